@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Signature is one form of a command's positional arguments, given as a
+// function and argument descriptions in the same way as for Callback
+type Signature struct {
+	F            interface{}
+	Descriptions []string
+}
+
+// Sig is shorthand for creating a Signature
+func Sig(f interface{}, descriptions ...string) Signature {
+	return Signature{F: f, Descriptions: descriptions}
+}
+
+// OneOfOption sets the command callback to one that accepts alternative
+// forms of positional arguments, for instance:
+//
+//	cli.OneOfOption(
+//		cli.Sig(copyFiles, "<src>", "<dst>"),
+//		cli.Sig(copyStdin, "<dst>"),
+//	)
+//
+// The signatures are tried in order. The first signature that uses all
+// of the arguments is run, otherwise the first signature whose arguments
+// could be parsed is run. If none of the signatures match, the error from
+// the closest matching signature is returned along with its usage. All
+// of the signatures are displayed in the command's usage. Running a
+// command without any signatures returns ErrNoSignatures
+func OneOfOption(signatures ...Signature) Option {
+	return func(cmd *Command) {
+		callbacks := make([]*callback, len(signatures))
+		for i, sig := range signatures {
+			callbacks[i] = newCallback(sig.F, sig.Descriptions...)
+			callbacks[i].setResult = cmd.SetResult
+		}
+		cmd.alternatives = callbacks
+		cmd.arguments = nil
+		cmd.parseArgs = false
+		cmd.Callback = func(name string, args ...string) ([]string, error) {
+			return runAlternatives(callbacks, name, args)
+		}
+	}
+}
+
+func runAlternatives(callbacks []*callback, name string, args []string) ([]string, error) {
+	if len(callbacks) == 0 {
+		return args, ErrNoSignatures
+	}
+
+	var chosen, closest *callback
+	var closestErr error
+	closestScore := 0
+	for _, cb := range callbacks {
+		if cb.inputErr != nil {
+			return args, cb.inputErr
+		}
+
+		n, err := cb.arguments.parse(args)
+		if err == nil {
+			if chosen == nil {
+				chosen = cb
+			}
+
+			if len(cb.arguments.Args()) == 0 {
+				chosen = cb
+				break
+			}
+			continue
+		}
+
+		// not having enough arguments is scored by how many are missing,
+		// failing to parse is scored by how many were parsed first
+		score := n
+		if errors.Is(err, ErrNumArguments) {
+			score = len(args) - cb.arguments.Len()
+		}
+
+		if closest == nil || score > closestScore {
+			closest, closestErr, closestScore = cb, err, score
+		}
+	}
+
+	if chosen != nil {
+		return chosen.invoke()
+	}
+
+	builder := &strings.Builder{}
+	closest.arguments.Usage(builder)
+	return args, fmt.Errorf("%w (usage: %s %s)", closestErr, name, builder.String())
+}
+
+// alternativesUsage returns the usage of each alternative separated by
+// " | "
+func alternativesUsage(callbacks []*callback) string {
+	usages := make([]string, len(callbacks))
+	for i, cb := range callbacks {
+		builder := &strings.Builder{}
+		cb.arguments.Usage(builder)
+		usages[i] = builder.String()
+	}
+	return strings.Join(usages, " | ")
+}
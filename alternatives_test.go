@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOneOfOption(t *testing.T) {
+	tests := []struct {
+		desc       string
+		args       []string
+		want       string
+		wantErr    error
+		wantErrStr string
+	}{
+		{"first", []string{"a", "b"}, "copy a b", nil, ""},
+		{"second", []string{"b"}, "stdin b", nil, ""},
+		{"exact preferred", []string{"1", "2", "3"}, "repeat 1 2 3", nil, ""},
+		{"no match", []string{}, "", ErrNumArguments, "Invalid Usage not enough arguments given: expected 1 (<dst>), got 0 (usage: app <dst>)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := ""
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OneOfOption(
+				Sig(func(src, dst string) { got = "copy " + src + " " + dst }, "<src>", "<dst>"),
+				Sig(func(dst string) { got = "stdin " + dst }, "<dst>"),
+				Sig(func(a, b, c int) { got = "repeat 1 2 3" }),
+			))
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil && err.Error() != test.wantErrStr {
+				t.Errorf("Wanted error %q got %q", test.wantErrStr, err.Error())
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestOneOfOptionClosest(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), OneOfOption(
+		Sig(func(a, b, c string) {}, "<a>", "<b>", "<c>"),
+		Sig(func(n int, s string) {}, "<n>", "<s>"),
+		Sig(func(s string, n int) {}, "<s>", "<n>"),
+	))
+
+	_, err := cmd.Run([]string{"foo", "bar"})
+	want := "Invalid Usage parse error (usage: app <s> <n>)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Wanted error %q got %v", want, err)
+	}
+
+	if got := cmd.usageStr(); got != "<a> <b> <c> | <n> <s> | <s> <n>" {
+		t.Errorf("Unexpected usage %q", got)
+	}
+}
+
+func TestOneOfOptionEmpty(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), OneOfOption())
+	_, err := cmd.Run([]string{"foo"})
+	if !errors.Is(err, ErrNoSignatures) {
+		t.Errorf("Wanted error %v got %v", ErrNoSignatures, err)
+	}
+
+	if got := cmd.usageStr(); got != "" {
+		t.Errorf("Unexpected usage %q", got)
+	}
+}
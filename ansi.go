@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// ansi parser states
+const (
+	ansiText   = iota
+	ansiEscape // after ESC
+	ansiCSI    // after ESC [
+	ansiOSC    // after ESC ]
+	ansiOSCEsc // after ESC inside an OSC string
+)
+
+type ansiStripper struct {
+	writer io.Writer
+	mu     sync.Mutex
+	state  int
+}
+
+// StripANSI returns a writer that removes ANSI escape sequences, such
+// as colors and cursor movement, from everything written to it before
+// writing it to w. Escape sequences that are split across writes are
+// removed as well. The writer is safe for concurrent use
+func StripANSI(w io.Writer) io.Writer {
+	return &ansiStripper{writer: w}
+}
+
+func (as *ansiStripper) Write(p []byte) (int, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch as.state {
+		case ansiText:
+			if b == 0x1b {
+				as.state = ansiEscape
+			} else {
+				out = append(out, b)
+			}
+		case ansiEscape:
+			switch b {
+			case '[':
+				as.state = ansiCSI
+			case ']':
+				as.state = ansiOSC
+			default:
+				// two character sequence
+				as.state = ansiText
+			}
+		case ansiCSI:
+			// parameter and intermediate bytes are followed by a final
+			// byte in the range 0x40 to 0x7e
+			if b >= 0x40 && b <= 0x7e {
+				as.state = ansiText
+			}
+		case ansiOSC:
+			if b == 0x07 {
+				as.state = ansiText
+			} else if b == 0x1b {
+				as.state = ansiOSCEsc
+			}
+		case ansiOSCEsc:
+			if b == '\\' {
+				as.state = ansiText
+			} else {
+				as.state = ansiOSC
+			}
+		}
+	}
+
+	if _, err := as.writer.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// isTerminal reports whether file is a terminal
+func isTerminal(file *os.File) bool {
+	fi, err := file.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// plainWriters holds the writer that plain returns for each file, so
+// that the file is only checked once and escape sequences split across
+// writes are removed
+var plainWriters sync.Map
+
+// plain wraps w with StripANSI if w is a file that is not a terminal,
+// such as when output has been redirected to a file or a pipe. The same
+// writer is returned every time for a file
+func plain(w io.Writer) io.Writer {
+	file, ok := w.(*os.File)
+	if !ok {
+		return w
+	}
+
+	if writer, found := plainWriters.Load(file); found {
+		return writer.(io.Writer)
+	}
+
+	if !isTerminal(file) {
+		w = StripANSI(w)
+	}
+	writer, _ := plainWriters.LoadOrStore(file, w)
+	return writer.(io.Writer)
+}
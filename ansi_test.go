@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		desc   string
+		writes []string
+		want   string
+	}{
+		{"plain", []string{"hello"}, "hello"},
+		{"color", []string{"\x1b[31merror:\x1b[0m oops"}, "error: oops"},
+		{"split sequence", []string{"a\x1b[", "1;3", "1mb\x1b", "[0mc"}, "abc"},
+		{"cursor movement", []string{"50%\x1b[2K\r\x1b[1A100%"}, "50%\r100%"},
+		{"osc bell", []string{"\x1b]0;title\x07text"}, "text"},
+		{"osc st", []string{"\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\"}, "link"},
+		{"two character", []string{"\x1bcreset"}, "reset"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			builder := &strings.Builder{}
+			w := StripANSI(builder)
+			for _, write := range test.writes {
+				if n, err := w.Write([]byte(write)); err != nil || n != len(write) {
+					t.Errorf("Wanted %d bytes written got %d (%v)", len(write), n, err)
+				}
+			}
+
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestStripANSIConcurrent(t *testing.T) {
+	builder := &strings.Builder{}
+	w := StripANSI(builder)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Write([]byte("\x1b[1mx\x1b[0m"))
+		}()
+	}
+	wg.Wait()
+
+	if want := strings.Repeat("x", 10); want != builder.String() {
+		t.Errorf("Wanted %q got %q", want, builder.String())
+	}
+}
+
+func TestRedirectedOutputIsPlain(t *testing.T) {
+	file, err := ioutil.TempFile("", "output")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	Colors = true
+	defer func() { Colors = false }()
+
+	cmd := New("app", StdoutOption(file), OutputOption(file))
+	cmd.Warnf("careful")
+	cmd.Infof("\x1b[1mbold\x1b[0m")
+	cmd.Stdout().Write([]byte("raw \x1b[1m\n"))
+
+	// a sequence split across writes to Output
+	cmd.Output().Write([]byte("split \x1b["))
+	cmd.Output().Write([]byte("1mdone\n"))
+
+	got, _ := ioutil.ReadFile(file.Name())
+	if want := "warning: careful\nbold\nraw \x1b[1m\nsplit done\n"; want != string(got) {
+		t.Errorf("Wanted %q got %q", want, string(got))
+	}
+}
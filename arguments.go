@@ -24,7 +24,7 @@ func (b *boolValue) Get() interface{} { return bool(*b) }
 func (b *boolValue) Set(s string) error {
 	v, err := strconv.ParseBool(s)
 	if err != nil {
-		err = errParse
+		err = ErrParse
 	}
 	*b = boolValue(v)
 	return err
@@ -117,9 +117,9 @@ type durationValue time.Duration
 
 func (d *durationValue) Get() interface{} { return time.Duration(*d) }
 func (d *durationValue) Set(s string) error {
-	v, err := time.ParseDuration(s)
+	v, err := ParseDuration(s)
 	if err != nil {
-		err = errParse
+		err = ErrParse
 	}
 	*d = durationValue(v)
 	return err
@@ -128,87 +128,142 @@ func (d *durationValue) Set(s string) error {
 func (d *durationValue) String() string { return (*time.Duration)(d).String() }
 
 type Arguments struct {
-	input []string
-	args  []*argument
+	input    []string
+	args     []*argument
+	provided int
 }
 
 type argument struct {
-	value interface{}
-	desc  string
+	value      interface{}
+	desc       string
+	transforms []func(string) string
+	glob       bool
+	globLimit  int
+	def        *string
+	delimiter  string
 }
 
-func (args *Arguments) Bool(desc string) *bool {
+// ArgumentOption configures a positional argument
+type ArgumentOption func(*argument)
+
+// Optional makes the argument optional, setting it to def when it is not
+// given. Only the last arguments of a command can be optional. Slice
+// arguments are set to the space separated fields of def
+func Optional(def string) ArgumentOption {
+	return func(arg *argument) { arg.def = &def }
+}
+
+// Delimiter ends a slice argument at the first occurrence of token,
+// which is not included in the values, so that further arguments,
+// including other slices, can follow. For instance, with two slice
+// arguments where the first is delimited by "--", the input
+// "a b -- c d" sets the first slice to "a b" and the second to "c d".
+// When the token is not given, the slice takes the input as though it
+// had no delimiter
+func Delimiter(token string) ArgumentOption {
+	return func(arg *argument) { arg.delimiter = token }
+}
+
+func (arg *argument) transform(s string) string {
+	for _, transform := range arg.transforms {
+		s = transform(s)
+	}
+	return s
+}
+
+func (args *Arguments) Bool(desc string, options ...ArgumentOption) *bool {
 	p := new(bool)
-	args.BoolVar(p, desc)
+	args.BoolVar(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) BoolVar(p *bool, desc string) { args.Var((*boolValue)(p), desc) }
+func (args *Arguments) BoolVar(p *bool, desc string, options ...ArgumentOption) {
+	args.Var((*boolValue)(p), desc, options...)
+}
 
-func (args *Arguments) Duration(desc string) *time.Duration {
+func (args *Arguments) Duration(desc string, options ...ArgumentOption) *time.Duration {
 	p := new(time.Duration)
-	args.DurationVar(p, desc)
+	args.DurationVar(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) DurationVar(p *time.Duration, desc string) {
-	args.Var((*durationValue)(p), desc)
+func (args *Arguments) DurationVar(p *time.Duration, desc string, options ...ArgumentOption) {
+	args.Var((*durationValue)(p), desc, options...)
 }
 
-func (args *Arguments) Float64(desc string) *float64 {
+func (args *Arguments) Float64(desc string, options ...ArgumentOption) *float64 {
 	p := new(float64)
-	args.Float64Var(p, desc)
+	args.Float64Var(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) Float64Var(p *float64, desc string) { args.Var((*float64Value)(p), desc) }
+func (args *Arguments) Float64Var(p *float64, desc string, options ...ArgumentOption) {
+	args.Var((*float64Value)(p), desc, options...)
+}
 
-func (args *Arguments) Int(desc string) *int {
+func (args *Arguments) Int(desc string, options ...ArgumentOption) *int {
 	p := new(int)
-	args.IntVar(p, desc)
+	args.IntVar(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) IntVar(p *int, desc string) { args.Var((*intValue)(p), desc) }
+func (args *Arguments) IntVar(p *int, desc string, options ...ArgumentOption) {
+	args.Var((*intValue)(p), desc, options...)
+}
 
-func (args *Arguments) Int64(desc string) *int64 {
+func (args *Arguments) Int64(desc string, options ...ArgumentOption) *int64 {
 	p := new(int64)
-	args.Int64Var(p, desc)
+	args.Int64Var(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) Int64Var(p *int64, desc string) { args.Var((*int64Value)(p), desc) }
+func (args *Arguments) Int64Var(p *int64, desc string, options ...ArgumentOption) {
+	args.Var((*int64Value)(p), desc, options...)
+}
 
-func (args *Arguments) String(desc string) *string {
+func (args *Arguments) String(desc string, options ...ArgumentOption) *string {
 	p := new(string)
-	args.StringVar(p, desc)
+	args.StringVar(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) StringVar(p *string, desc string) { args.Var((*stringValue)(p), desc) }
+func (args *Arguments) StringVar(p *string, desc string, options ...ArgumentOption) {
+	args.Var((*stringValue)(p), desc, options...)
+}
 
-func (args *Arguments) Uint(desc string) *uint {
+func (args *Arguments) Uint(desc string, options ...ArgumentOption) *uint {
 	p := new(uint)
-	args.UintVar(p, desc)
+	args.UintVar(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) UintVar(p *uint, desc string) { args.Var((*uintValue)(p), desc) }
+func (args *Arguments) UintVar(p *uint, desc string, options ...ArgumentOption) {
+	args.Var((*uintValue)(p), desc, options...)
+}
 
-func (args *Arguments) Uint64(desc string) *uint64 {
+func (args *Arguments) Uint64(desc string, options ...ArgumentOption) *uint64 {
 	p := new(uint64)
-	args.Uint64Var(p, desc)
+	args.Uint64Var(p, desc, options...)
 	return p
 }
 
-func (args *Arguments) Uint64Var(p *uint64, desc string) { args.Var((*uint64Value)(p), desc) }
+func (args *Arguments) Uint64Var(p *uint64, desc string, options ...ArgumentOption) {
+	args.Var((*uint64Value)(p), desc, options...)
+}
+
+func (args *Arguments) Var(value Value, desc string, options ...ArgumentOption) {
+	args.add(&argument{value: value, desc: desc}, options)
+}
 
-func (args *Arguments) Var(value Value, desc string) {
-	args.args = append(args.args, &argument{value, desc})
+func (args *Arguments) VarSlice(value SliceValue, desc string, options ...ArgumentOption) {
+	args.add(&argument{value: value, desc: desc}, options)
 }
 
-func (args *Arguments) VarSlice(value SliceValue, desc string) {
-	args.args = append(args.args, &argument{value, desc})
+func (args *Arguments) add(arg *argument, options []ArgumentOption) {
+	for _, option := range options {
+		option(arg)
+	}
+	args.args = append(args.args, arg)
 }
 
 func (args *Arguments) Len() int { return len(args.args) }
@@ -222,30 +277,197 @@ func (args *Arguments) Args() []string {
 }
 
 func (args *Arguments) Parse(input []string) error {
-	if len(input) < len(args.args) {
-		return errNumArguments
+	_, err := args.parse(input)
+	return err
+}
+
+// Provided reports whether the i'th argument was set when the
+// arguments were last parsed, rather than being left at its initial value
+func (args *Arguments) Provided(i int) bool {
+	return i >= 0 && i < args.provided
+}
+
+// parse parses input and returns the number of arguments that were
+// successfully set before an error occurred
+func (args *Arguments) parse(input []string) (n int, err error) {
+	defer func() { args.provided = n }()
+	if required, _ := args.bounds(); len(input) < required {
+		return 0, args.countError(ErrNumArguments, len(input))
 	}
 	args.input = []string{}
+	pos, given := 0, len(args.args)
 	for i, arg := range args.args {
-		if s, ok := arg.value.(SliceValue); ok {
-			return s.Set(input[i:len(input)])
-		} else if s, ok := arg.value.(Value); ok {
-			err := s.Set(input[i])
+		if pos >= len(input) {
+			if i < given {
+				given = i
+			}
+
+			if arg.def == nil {
+				return i, args.countError(ErrNumArguments, len(input))
+			} else if err := arg.setDefault(); err != nil {
+				return i, err
+			}
+			continue
+		}
+
+		if _, ok := arg.value.(SliceValue); !ok {
+			if err := arg.set(input[pos : pos+1]); err != nil {
+				return i, err
+			}
+			pos++
+			continue
+		}
+
+		// a slice with a delimiter takes the input up to the delimiter
+		if end := arg.delimiterIndex(input[pos:]); end == 0 {
+			return i, args.countError(ErrNumArguments, pos)
+		} else if end > 0 {
+			if err := arg.set(input[pos : pos+end]); err != nil {
+				return i, err
+			}
+			pos += end + 1
+			continue
+		}
+
+		// other slices take all of the remaining input except for the
+		// values of the arguments that follow, which are taken from the
+		// end
+		rest := args.args[i+1:]
+		fixed := len(rest)
+		if available := len(input) - pos - 1; available < fixed {
+			fixed = available
+		}
+
+		end := len(input) - fixed
+		if err := arg.set(input[pos:end]); err != nil {
+			return i, err
+		}
+
+		for j, trailing := range rest {
+			if j < fixed {
+				err = trailing.set(input[end+j : end+j+1])
+			} else if trailing.def == nil {
+				err = args.countError(ErrNumArguments, len(input))
+			} else {
+				err = trailing.setDefault()
+			}
+
 			if err != nil {
-				return err
+				return i + 1 + j, err
 			}
-		} else {
-			panic(fmt.Sprintf("huh? value should have been Value or SliceValue got %T", arg.value))
 		}
+		return i + 1 + fixed, nil
+	}
+
+	args.input = input[pos:]
+	return given, nil
+}
+
+// delimiterIndex returns the index of the argument's delimiter in input
+// or -1 if the argument has no delimiter or it is not found
+func (arg *argument) delimiterIndex(input []string) int {
+	if arg.delimiter != "" {
+		for i, s := range input {
+			if s == arg.delimiter {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// set sets the argument from input, which holds a single value unless
+// the argument is a slice
+func (arg *argument) set(input []string) error {
+	expanded, err := arg.expandAll(input)
+	if err != nil {
+		return err
+	}
+
+	switch v := arg.value.(type) {
+	case SliceValue:
+		values := make([]string, len(expanded))
+		for j, value := range expanded {
+			values[j] = arg.transform(value)
+		}
+		return v.Set(values)
+	case Value:
+		if len(expanded) > 1 {
+			return fmt.Errorf("%w: %s matches %d files", ErrTooManyMatches, arg.desc, len(expanded))
+		}
+		return v.Set(arg.transform(expanded[0]))
+	}
+	return &InternalError{fmt.Errorf("%w, got %T", ErrInvalidArgumentValue, arg.value)}
+}
+
+// setDefault sets an optional argument that was not given to its
+// default value
+func (arg *argument) setDefault() error {
+	if arg.def == nil {
+		return ErrNumArguments
+	}
+
+	switch v := arg.value.(type) {
+	case SliceValue:
+		return v.Set(strings.Fields(*arg.def))
+	case Value:
+		return v.Set(*arg.def)
+	}
+	return &InternalError{fmt.Errorf("%w, got %T", ErrInvalidArgumentValue, arg.value)}
+}
+
+// bounds returns the least and the most number of values the arguments
+// accept, where the least is the number of arguments that are not
+// optional. Arguments that include a slice have no maximum and max is -1
+func (args *Arguments) bounds() (min, max int) {
+	min, max = len(args.args), len(args.args)
+	for _, arg := range args.args {
+		if arg.def != nil {
+			min--
+		}
+
+		if _, ok := arg.value.(SliceValue); ok {
+			max = -1
+		}
+	}
+	return min, max
+}
+
+// countError returns err, which is either ErrNumArguments or
+// ErrTooManyArguments, along with how many values were expected and
+// how many were given
+func (args *Arguments) countError(err error, got int) error {
+	min, max := args.bounds()
+	expected := strconv.Itoa(min)
+	if err == ErrTooManyArguments {
+		expected = "at most " + strconv.Itoa(max)
+	} else if min != max {
+		expected = "at least " + expected
+	}
+	return fmt.Errorf("%w: expected %s (%s), got %d", err, expected, args.usage(), got)
+}
+
+// checkCount returns ErrTooManyArguments if input holds more values
+// than the arguments accept
+func (args *Arguments) checkCount(input []string) error {
+	if _, max := args.bounds(); max >= 0 && len(input) > max {
+		return args.countError(ErrTooManyArguments, len(input))
 	}
-	args.input = input[len(args.args):]
 	return nil
 }
 
-func (args *Arguments) Usage(writer io.Writer) {
+func (args *Arguments) usage() string {
 	desc := []string{}
 	for _, arg := range args.args {
-		desc = append(desc, arg.desc)
+		if arg.def != nil {
+			desc = append(desc, "["+arg.desc+"]")
+		} else {
+			desc = append(desc, arg.desc)
+		}
 	}
-	writer.Write([]byte(strings.Join(desc, " ")))
+	return strings.Join(desc, " ")
+}
+
+func (args *Arguments) Usage(writer io.Writer) {
+	writer.Write([]byte(args.usage()))
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"io"
 	"reflect"
@@ -91,21 +92,21 @@ func TestArguments(t *testing.T) {
 		wantErr error
 	}{
 		{"bool", []string{"true"}, func(args *Arguments) interface{} { return args.Bool("bool") }, true, nil},
-		{"bool", []string{"foobar"}, func(args *Arguments) interface{} { return args.Bool("bool") }, false, errParse},
+		{"bool", []string{"foobar"}, func(args *Arguments) interface{} { return args.Bool("bool") }, false, ErrParse},
 		{"duration", []string{"64s"}, func(args *Arguments) interface{} { return args.Duration("duration") }, time.Second * 64, nil},
-		{"duration err", []string{"sixty-four seconds"}, func(args *Arguments) interface{} { return args.Duration("duration") }, time.Duration(0), errParse},
+		{"duration err", []string{"sixty-four seconds"}, func(args *Arguments) interface{} { return args.Duration("duration") }, time.Duration(0), ErrParse},
 		{"int", []string{"1"}, func(args *Arguments) interface{} { return args.Int("int") }, 1, nil},
-		{"int errParse", []string{"one"}, func(args *Arguments) interface{} { return args.Int("int") }, 0, errParse},
-		{"int errRange", []string{"18446744073709551615"}, func(args *Arguments) interface{} { return args.Int("int") }, 0, errRange},
+		{"int ErrParse", []string{"one"}, func(args *Arguments) interface{} { return args.Int("int") }, 0, ErrParse},
+		{"int ErrRange", []string{"18446744073709551615"}, func(args *Arguments) interface{} { return args.Int("int") }, 0, ErrRange},
 		{"int64", []string{"2"}, func(args *Arguments) interface{} { return args.Int64("int64") }, int64(2), nil},
-		{"int64 err", []string{"two"}, func(args *Arguments) interface{} { return args.Int64("int64") }, 0, errParse},
+		{"int64 err", []string{"two"}, func(args *Arguments) interface{} { return args.Int64("int64") }, 0, ErrParse},
 		{"float64", []string{"2.001"}, func(args *Arguments) interface{} { return args.Float64("float64") }, float64(2.001), nil},
-		{"float64 err", []string{"two point zero zero one"}, func(args *Arguments) interface{} { return args.Float64("float64") }, 0, errParse},
+		{"float64 err", []string{"two point zero zero one"}, func(args *Arguments) interface{} { return args.Float64("float64") }, 0, ErrParse},
 		{"string", []string{"foobar"}, func(args *Arguments) interface{} { return args.String("string") }, "foobar", nil},
 		{"uint", []string{"5"}, func(args *Arguments) interface{} { return args.Uint("uint") }, uint(5), nil},
-		{"uint", []string{"five"}, func(args *Arguments) interface{} { return args.Uint("uint") }, 0, errParse},
+		{"uint", []string{"five"}, func(args *Arguments) interface{} { return args.Uint("uint") }, 0, ErrParse},
 		{"uint64", []string{"6"}, func(args *Arguments) interface{} { return args.Uint64("uint64") }, uint64(6), nil},
-		{"uint64 err", []string{"six"}, func(args *Arguments) interface{} { return args.Uint64("uint64") }, 0, errParse},
+		{"uint64 err", []string{"six"}, func(args *Arguments) interface{} { return args.Uint64("uint64") }, 0, ErrParse},
 		{"varslice", []string{"1", "2", "3"}, func(args *Arguments) interface{} {
 			varSlice := []int{}
 			args.VarSlice((*intSlice)(&varSlice), "n n n n...")
@@ -137,8 +138,8 @@ func TestNumError(t *testing.T) {
 		input error
 		want  error
 	}{
-		{"errParse", &strconv.NumError{Err: strconv.ErrSyntax}, errParse},
-		{"errRange", &strconv.NumError{Err: strconv.ErrRange}, errRange},
+		{"ErrParse", &strconv.NumError{Err: strconv.ErrSyntax}, ErrParse},
+		{"ErrRange", &strconv.NumError{Err: strconv.ErrRange}, ErrRange},
 		{"EOF", io.EOF, io.EOF},
 		{"Other", &strconv.NumError{Err: io.EOF}, io.EOF},
 	}
@@ -168,7 +169,7 @@ func TestArgumentsParse(t *testing.T) {
 		wantErr  error
 	}{
 		{"test 1", []string{"true"}, []Value{testValue{}}, 1, []string{}, nil},
-		{"test 2", []string{}, []Value{testValue{}}, 1, []string{}, errNumArguments},
+		{"test 2", []string{}, []Value{testValue{}}, 1, []string{}, ErrNumArguments},
 	}
 
 	for _, test := range tests {
@@ -180,7 +181,7 @@ func TestArgumentsParse(t *testing.T) {
 
 			arguments := &Arguments{args: args}
 			gotErr := arguments.Parse(test.input)
-			if test.wantErr != gotErr {
+			if !errors.Is(gotErr, test.wantErr) {
 				t.Errorf("want error %v got %v", test.wantErr, gotErr)
 			} else if gotErr == nil {
 				gotLen := arguments.Len()
@@ -197,6 +198,27 @@ func TestArgumentsParse(t *testing.T) {
 	}
 }
 
+func TestArgumentsInvalidValue(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input []string
+		def   *string
+	}{
+		{"given", []string{"foo"}, nil},
+		{"default", []string{}, new(string)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			arguments := &Arguments{args: []*argument{{value: 42, desc: "<answer>", def: test.def}}}
+			err := arguments.Parse(test.input)
+			if !errors.Is(err, ErrInvalidArgumentValue) {
+				t.Errorf("Wanted error %v got %v", ErrInvalidArgumentValue, err)
+			}
+		})
+	}
+}
+
 func TestArgumentsUsage(t *testing.T) {
 	tests := []struct {
 		desc  string
@@ -220,3 +242,228 @@ func TestArgumentsUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestArgumentsProvided(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input []string
+		want  []bool
+	}{
+		{"none", []string{}, []bool{false, false}},
+		{"all", []string{"foo", "5"}, []bool{true, true}},
+		{"error", []string{"foo", "five"}, []bool{true, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := &Arguments{}
+			args.String("<name>")
+			args.Int("<count>")
+			args.Parse(test.input)
+			for i, want := range test.want {
+				if got := args.Provided(i); want != got {
+					t.Errorf("Wanted argument %d provided to be %v got %v", i, want, got)
+				}
+			}
+
+			if args.Provided(-1) || args.Provided(len(test.want)) {
+				t.Errorf("Expected out of range arguments not to be provided")
+			}
+		})
+	}
+}
+
+func TestArgumentsProvidedTrailing(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input []string
+		want  []bool
+	}{
+		{"default", []string{"1"}, []bool{true, false}},
+		{"given", []string{"1", "b"}, []bool{true, true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := &Arguments{}
+			args.VarSlice(&intSlice{}, "<src>...")
+			args.String("[dst]", Optional("out"))
+			args.Parse(test.input)
+			for i, want := range test.want {
+				if got := args.Provided(i); want != got {
+					t.Errorf("Wanted argument %d provided to be %v got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestArgumentsOptional(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    []string
+		wantName string
+		wantTags []int
+		wantErr  error
+	}{
+		{"given", []string{"foo", "1", "2"}, "foo", []int{1, 2}, nil},
+		{"defaults", []string{}, "bar", []int{3, 4}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := &Arguments{}
+			name := args.String("<name>", Optional("bar"))
+			tags := intSlice{}
+			args.VarSlice(&tags, "<tag>...", Optional("3 4"))
+
+			builder := &strings.Builder{}
+			args.Usage(builder)
+			if want, got := "[<name>] [<tag>...]", builder.String(); want != got {
+				t.Errorf("Wanted usage %q got %q", want, got)
+			}
+
+			if err := args.Parse(test.input); test.wantErr != err {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if *name != test.wantName || !reflect.DeepEqual(test.wantTags, []int(tags)) {
+				t.Errorf("Wanted %q %v got %q %v", test.wantName, test.wantTags, *name, tags)
+			}
+
+			if got := args.Provided(0); got != (len(test.input) > 0) {
+				t.Errorf("Wanted provided %v got %v", len(test.input) > 0, got)
+			}
+		})
+	}
+}
+
+func TestArgumentsTrailing(t *testing.T) {
+	tests := []struct {
+		desc     string
+		input    []string
+		wantSrc  []int
+		wantDst  string
+		wantMode string
+		wantErr  error
+	}{
+		{"one source", []string{"1", "dir"}, []int{1}, "dir", "copy", nil},
+		{"many sources", []string{"1", "2", "3", "dir", "move"}, []int{1, 2, 3}, "dir", "move", nil},
+		{"missing destination", []string{"1"}, nil, "", "", ErrNumArguments},
+		{"invalid source", []string{"one", "dir"}, nil, "", "", strconv.ErrSyntax},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			src := intSlice{}
+			args := &Arguments{}
+			args.VarSlice(&src, "<src>...")
+			dst := args.String("<dst>")
+			mode := args.String("<mode>", Optional("copy"))
+
+			err := args.Parse(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(test.wantSrc, []int(src)) || test.wantDst != *dst || test.wantMode != *mode {
+				t.Errorf("Wanted %v %q %q got %v %q %q", test.wantSrc, test.wantDst, test.wantMode, src, *dst, *mode)
+			}
+		})
+	}
+}
+
+func TestArgumentsDelimiter(t *testing.T) {
+	tests := []struct {
+		desc      string
+		input     []string
+		wantFirst []int
+		wantLast  []int
+		wantErr   error
+		wantGot   string
+	}{
+		{"delimited", []string{"1", "2", "--", "3", "4"}, []int{1, 2}, []int{3, 4}, nil, ""},
+		{"one each", []string{"1", "--", "2"}, []int{1}, []int{2}, nil, ""},
+		{"no delimiter", []string{"1", "2"}, []int{1}, []int{2}, nil, ""},
+		{"empty first", []string{"--", "1", "2"}, nil, nil, ErrNumArguments, "got 0"},
+		{"empty last", []string{"1", "--"}, nil, nil, ErrNumArguments, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			first, last := intSlice{}, intSlice{}
+			args := &Arguments{}
+			args.VarSlice(&first, "<first>...", Delimiter("--"))
+			args.VarSlice(&last, "<last>...")
+
+			err := args.Parse(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				if !strings.HasSuffix(err.Error(), test.wantGot) {
+					t.Errorf("Wanted error ending in %q got %q", test.wantGot, err.Error())
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(test.wantFirst, []int(first)) || !reflect.DeepEqual(test.wantLast, []int(last)) {
+				t.Errorf("Wanted %v %v got %v %v", test.wantFirst, test.wantLast, first, last)
+			}
+		})
+	}
+}
+
+func TestArgumentsCountError(t *testing.T) {
+	tests := []struct {
+		desc    string
+		input   []string
+		strict  bool
+		wantErr error
+		wantStr string
+	}{
+		{"not enough", []string{"example.com"}, false, ErrNumArguments, "Invalid Usage not enough arguments given: expected 3 (<host> <port> <path>), got 1"},
+		{"too many", []string{"example.com", "80", "/", "extra"}, true, ErrTooManyArguments, "Invalid Usage too many arguments given: expected at most 3 (<host> <port> <path>), got 4"},
+		{"too many not strict", []string{"example.com", "80", "/", "extra"}, false, nil, ""},
+		{"exact", []string{"example.com", "80", "/"}, true, nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			Strict = test.strict
+			defer func() { Strict = false }()
+
+			args := &Arguments{}
+			args.String("<host>")
+			args.Int("<port>")
+			args.String("<path>")
+			cmd := New("get", ErrorHandlingOption(ContinueOnError), ArgumentsOption(args), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+
+			_, err := cmd.Run(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil && err.Error() != test.wantStr {
+				t.Errorf("Wanted error %q got %q", test.wantStr, err.Error())
+			}
+		})
+	}
+
+	args := &Arguments{}
+	args.VarSlice(&intSlice{}, "<n>...")
+	args.String("<name>", Optional(""))
+	want := "Invalid Usage not enough arguments given: expected at least 1 (<n>... [<name>]), got 0"
+	if err := args.Parse(nil); err == nil || err.Error() != want {
+		t.Errorf("Wanted error %q got %v", want, err)
+	}
+
+	args = &Arguments{}
+	args.VarSlice(&intSlice{}, "<src>...")
+	args.String("<x>")
+	args.String("<y>", Optional(""))
+	args.String("<z>")
+	want = "Invalid Usage not enough arguments given: expected at least 3 (<src>... <x> [<y>] <z>), got 2"
+	if err := args.Parse([]string{"1", "b"}); err == nil || err.Error() != want {
+		t.Errorf("Wanted error %q got %v", want, err)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteOption configures WriteFileAtomic
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	backup string
+}
+
+// WithBackup keeps a copy of the previous contents of the file, in a
+// file named by adding suffix, such as ".bak", to the file's path
+func WithBackup(suffix string) WriteOption {
+	return func(wc *writeConfig) { wc.backup = suffix }
+}
+
+// WriteFileAtomic writes data to the named file, creating it with
+// permissions perm if necessary. The data is written to a temporary
+// file in the same directory which then replaces the named file, so
+// that a crash never leaves a partially written file behind
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode, options ...WriteOption) (err error) {
+	wc := &writeConfig{}
+	for _, option := range options {
+		option(wc)
+	}
+
+	tmpfile, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmpfile.Name())
+		}
+	}()
+
+	if _, err = tmpfile.Write(data); err == nil {
+		err = tmpfile.Sync()
+	}
+
+	if cerr := tmpfile.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmpfile.Name(), perm)
+	}
+
+	if err == nil && wc.backup != "" {
+		err = backupFile(filename, filename+wc.backup)
+	}
+
+	if err == nil {
+		err = os.Rename(tmpfile.Name(), filename)
+	}
+	return err
+}
+
+// backupFile copies filename to backup, if filename exists
+func backupFile(filename, backup string) error {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err == nil {
+		err = ioutil.WriteFile(backup, data, info.Mode().Perm())
+	}
+	return err
+}
+
+// EditFile opens the named file in the user's editor, see Edit, and
+// then writes the result back with WriteFileAtomic, keeping the file's
+// permissions. The file is only written if it was changed
+func EditFile(filename string, options ...WriteOption) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	output, err := Edit(input)
+	if err != nil || string(output) == string(input) {
+		return err
+	}
+	return WriteFileAtomic(filename, output, info.Mode().Perm(), options...)
+}
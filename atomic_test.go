@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config")
+	if err := WriteFileAtomic(filename, []byte("one"), 0640, WithBackup(".bak")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := os.Stat(filename + ".bak"); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup of a new file")
+	}
+
+	if err := WriteFileAtomic(filename, []byte("two"), 0640, WithBackup(".bak")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{filename, "two"},
+		{filename + ".bak", "one"},
+	}
+
+	for _, test := range tests {
+		got, err := ioutil.ReadFile(test.filename)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		if string(got) != test.want {
+			t.Errorf("Wanted %q got %q", test.want, got)
+		}
+	}
+
+	if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("Wanted mode 0640 got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("Expected no temporary files to be left behind got %d files", len(files))
+	}
+}
+
+func TestWriteFileAtomicMissingDir(t *testing.T) {
+	if err := WriteFileAtomic(filepath.Join(os.TempDir(), "missing", "dir", "file"), nil, 0600); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestEditFile(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "atomic")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config")
+	ioutil.WriteFile(filename, []byte("before"), 0600)
+
+	os.Setenv("EDITOR", os.Args[0])
+	os.Setenv("TEST_OUTPUT", "after")
+	editCmd.Args = []string{"-test.run=TestHelperProcess", "--"}
+	editCmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+
+	if err := EditFile(filename, WithBackup("~")); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if got, _ := ioutil.ReadFile(filename); string(got) != "after" {
+		t.Errorf("Wanted %q got %q", "after", got)
+	}
+
+	if got, _ := ioutil.ReadFile(filename + "~"); string(got) != "before" {
+		t.Errorf("Wanted %q got %q", "before", got)
+	}
+}
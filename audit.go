@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+type auditor struct {
+	writer io.Writer
+	redact map[string]bool
+}
+
+// AuditOption appends a record of every run of the command to w. Each
+// record is a single line holding the UTC time, the command name and
+// the command line arguments. The values of the flags named in
+// redactFlags, and of flags created with Secret, are replaced with
+// "********". If a record can not be written, the command is not run
+// and an InternalError is returned. AuditOption should be set on the
+// root command only, since subcommands are recorded as part of the
+// command line of their parent
+func AuditOption(w io.Writer, redactFlags ...string) Option {
+	return func(cmd *Command) {
+		redact := make(map[string]bool)
+		for _, name := range redactFlags {
+			redact[name] = true
+		}
+		cmd.audit = &auditor{writer: w, redact: redact}
+	}
+}
+
+func (a *auditor) record(cmd *Command, args []string) error {
+	line := fmt.Sprintf("%s %s", now().UTC().Format(time.RFC3339), cmd.Name)
+	if len(args) > 0 {
+		line += " " + quoteArgs(a.redactArgs(cmd, args))
+	}
+
+	if _, err := io.WriteString(a.writer, line+"\n"); err != nil {
+		return &InternalError{fmt.Errorf("audit: %w", err)}
+	}
+	return nil
+}
+
+// redactArgs returns a copy of args with the values of redacted flags
+// replaced. Flags are looked up in the commands of the path that args
+// resolve to, so a secret flag is redacted even when another command
+// has a flag of the same name that is not a secret
+func (a *auditor) redactArgs(cmd *Command, args []string) []string {
+	path := cmd.resolvedPath(args)
+	depth := 0
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if depth+1 < len(path) {
+				if subCmd, found := path[depth].Lookup(arg); found && subCmd == path[depth+1] {
+					depth++
+				}
+			}
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		hasValue := false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		owner := path[depth]
+		if cmd.resolvePath {
+			owner = flagOwner(path, name)
+		}
+
+		f := owner.Flags.Lookup(name)
+		if !a.redact[name] && (f == nil || !isSecret(f.Value)) {
+			continue
+		}
+
+		if hasValue {
+			out[i] = strings.TrimSuffix(arg, value) + redacted
+		} else if !isBoolFlag(f) && i+1 < len(out) {
+			i++
+			out[i] = redacted
+		}
+	}
+	return out
+}
+
+// lookupFlag finds the named flag in cmd or any of its subcommands
+func lookupFlag(cmd *Command, name string) *flag.Flag {
+	if f := cmd.Flags.Lookup(name); f != nil {
+		return f
+	}
+
+	for _, subCmd := range cmd.SubCommands {
+		if f := lookupFlag(subCmd, name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	if f == nil {
+		return false
+	}
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
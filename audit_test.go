@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAuditOption(t *testing.T) {
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		desc string
+		args []string
+		want string
+	}{
+		{"no args", nil, "2020-01-02T03:04:05Z app\n"},
+		{"plain", []string{"login", "bob"}, "2020-01-02T03:04:05Z app login bob\n"},
+		{"quoted", []string{"login", "bob smith"}, "2020-01-02T03:04:05Z app login \"bob smith\"\n"},
+		{"redacted flag", []string{"login", "-password", "hunter2", "bob"}, "2020-01-02T03:04:05Z app login -password ******** bob\n"},
+		{"redacted flag with equals", []string{"login", "--password=hunter2", "bob"}, "2020-01-02T03:04:05Z app login --password=******** bob\n"},
+		{"secret flag", []string{"-token=abc", "login", "bob"}, "2020-01-02T03:04:05Z app -token=******** login bob\n"},
+		{"bool flag", []string{"-v", "login", "-password", "x", "bob"}, "2020-01-02T03:04:05Z app -v login -password ******** bob\n"},
+		{"after terminator", []string{"login", "--", "-password", "bob"}, "2020-01-02T03:04:05Z app login -- -password bob\n"},
+		{"plain sibling flag", []string{"a", "-key", "x"}, "2020-01-02T03:04:05Z app a -key x\n"},
+		{"secret sibling flag", []string{"b", "-key", "hunter2"}, "2020-01-02T03:04:05Z app b -key ********\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			log := &strings.Builder{}
+			var token string
+			root := New("app", AuditOption(log, "password"), ErrorHandlingOption(ContinueOnError))
+			root.Flags.Var(SecretString(&token), "token", "api token")
+			root.Flags.Bool("v", false, "verbose")
+			login := root.SubCommand("login", FuncOption(func(string) {}))
+			login.Flags.String("password", "", "password")
+			root.SubCommand("a", FuncOption(func() {})).Flags.String("key", "", "plain key")
+			var key string
+			root.SubCommand("b", FuncOption(func() {})).Flags.Var(SecretString(&key), "key", "secret key")
+
+			root.Run(test.args)
+			if got := log.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAuditOptionWriteError(t *testing.T) {
+	ran := false
+	root := New("app", AuditOption(errWriter{}), ErrorHandlingOption(ContinueOnError), CallbackOption(func(string, ...string) ([]string, error) {
+		ran = true
+		return nil, nil
+	}))
+
+	_, err := root.Run(nil)
+	var internalErr *InternalError
+	if !errors.As(err, &internalErr) {
+		t.Errorf("Wanted InternalError got %v", err)
+	}
+
+	if ran {
+		t.Errorf("Expected callback not to run")
+	}
+}
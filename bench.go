@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"time"
+)
+
+// benchResult holds the durations of the runs made by the bench
+// command
+type benchResult struct {
+	durations []time.Duration
+	failures  int
+}
+
+// percentile returns the nearest rank percentile p of the durations,
+// which must be sorted
+func (br *benchResult) percentile(p float64) time.Duration {
+	if len(br.durations) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(br.durations))))
+	if rank < 1 {
+		rank = 1
+	}
+	return br.durations[rank-1]
+}
+
+func (br *benchResult) write(w io.Writer) error {
+	sort.Slice(br.durations, func(i, j int) bool { return br.durations[i] < br.durations[j] })
+	var total time.Duration
+	for _, d := range br.durations {
+		total += d
+	}
+
+	mean := time.Duration(0)
+	if len(br.durations) > 0 {
+		mean = total / time.Duration(len(br.durations))
+	}
+
+	round := func(d time.Duration) time.Duration { return d.Round(time.Microsecond) }
+	_, err := fmt.Fprintf(w, "%d runs, %d failed\nmin %v  mean %v  p50 %v  p90 %v  p99 %v  max %v\n",
+		len(br.durations), br.failures,
+		round(br.percentile(0)), round(mean), round(br.percentile(50)),
+		round(br.percentile(90)), round(br.percentile(99)), round(br.percentile(100)),
+	)
+	return err
+}
+
+// bench runs root with args n times, discarding the output, and
+// returns the duration of each run. The runs are not recorded, see
+// replay
+func bench(root *Command, n int, args []string) *benchResult {
+	restore := root.redirect(ioutil.Discard, ioutil.Discard)
+	defer restore()
+
+	result := &benchResult{}
+	for i := 0; i < n; i++ {
+		start := now()
+		_, err := root.replay(args)
+		result.durations = append(result.durations, now().Sub(start))
+		if err != nil {
+			result.failures++
+		}
+	}
+	return result
+}
+
+func addBenchCommand(root *Command) {
+	n := 10
+	cmd := root.SubCommand("bench",
+		DescOption("Run a command repeatedly and report how long it takes"),
+		UsageOption("<command> [args...]"),
+	)
+	cmd.Flags.IntVar(&n, "n", n, "number of times to run the command")
+	cmd.Callback = func(name string, args ...string) ([]string, error) {
+		if len(args) == 0 {
+			return args, &UserError{fmt.Errorf("%w: no command to benchmark", ErrUsage)}
+		} else if n < 1 {
+			return args, &UserError{fmt.Errorf("%w: -n must be at least 1", ErrUsage)}
+		}
+
+		stdout := root.Stdout()
+		return nil, bench(root, n, args).write(stdout)
+	}
+}
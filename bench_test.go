@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBench(t *testing.T) {
+	tests := []struct {
+		desc    string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{"runs", []string{"bench", "-n", "4", "greet", "alice"}, "4 runs, 0 failed\nmin 1ms  mean 2.5ms  p50 2ms  p90 4ms  p99 4ms  max 4ms\n", nil},
+		{"failures", []string{"bench", "-n", "2", "fail"}, "2 runs, 2 failed\nmin 1ms  mean 2ms  p50 1ms  p90 3ms  p99 3ms  max 3ms\n", nil},
+		{"no command", []string{"bench"}, "", ErrUsage},
+		{"no runs", []string{"bench", "-n", "0", "greet"}, "", ErrUsage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			durations := []time.Duration{3 * time.Millisecond, time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}
+			base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			calls := 0
+			now = func() time.Time {
+				current := base
+				if calls%2 == 1 {
+					current = base.Add(durations[calls/2])
+				}
+				calls++
+				return current
+			}
+			defer func() { now = time.Now }()
+
+			stdout := &strings.Builder{}
+			root := New("app", StdoutOption(stdout), OutputOption(&strings.Builder{}), ErrorHandlingOption(ContinueOnError))
+			root.SubCommand("greet", FuncOption(func(name string) { root.Infof("hello %s", name) }))
+			root.SubCommand("fail", FuncOption(func() error { return errors.New("failed") }))
+			AddBuiltins(root, Builtins{Bench: true})
+
+			_, err := root.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got := stdout.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestBenchNotRecorded(t *testing.T) {
+	audit := &strings.Builder{}
+	root := New("app", StdoutOption(&strings.Builder{}), OutputOption(&strings.Builder{}), ErrorHandlingOption(ContinueOnError), AuditOption(audit))
+	root.SubCommand("greet", FuncOption(func(name string) {}))
+	AddBuiltins(root, Builtins{Bench: true})
+
+	if _, err := root.Run([]string{"bench", "-n", "3", "greet", "alice"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if lines := strings.Count(audit.String(), "\n"); lines != 1 {
+		t.Errorf("Wanted only the bench command to be audited got %q", audit.String())
+	}
+}
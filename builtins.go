@@ -0,0 +1,83 @@
+package cli
+
+import "fmt"
+
+// Builtins selects the standard auxiliary commands that AddBuiltins
+// will add to a command hierarchy
+type Builtins struct {
+	// Bench adds a "bench" command that runs another command a number
+	// of times, discarding its output, and reports latency percentiles
+	Bench bool
+
+	// Completion adds a "completion" command that prints a bash
+	// completion script
+	Completion bool
+
+	// Docs adds a "docs" command that prints markdown documentation
+	// for the whole command hierarchy
+	Docs bool
+
+	// History records successful runs of root in the named file (see
+	// HistoryOption) and adds "history" and "redo" commands to list
+	// and re-run them
+	History string
+
+	// StickyFlags adds a "config unset" command that forgets the flag
+	// values remembered in the named file (see StickyFlagsOption)
+	StickyFlags string
+
+	// Stats counts how often commands and flags are used in the named
+	// file (see StatsOption) and adds a "stats" command to show or
+	// purge the counts
+	Stats string
+
+	// Version adds a "version" command that prints the version string
+	// when it is not empty
+	Version string
+}
+
+// AddBuiltins adds the selected builtin commands to root. All builtin
+// commands print to root's Stdout
+func AddBuiltins(root *Command, builtins Builtins) {
+	if builtins.Bench {
+		addBenchCommand(root)
+	}
+
+	if builtins.Completion {
+		root.SubCommand("completion",
+			DescOption("Print a bash completion script"),
+			FuncOption(func() error { return root.WriteBashCompletion(root.Stdout()) }),
+		)
+	}
+
+	if builtins.Docs {
+		root.SubCommand("docs",
+			DescOption("Print markdown documentation for all commands"),
+			FuncOption(func() error { return root.WriteDocs(root.Stdout()) }),
+		)
+	}
+
+	if builtins.History != "" {
+		HistoryOption(builtins.History)(root)
+		addHistoryCommands(root)
+	}
+
+	if builtins.StickyFlags != "" {
+		addStickyCommands(root, builtins.StickyFlags)
+	}
+
+	if builtins.Stats != "" {
+		StatsOption(builtins.Stats)(root)
+		addStatsCommand(root)
+	}
+
+	if builtins.Version != "" {
+		root.SubCommand("version",
+			DescOption("Print the version"),
+			FuncOption(func() error {
+				_, err := fmt.Fprintf(root.Stdout(), "%s %s\n", root.Name, builtins.Version)
+				return err
+			}),
+		)
+	}
+}
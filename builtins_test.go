@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddBuiltins(t *testing.T) {
+	tests := []struct {
+		desc     string
+		builtins Builtins
+		args     []string
+		want     string
+	}{
+		{"version", Builtins{Version: "1.2.3"}, []string{"version"}, "app 1.2.3\n"},
+		{"docs", Builtins{Docs: true}, []string{"docs"}, "# app\n"},
+		{"completion", Builtins{Completion: true}, []string{"completion"}, "complete -F _app_completion app\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			builder := &strings.Builder{}
+			root := New("app", StdoutOption(builder), ErrorHandlingOption(ContinueOnError))
+			AddBuiltins(root, test.builtins)
+			if len(root.SubCommands) != 1 {
+				t.Fatalf("Wanted 1 builtin command got %d", len(root.SubCommands))
+			}
+
+			if err := root.Validate(); err != nil {
+				t.Errorf("Unexpected validation error %v", err)
+			}
+
+			_, err := root.Run(test.args)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := builder.String(); !strings.Contains(got, test.want) {
+				t.Errorf("Wanted output to contain %q got %q", test.want, got)
+			}
+		})
+	}
+}
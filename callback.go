@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 )
 
@@ -12,12 +13,84 @@ type callback struct {
 	variables []interface{}
 	t         reflect.Type
 	inputErr  error
+	setResult func(interface{})
 }
 
-func Callback(f interface{}, descriptions ...string) CommandFunc {
-	cb := callback{Value: reflect.ValueOf(f), t: reflect.TypeOf(f)}
+func newCallback(f interface{}, descriptions ...string) *callback {
+	cb := &callback{Value: reflect.ValueOf(f), t: reflect.TypeOf(f)}
 	cb.process(descriptions...)
-	return cb.callback
+	return cb
+}
+
+// Callback will build a CommandFunc from the function f. Each of f's
+// parameters becomes a positional argument that is parsed from the
+// command line. Descriptions are used, in order, to describe the
+// arguments in usage output. Arguments without a description are given
+// a placeholder derived from their type, such as "<int>" (see TypeNames).
+//
+// If f's only parameter is a struct, or a pointer to one, then each of
+// its exported fields becomes a positional argument instead, in the
+// order they are declared. Fields are described by their lower case
+// name, such as "<host>", unless described by an arg tag, which can
+// also make the field optional with a default value:
+//
+//	type target struct {
+//		Host string `arg:"<host>"`
+//		Port int    `arg:"<port>,default=22"`
+//		Skip string `arg:"-"`
+//	}
+func Callback(f interface{}, descriptions ...string) CommandFunc {
+	return newCallback(f, descriptions...).callback
+}
+
+// CallbackN is the same as Callback except that it accepts argument
+// names rather than descriptions. The names are turned into
+// placeholders, for instance CallbackN(f, "host", "port") describes
+// its arguments as "<host> <port>". Use FuncNOption to also display
+// the names in the usage of a command
+func CallbackN(f interface{}, names ...string) CommandFunc {
+	return Callback(f, placeholders(names)...)
+}
+
+func placeholders(names []string) []string {
+	descriptions := make([]string, len(names))
+	for i, name := range names {
+		descriptions[i] = fmt.Sprintf("<%s>", name)
+	}
+	return descriptions
+}
+
+var sliceValueType = reflect.TypeOf((*SliceValue)(nil)).Elem()
+
+// placeholder returns a usage placeholder for an argument of type t
+func placeholder(t reflect.Type) string {
+	suffix := ""
+	if reflect.PtrTo(t).Implements(sliceValueType) || t.Implements(sliceValueType) {
+		suffix = "..."
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name := ""
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		name = "duration"
+	case reflect.TypeOf(float64(0)):
+		name = "float"
+	default:
+		name = strings.ToLower(strings.TrimSuffix(t.Name(), "Value"))
+	}
+
+	if name == "" {
+		name = "value"
+	}
+
+	if placeholder, found := typeName(reflect.New(t).Interface(), name); found {
+		return placeholder + suffix
+	}
+	return fmt.Sprintf("<%s>%s", name, suffix)
 }
 
 func getError(values []reflect.Value) error {
@@ -32,6 +105,19 @@ func getError(values []reflect.Value) error {
 	return nil
 }
 
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// getResult returns the first return value, as long as it is not the
+// trailing error
+func getResult(values []reflect.Value) (result interface{}, found bool) {
+	if len(values) > 1 || (len(values) == 1 && values[0].Type() != errorType) {
+		if values[0].CanInterface() {
+			return values[0].Interface(), true
+		}
+	}
+	return nil, false
+}
+
 func (cb *callback) callback(name string, args ...string) ([]string, error) {
 	if cb.inputErr != nil {
 		return args, cb.inputErr
@@ -39,19 +125,27 @@ func (cb *callback) callback(name string, args ...string) ([]string, error) {
 
 	err := cb.arguments.Parse(args)
 	if err == nil {
-		args = cb.arguments.Args()
-		values := []reflect.Value{}
-		for i, v := range cb.variables {
-			if cb.t.In(i).Kind() == reflect.Ptr {
-				values = append(values, reflect.ValueOf(v))
-			} else {
-				values = append(values, reflect.Indirect(reflect.ValueOf(v)))
-			}
+		args, err = cb.invoke()
+	}
+	return args, err
+}
+
+// invoke calls the callback function with the already parsed arguments
+func (cb *callback) invoke() ([]string, error) {
+	values := []reflect.Value{}
+	for i, v := range cb.variables {
+		if cb.t.In(i).Kind() == reflect.Ptr {
+			values = append(values, reflect.ValueOf(v))
+		} else {
+			values = append(values, reflect.Indirect(reflect.ValueOf(v)))
 		}
+	}
 
-		err = getError(cb.Call(values))
+	results := cb.Call(values)
+	if result, found := getResult(results); found && cb.setResult != nil {
+		cb.setResult(result)
 	}
-	return args, err
+	return cb.arguments.Args(), getError(results)
 }
 
 func (cb *callback) addVar(v interface{}) {
@@ -78,12 +172,17 @@ func (cb *callback) process(descriptions ...string) {
 		return
 	}
 
+	if cb.t.NumIn() == 1 && isArgStruct(cb.t.In(0)) {
+		cb.processStruct(cb.t.In(0), descriptions...)
+		return
+	}
+
 	for i := 0; i < cb.t.NumIn(); i++ {
-		description := ""
+		inArg := cb.t.In(i)
+		description := placeholder(inArg)
 		if i < len(descriptions) {
 			description = descriptions[i]
 		}
-		inArg := cb.t.In(i)
 		switch inArg {
 		case reflect.TypeOf(false):
 			cb.addVar(cb.arguments.Bool(description))
@@ -103,10 +202,102 @@ func (cb *callback) process(descriptions ...string) {
 			cb.addVar(cb.arguments.Uint64(description))
 		default:
 			if !cb.tryValue(inArg, description, reflect.TypeOf((*Value)(nil)).Elem(), reflect.ValueOf(cb.arguments.Var)) {
-				if !cb.tryValue(inArg, description, reflect.TypeOf((*SliceValue)(nil)).Elem(), reflect.ValueOf(cb.arguments.VarSlice)) {
+				if !cb.tryValue(inArg, description, sliceValueType, reflect.ValueOf(cb.arguments.VarSlice)) {
 					cb.inputErr = fmt.Errorf("%v must implement either Value or ValueSlice interfaces", inArg)
 				}
 			}
 		}
 	}
 }
+
+// isArgStruct reports whether a parameter of type t has its fields
+// bound to positional arguments. Structs without exported fields, such
+// as time.Time, are not
+func isArgStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	ptr := reflect.PtrTo(t)
+	if t.Kind() != reflect.Struct || ptr.Implements(reflect.TypeOf((*Value)(nil)).Elem()) || ptr.Implements(sliceValueType) {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// processStruct binds the exported fields of a struct parameter to
+// positional arguments
+func (cb *callback) processStruct(t reflect.Type, descriptions ...string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t)
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("arg")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		description := fmt.Sprintf("<%s>", strings.ToLower(field.Name))
+		if n < len(descriptions) {
+			description = descriptions[n]
+		}
+		n++
+
+		options := []ArgumentOption{}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			description = parts[0]
+		}
+
+		for _, part := range parts[1:] {
+			if def := strings.TrimPrefix(part, "default="); def != part {
+				options = append(options, Optional(def))
+			}
+		}
+
+		if err := bindArgument(&cb.arguments, v.Elem().Field(i).Addr().Interface(), description, options); err != nil {
+			cb.inputErr = fmt.Errorf("field %s: %w", field.Name, err)
+			return
+		}
+	}
+	cb.addVar(v.Interface())
+}
+
+// bindArgument adds a positional argument that is stored in p
+func bindArgument(args *Arguments, p interface{}, description string, options []ArgumentOption) error {
+	switch p := p.(type) {
+	case *bool:
+		args.BoolVar(p, description, options...)
+	case *time.Duration:
+		args.DurationVar(p, description, options...)
+	case *float64:
+		args.Float64Var(p, description, options...)
+	case *int:
+		args.IntVar(p, description, options...)
+	case *int64:
+		args.Int64Var(p, description, options...)
+	case *string:
+		args.StringVar(p, description, options...)
+	case *uint:
+		args.UintVar(p, description, options...)
+	case *uint64:
+		args.Uint64Var(p, description, options...)
+	case Value:
+		args.Var(p, description, options...)
+	case SliceValue:
+		args.VarSlice(p, description, options...)
+	default:
+		return fmt.Errorf("%T must implement either Value or ValueSlice interfaces", p)
+	}
+	return nil
+}
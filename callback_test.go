@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -12,13 +14,55 @@ func TestCallbackDesc(t *testing.T) {
 	cb := callback{Value: reflect.ValueOf(f), t: reflect.TypeOf(f)}
 	cb.process("<a>", "<b>")
 
-	for i, want := range []string{"<a>", "<b>", ""} {
+	for i, want := range []string{"<a>", "<b>", "<int>"} {
 		if cb.arguments.args[i].desc != want {
 			t.Errorf("Wanted description %q got %q", want, cb.arguments.args[i].desc)
 		}
 	}
 }
 
+func TestCallbackN(t *testing.T) {
+	f := func(host string, port int) {}
+	cmd := New("connect", FuncNOption(f, "host", "port"))
+	builder := &strings.Builder{}
+	cmd.RenderUsage(builder)
+	if want := "Usage: connect <host> <port>\n"; !strings.HasPrefix(builder.String(), want) {
+		t.Errorf("Wanted usage starting with %q got %q", want, builder.String())
+	}
+
+	cmd.Callback = CallbackN(f, "host", "port")
+	if _, err := cmd.Run([]string{"localhost", "80"}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input interface{}
+		want  string
+	}{
+		{"bool", false, "<bool>"},
+		{"duration", time.Duration(0), "<duration>"},
+		{"float64", float64(0), "<float>"},
+		{"int", int(0), "<int>"},
+		{"string", "", "<string>"},
+		{"value", boolValue(false), "<bool>"},
+		{"value pointer", new(boolValue), "<bool>"},
+		{"slice value", intSlice{}, "<intslice>..."},
+		{"unnamed", struct{}{}, "<value>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := placeholder(reflect.TypeOf(test.input))
+			if test.want != got {
+				t.Errorf("Wanted placeholder %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
 func TestCallback(t *testing.T) {
 	tests := []struct {
 		desc    string
@@ -27,8 +71,9 @@ func TestCallback(t *testing.T) {
 		wantErr string
 	}{
 		{"bool", func(b bool) error { return fmt.Errorf("%v", b) }, []string{"true"}, "true"},
-		{"bool (parse error)", func(b bool) error { return fmt.Errorf("%v", b) }, []string{"yo"}, "parse error"},
+		{"bool (parse error)", func(b bool) error { return fmt.Errorf("%v", b) }, []string{"yo"}, "Invalid Usage parse error"},
 		{"duration", func(d time.Duration) error { return fmt.Errorf("%v", d) }, []string{"1s"}, "1s"},
+		{"duration days", func(d time.Duration) error { return fmt.Errorf("%v", d) }, []string{"2d"}, "48h0m0s"},
 		{"float64", func(f float64) error { return fmt.Errorf("%v", f) }, []string{"1.234"}, "1.234"},
 		{"int", func(i int) error { return fmt.Errorf("%v", i) }, []string{"4234"}, "4234"},
 		{"int64", func(i int64) error { return fmt.Errorf("%v", i) }, []string{"934"}, "934"},
@@ -40,7 +85,7 @@ func TestCallback(t *testing.T) {
 		{"no func", "hello world", []string{"true"}, "Provided callback is not a function"},
 		{"int slice", func(i *intSlice) error { return fmt.Errorf("%v", i.String()) }, []string{"1", "2", "3", "4", "5"}, "1,2,3,4,5"},
 		{"two values", func(a, b int) error { return fmt.Errorf("%d %d", a, b) }, []string{"1", "2"}, "1 2"},
-		{"two expected one received", func(a, b int) error { return fmt.Errorf("%d %d", a, b) }, []string{"1"}, "Invalid Usage not enough arguments given"},
+		{"two expected one received", func(a, b int) error { return fmt.Errorf("%d %d", a, b) }, []string{"1"}, "Invalid Usage not enough arguments given: expected 2 (<int> <int>), got 1"},
 		{"non-value argument", func(a time.Time) error { return nil }, []string{"1"}, "time.Time must implement either Value or ValueSlice interfaces"},
 	}
 
@@ -58,3 +103,43 @@ func TestCallback(t *testing.T) {
 		})
 	}
 }
+
+func TestCallbackStruct(t *testing.T) {
+	type target struct {
+		Host    string
+		Port    int           `arg:"<port>,default=22"`
+		Timeout time.Duration `arg:",default=5s"`
+		Skip    string        `arg:"-"`
+		skip    string
+	}
+
+	tests := []struct {
+		desc    string
+		cb      interface{}
+		input   []string
+		want    string
+		wantErr error
+	}{
+		{"all", func(t target) string { return fmt.Sprintf("%s %d %v", t.Host, t.Port, t.Timeout) }, []string{"example.com", "2222", "1s"}, "example.com 2222 1s", nil},
+		{"defaults", func(t target) string { return fmt.Sprintf("%s %d %v", t.Host, t.Port, t.Timeout) }, []string{"example.com"}, "example.com 22 5s", nil},
+		{"pointer", func(t *target) string { return fmt.Sprintf("%s %d", t.Host, t.Port) }, []string{"example.com", "80"}, "example.com 80", nil},
+		{"missing", func(t target) string { return "" }, []string{}, "", ErrNumArguments},
+		{"invalid", func(t target) string { return "" }, []string{"example.com", "ssh"}, "", ErrParse},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("connect", ErrorHandlingOption(ContinueOnError), FuncOption(test.cb))
+			if got, want := cmd.usageStr(), "<host> [<port>] [<timeout>]"; want != got {
+				t.Errorf("Wanted usage %q got %q", want, got)
+			}
+
+			_, err := cmd.Run(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			} else if got := cmd.Result(); err == nil && test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -2,26 +2,356 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"strconv"
 	"strings"
 )
 
+// NoInput disables all interactive prompts. When set, prompts return
+// their default value or fail with ErrNonInteractive
+var NoInput = false
+
+// NoInputOption adds a -no-input flag to the command that sets NoInput
+func NoInputOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.BoolVar(&NoInput, "no-input", NoInput, "disable interactive prompts")
+	}
+}
+
+// DisableInputOption makes the prompts of the command, and of the
+// subcommands added after the option, behave as though NoInput was set
+func DisableInputOption() Option {
+	return func(cmd *Command) { cmd.noInput = true }
+}
+
+// Interactive reports whether the user can be prompted for input read
+// from reader. It returns false if NoInput is set. If reader is a file
+// (such as os.Stdin) then Interactive also returns false when the CI
+// environment variable is "true" or when the file is not a terminal.
+// The prompts of a command read scripted responses from a file that is
+// not a terminal, such as piped input, when the file has been set with
+// StdinOption or SetStdin
+func Interactive(reader io.Reader) bool {
+	if NoInput {
+		return false
+	}
+
+	switch r := reader.(type) {
+	case noInputReader:
+		return false
+	case *os.File:
+		if os.Getenv("CI") == "true" || !isTerminal(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// noInputReader is read from by the prompts of commands that have input
+// disabled
+type noInputReader struct{}
+
+func (noInputReader) Read([]byte) (int, error) { return 0, io.EOF }
+
+// Query writes message to writer and reads a response from reader until
+// the response matches one of the acceptable values. Responses are
+// compared case insensitively and the response is returned in lower case.
+// If Interactive(reader) is false, or the input ends before an acceptable
+// response is read, an empty string is returned even when it is not one
+// of the acceptable values. Use QueryContext to tell these cases apart
+// from a response
 func Query(reader io.Reader, writer io.Writer, message string, acceptable ...string) (resp string) {
-	accept := make(map[string]bool, len(acceptable))
-	for _, a := range acceptable {
-		accept[strings.ToLower(strings.TrimSpace(a))] = true
+	resp, _ = queryContext(context.Background(), reader, writer, DefaultTheme, message, acceptable...)
+	return resp
+}
+
+// QueryContext is the same as Query, except that it returns an error
+// instead of an empty string when a response can not be read. The
+// error is ErrNonInteractive if Interactive(reader) is false,
+// io.ErrUnexpectedEOF if the input ends, or the context's error if ctx
+// is done before a response is read. When ctx is done, the read from
+// reader is not interrupted. The line it reads is the response to the
+// next prompt that reads from reader
+func QueryContext(ctx context.Context, reader io.Reader, writer io.Writer, message string, acceptable ...string) (string, error) {
+	return queryContext(ctx, reader, writer, DefaultTheme, message, acceptable...)
+}
+
+func queryContext(ctx context.Context, reader io.Reader, writer io.Writer, theme Theme, message string, acceptable ...string) (string, error) {
+	return ask(ctx, reader, writer, theme, message, QueryChoices(acceptable...))
+}
+
+// QueryFunc writes message to writer and reads a response from reader
+// until validate accepts the response. Responses are trimmed but are
+// otherwise returned as they were entered. The error returned by
+// validate is displayed before prompting again. ErrNonInteractive is
+// returned if Interactive(reader) is false and io.ErrUnexpectedEOF is
+// returned if the input ends before a response is accepted
+func QueryFunc(reader io.Reader, writer io.Writer, message string, validate func(string) error) (string, error) {
+	return queryFunc(context.Background(), reader, writer, DefaultTheme, message, validate)
+}
+
+// QueryValue prompts for a response in the same way as QueryFunc and
+// sets value with it. Values, such as those used for flags, validate
+// their input when set, so QueryValue can be used to prompt for numbers,
+// durations and other parsed types
+func QueryValue(reader io.Reader, writer io.Writer, message string, value Value) error {
+	_, err := QueryFunc(reader, writer, message, value.Set)
+	return err
+}
+
+func queryFunc(ctx context.Context, reader io.Reader, writer io.Writer, theme Theme, message string, validate func(string) error) (string, error) {
+	return ask(ctx, reader, writer, theme, message, QueryValidate(validate))
+}
+
+// QueryOption configures a prompt made with Ask
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	choices     []string
+	def         *string
+	showChoices bool
+	attempts    int
+	invalid     string
+	validate    func(string) error
+}
+
+// QueryChoices limits the accepted responses to choices. Responses are
+// compared case insensitively and are returned in lower case
+func QueryChoices(choices ...string) QueryOption {
+	return func(qc *queryConfig) {
+		for _, choice := range choices {
+			qc.choices = append(qc.choices, strings.ToLower(strings.TrimSpace(choice)))
+		}
+	}
+}
+
+// QueryDefault is returned when the response is empty, or when it is
+// not possible to prompt
+func QueryDefault(def string) QueryOption {
+	return func(qc *queryConfig) { qc.def = &def }
+}
+
+// QueryShowChoices displays the choices after the message, for instance
+// "[y/N]". The default choice is displayed in upper case
+func QueryShowChoices() QueryOption {
+	return func(qc *queryConfig) { qc.showChoices = true }
+}
+
+// QueryMaxAttempts gives up with ErrTooManyAttempts after n invalid
+// responses
+func QueryMaxAttempts(n int) QueryOption {
+	return func(qc *queryConfig) { qc.attempts = n }
+}
+
+// QueryInvalid replaces the theme's message for invalid responses
+func QueryInvalid(message string) QueryOption {
+	return func(qc *queryConfig) { qc.invalid = message }
+}
+
+// QueryValidate only accepts responses for which validate returns nil.
+// The error is displayed before prompting again
+func QueryValidate(validate func(string) error) QueryOption {
+	return func(qc *queryConfig) { qc.validate = validate }
+}
+
+// prompt returns the message with the choices added, if they are shown
+func (qc *queryConfig) prompt(message string) string {
+	if !qc.showChoices || len(qc.choices) == 0 {
+		return message
+	}
+
+	choices := make([]string, len(qc.choices))
+	for i, choice := range qc.choices {
+		if qc.def != nil && choice == strings.ToLower(*qc.def) {
+			choice = strings.ToUpper(choice)
+		}
+		choices[i] = choice
+	}
+	return fmt.Sprintf("%s [%s] ", strings.TrimRight(message, " "), strings.Join(choices, "/"))
+}
+
+func (qc *queryConfig) accept(resp string) (string, error) {
+	if resp == "" && qc.def != nil {
+		return *qc.def, nil
+	}
+
+	if len(qc.choices) > 0 {
+		resp = strings.ToLower(resp)
+		found := false
+		for _, choice := range qc.choices {
+			if resp == choice {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return "", errInvalidInput
+		}
+	}
+
+	if qc.validate != nil {
+		if err := qc.validate(resp); err != nil {
+			return "", err
+		}
+	}
+	return resp, nil
+}
+
+// Ask writes message to writer and reads a response from reader until
+// the response is accepted. Any response is accepted unless options,
+// such as QueryChoices or QueryValidate, restrict it. Responses are
+// trimmed of surrounding whitespace. If it is not possible to prompt
+// (see Interactive) the default is returned when one has been set with
+// QueryDefault, otherwise ErrNonInteractive is returned. If the input
+// ends before a response is accepted, io.ErrUnexpectedEOF is returned
+func Ask(reader io.Reader, writer io.Writer, message string, options ...QueryOption) (string, error) {
+	return ask(context.Background(), reader, writer, DefaultTheme, message, options...)
+}
+
+func ask(ctx context.Context, reader io.Reader, writer io.Writer, theme Theme, message string, options ...QueryOption) (resp string, err error) {
+	qc := &queryConfig{}
+	for _, option := range options {
+		option(qc)
+	}
+
+	if !Interactive(reader) {
+		if qc.def != nil {
+			return *qc.def, nil
+		}
+		return "", ErrNonInteractive
+	}
+
+	if qc.invalid != "" {
+		theme.Invalid = qc.invalid
+	}
+
+	attempts := 0
+	err = promptContext(ctx, reader, bufio.NewReader(reader), writer, theme, qc.prompt(message), func(r string) error {
+		r, err := qc.accept(r)
+		if err != nil {
+			attempts++
+			if qc.attempts > 0 && attempts >= qc.attempts {
+				return &stopPrompt{err}
+			}
+			return err
+		}
+		resp = r
+		return nil
+	})
+	return resp, err
+}
+
+// Confirm asks a yes or no question. An empty response, or not being
+// able to prompt (see Interactive), results in def being returned
+func Confirm(reader io.Reader, writer io.Writer, message string, def bool) bool {
+	return confirm(reader, writer, DefaultTheme, message, def)
+}
+
+func confirm(reader io.Reader, writer io.Writer, theme Theme, message string, def bool) bool {
+	choices := " [y/N] "
+	if def {
+		choices = " [Y/n] "
+	}
+
+	resp, _ := queryContext(context.Background(), reader, writer, theme, message+choices, "y", "yes", "n", "no", "")
+	switch resp {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// Select asks the user to choose one of choices, which are displayed as
+// a numbered list. A choice is made by entering its number or its text.
+// Any other response filters the list to the choices containing it,
+// ignoring case. A choice is made when a single choice matches,
+// otherwise the matching choices are displayed and the list can be
+// filtered further, or reset with an empty response. The index of the
+// chosen item is returned. When the list is not filtered, an empty
+// response chooses def, which is marked with the theme's cursor, unless
+// def is negative. If Interactive(reader) is false then def is returned,
+// or ErrNonInteractive if def is negative
+func Select(reader io.Reader, writer io.Writer, message string, choices []string, def int) (int, error) {
+	return selectChoice(reader, writer, DefaultTheme, message, choices, def)
+}
+
+func selectChoice(reader io.Reader, writer io.Writer, theme Theme, message string, choices []string, def int) (choice int, err error) {
+	if !Interactive(reader) {
+		if def < 0 || def >= len(choices) {
+			return -1, ErrNonInteractive
+		}
+		return def, nil
+	}
+
+	all := make([]int, len(choices))
+	for i := range choices {
+		all[i] = i
 	}
 
 	buf := bufio.NewReader(reader)
-	for {
-		fmt.Fprint(writer, message)
-		resp, _ = buf.ReadString('\n')
-		resp = strings.ToLower(strings.TrimSpace(resp))
-		if accept[resp] {
-			break
+	fmt.Fprintln(writer, theme.prompt(message))
+	blank := strings.Repeat(" ", len(theme.Cursor))
+	for shown := all; ; {
+		for _, i := range shown {
+			cursor := blank
+			if i == def {
+				cursor = theme.Cursor
+			}
+			fmt.Fprintf(writer, "%s %d) %s\n", cursor, i+1, choices[i])
+		}
+
+		var filtered []int
+		err = prompt(buf, writer, theme, "choice: ", func(resp string) error {
+			filtered = nil
+			if resp == "" && len(shown) < len(choices) {
+				filtered = all
+				return nil
+			} else if resp == "" && def >= 0 && def < len(choices) {
+				choice = def
+				return nil
+			}
+
+			for i, c := range choices {
+				if resp == c || resp == strconv.Itoa(i+1) {
+					choice = i
+					return nil
+				}
+			}
+
+			filtered = filterChoices(choices, shown, resp)
+			switch len(filtered) {
+			case 0:
+				return fmt.Errorf("%q is not one of the choices", resp)
+			case 1:
+				choice, filtered = filtered[0], nil
+			}
+			return nil
+		})
+
+		if err != nil {
+			return -1, err
+		} else if filtered == nil {
+			return choice, nil
 		}
-		fmt.Fprintf(writer, "Invalid input\n")
+		shown = filtered
 	}
-	return resp
+}
+
+// filterChoices returns the indexes in shown of the choices that
+// contain filter, ignoring case
+func filterChoices(choices []string, shown []int, filter string) []int {
+	filter = strings.ToLower(filter)
+	matches := []int{}
+	for _, i := range shown {
+		if strings.Contains(strings.ToLower(choices[i]), filter) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
 }
@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestQuery(t *testing.T) {
@@ -15,6 +22,8 @@ func TestQuery(t *testing.T) {
 	}{
 		{"good input", "y\n", []string{"Y"}, "", "y"},
 		{"bad input", "n\ny\n", []string{"Y"}, "Invalid input\n", "y"},
+		{"end of input", "n\n", []string{"Y"}, "Invalid input\n", ""},
+		{"no newline", "y", []string{"Y"}, "", "y"},
 	}
 
 	for _, test := range tests {
@@ -34,3 +43,402 @@ func TestQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	blocked, _ := io.Pipe()
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		reader   io.Reader
+		noInput  bool
+		wantResp string
+		wantErr  error
+	}{
+		{"good input", context.Background(), strings.NewReader("y\n"), false, "y", nil},
+		{"end of input", context.Background(), strings.NewReader("n\n"), false, "", io.ErrUnexpectedEOF},
+		{"empty input", context.Background(), strings.NewReader(""), false, "", io.ErrUnexpectedEOF},
+		{"canceled", canceled, blocked, false, "", context.Canceled},
+		{"no input", context.Background(), strings.NewReader("y\n"), true, "", ErrNonInteractive},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			NoInput = test.noInput
+			defer func() { NoInput = false }()
+
+			gotResp, err := QueryContext(test.ctx, test.reader, ioutil.Discard, "ok? ", "y")
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantResp != gotResp {
+				t.Errorf("Wanted %q got %q", test.wantResp, gotResp)
+			}
+		})
+	}
+}
+
+// startedReader closes started when it is first read from
+type startedReader struct {
+	io.Reader
+	started chan struct{}
+	once    sync.Once
+}
+
+func (sr *startedReader) Read(p []byte) (int, error) {
+	sr.once.Do(func() { close(sr.started) })
+	return sr.Reader.Read(p)
+}
+
+func TestQueryContextAbandoned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pipe, writer := io.Pipe()
+	reader := &startedReader{Reader: pipe, started: make(chan struct{})}
+	go func() {
+		<-reader.started
+		cancel()
+	}()
+
+	if _, err := QueryContext(ctx, reader, ioutil.Discard, "ok? ", "y"); err != context.Canceled {
+		t.Errorf("Wanted error %v got %v", context.Canceled, err)
+	}
+
+	done := make(chan string)
+	go func() {
+		resp, _ := QueryContext(context.Background(), reader, ioutil.Discard, "ok? ", "y")
+		done <- resp
+	}()
+	writer.Write([]byte("y\n"))
+
+	select {
+	case resp := <-done:
+		if resp != "y" {
+			t.Errorf("Wanted %q got %q", "y", resp)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the abandoned read to answer the next query")
+	}
+}
+
+func TestQueryFunc(t *testing.T) {
+	validate := func(s string) error {
+		if !strings.Contains(s, ".") {
+			return errors.New("not a hostname")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		desc       string
+		input      string
+		wantResp   string
+		wantErr    error
+		wantOutput string
+	}{
+		{"good input", "  Example.com \n", "Example.com", nil, "host: "},
+		{"bad input", "localhost\nexample.com\n", "example.com", nil, "host: Invalid input: not a hostname\nhost: "},
+		{"end of input", "localhost\n", "", io.ErrUnexpectedEOF, "host: Invalid input: not a hostname\nhost: "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			writer := &strings.Builder{}
+			gotResp, err := QueryFunc(strings.NewReader(test.input), writer, "host: ", validate)
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantResp != gotResp {
+				t.Errorf("Wanted %q got %q", test.wantResp, gotResp)
+			}
+
+			if gotOutput := writer.String(); test.wantOutput != gotOutput {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestQueryValue(t *testing.T) {
+	var port int
+	args := &Arguments{}
+	args.IntVar(&port, "port")
+
+	writer := &strings.Builder{}
+	err := QueryValue(strings.NewReader("http\n8080\n"), writer, "port: ", args.args[0].value.(Value))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if port != 8080 {
+		t.Errorf("Wanted 8080 got %d", port)
+	}
+
+	want := "port: Invalid input: Invalid Usage parse error\nport: "
+	if got := writer.String(); want != got {
+		t.Errorf("Wanted output %q got %q", want, got)
+	}
+
+	NoInput = true
+	defer func() { NoInput = false }()
+	if err := QueryValue(strings.NewReader("1\n"), writer, "port: ", args.args[0].value.(Value)); err != ErrNonInteractive {
+		t.Errorf("Wanted %v got %v", ErrNonInteractive, err)
+	}
+}
+
+func TestAsk(t *testing.T) {
+	yesNo := QueryChoices("y", "n")
+	tests := []struct {
+		desc       string
+		input      string
+		options    []QueryOption
+		noInput    bool
+		wantResp   string
+		wantErr    error
+		wantOutput string
+	}{
+		{"anything", " Foo \n", nil, false, "Foo", nil, "ok? "},
+		{"choices", "Y\n", []QueryOption{yesNo}, false, "y", nil, "ok? "},
+		{"show choices", "y\n", []QueryOption{yesNo, QueryShowChoices()}, false, "y", nil, "ok? [y/n] "},
+		{"show default", "\n", []QueryOption{yesNo, QueryDefault("n"), QueryShowChoices()}, false, "n", nil, "ok? [y/N] "},
+		{"invalid message", "x\ny\n", []QueryOption{yesNo, QueryInvalid("Please answer y or n")}, false, "y", nil, "ok? Please answer y or n\nok? "},
+		{"validate", "x\ny\n", []QueryOption{QueryValidate(func(s string) error {
+			if s != "y" {
+				return errors.New("not y")
+			}
+			return nil
+		})}, false, "y", nil, "ok? Invalid input: not y\nok? "},
+		{"max attempts", "x\nz\ny\n", []QueryOption{yesNo, QueryMaxAttempts(2)}, false, "", ErrTooManyAttempts, "ok? Invalid input\nok? Invalid input\n"},
+		{"within attempts", "x\ny\n", []QueryOption{yesNo, QueryMaxAttempts(2)}, false, "y", nil, "ok? Invalid input\nok? "},
+		{"no input default", "", []QueryOption{QueryDefault("y")}, true, "y", nil, ""},
+		{"no input", "", nil, true, "", ErrNonInteractive, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			NoInput = test.noInput
+			defer func() { NoInput = false }()
+
+			writer := &strings.Builder{}
+			gotResp, err := Ask(strings.NewReader(test.input), writer, "ok? ", test.options...)
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantResp != gotResp {
+				t.Errorf("Wanted %q got %q", test.wantResp, gotResp)
+			}
+
+			if gotOutput := writer.String(); test.wantOutput != gotOutput {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestInteractive(t *testing.T) {
+	if !Interactive(strings.NewReader("")) {
+		t.Errorf("Expected readers to be interactive")
+	}
+
+	NoInput = true
+	defer func() { NoInput = false }()
+	if Interactive(strings.NewReader("")) {
+		t.Errorf("Expected NoInput to disable prompts")
+	}
+
+	writer := &strings.Builder{}
+	if resp := Query(strings.NewReader("y\n"), writer, "", "y"); resp != "" || writer.Len() != 0 {
+		t.Errorf("Expected Query to return immediately got %q", resp)
+	}
+
+	if _, err := New("app", ErrorHandlingOption(ContinueOnError)).Wizard(strings.NewReader(""), writer); err != ErrNonInteractive {
+		t.Errorf("Wanted %v got %v", ErrNonInteractive, err)
+	}
+}
+
+func TestNoInputOption(t *testing.T) {
+	defer func() { NoInput = false }()
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), NoInputOption(), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	if _, err := cmd.Run([]string{"-no-input"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !NoInput {
+		t.Errorf("Expected -no-input to set NoInput")
+	}
+}
+
+func TestInteractivePipe(t *testing.T) {
+	tests := []struct {
+		desc     string
+		ci       string
+		setStdin bool
+		want     string
+	}{
+		{"pipe", "", false, ""},
+		{"pipe in CI", "true", false, ""},
+		{"stdin option", "", true, "y"},
+		{"stdin option in CI", "true", true, "y"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			defer r.Close()
+			w.WriteString("y\n")
+			w.Close()
+
+			prev := os.Getenv("CI")
+			os.Setenv("CI", test.ci)
+			defer os.Setenv("CI", prev)
+
+			got := ""
+			if test.setStdin {
+				got = New("app", StdinOption(r), StdoutOption(ioutil.Discard)).Query("", "y")
+			} else {
+				got = Query(r, ioutil.Discard, "", "y")
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestDisableInputOption(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), DisableInputOption())
+	sub := cmd.SubCommand("sub")
+	for _, c := range []*Command{cmd, sub} {
+		c.SetStdin(strings.NewReader("y\n"))
+		c.SetStdout(ioutil.Discard)
+		if resp := c.Query("", "y"); resp != "" {
+			t.Errorf("%s: Expected Query to return immediately got %q", c.Name, resp)
+		}
+
+		if _, err := c.Wizard(strings.NewReader(""), ioutil.Discard); err != ErrNonInteractive {
+			t.Errorf("%s: Wanted %v got %v", c.Name, ErrNonInteractive, err)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		desc       string
+		input      string
+		def        bool
+		noInput    bool
+		want       bool
+		wantOutput string
+	}{
+		{"yes", "yes\n", false, false, true, "ok? [y/N] "},
+		{"no", "n\n", true, false, false, "ok? [Y/n] "},
+		{"default", "\n", true, false, true, "ok? [Y/n] "},
+		{"invalid", "maybe\ny\n", false, false, true, "ok? [y/N] Invalid input\nok? [y/N] "},
+		{"no input", "n\n", true, true, true, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			NoInput = test.noInput
+			defer func() { NoInput = false }()
+
+			writer := &strings.Builder{}
+			got := Confirm(strings.NewReader(test.input), writer, "ok?", test.def)
+			if test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+
+			if gotOutput := writer.String(); test.wantOutput != gotOutput {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	choices := []string{"red", "green"}
+	tests := []struct {
+		desc       string
+		input      string
+		def        int
+		noInput    bool
+		want       int
+		wantErr    error
+		wantOutput string
+	}{
+		{"number", "2\n", -1, false, 1, nil, "color?\n  1) red\n  2) green\nchoice: "},
+		{"text", "red\n", -1, false, 0, nil, "color?\n  1) red\n  2) green\nchoice: "},
+		{"default", "\n", 1, false, 1, nil, "color?\n  1) red\n> 2) green\nchoice: "},
+		{"invalid", "blue\n1\n", -1, false, 0, nil, "color?\n  1) red\n  2) green\nchoice: Invalid input: \"blue\" is not one of the choices\nchoice: "},
+		{"eof", "", -1, false, -1, io.ErrUnexpectedEOF, "color?\n  1) red\n  2) green\nchoice: "},
+		{"no input", "", 0, true, 0, nil, ""},
+		{"no input without default", "", -1, true, -1, ErrNonInteractive, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			NoInput = test.noInput
+			defer func() { NoInput = false }()
+
+			writer := &strings.Builder{}
+			got, err := Select(strings.NewReader(test.input), writer, "color?", choices, test.def)
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+
+			if gotOutput := writer.String(); test.wantOutput != gotOutput {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestSelectFilter(t *testing.T) {
+	choices := []string{"red", "green", "blue", "black"}
+	list := "  1) red\n  2) green\n  3) blue\n  4) black\n"
+	defList := "  1) red\n> 2) green\n  3) blue\n  4) black\n"
+	tests := []struct {
+		desc       string
+		input      string
+		def        int
+		want       int
+		wantOutput string
+	}{
+		{"one match", "GRE\n", -1, 1, "color?\n" + list + "choice: "},
+		{"many matches", "bl\nu\n", -1, 2, "color?\n" + list + "choice:   3) blue\n  4) black\nchoice: "},
+		{"number after filter", "bl\n4\n", -1, 3, "color?\n" + list + "choice:   3) blue\n  4) black\nchoice: "},
+		{"reset", "bl\n\ned\n", -1, 0, "color?\n" + list + "choice:   3) blue\n  4) black\nchoice: " + list + "choice: "},
+		{"reset with default", "bl\n\n\n", 1, 1, "color?\n" + defList + "choice:   3) blue\n  4) black\nchoice: " + defList + "choice: "},
+		{"no match", "bl\nr\nk\n", -1, 3, "color?\n" + list + "choice:   3) blue\n  4) black\nchoice: Invalid input: \"r\" is not one of the choices\nchoice: "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			writer := &strings.Builder{}
+			got, err := Select(strings.NewReader(test.input), writer, "color?", choices, test.def)
+			if err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+
+			if gotOutput := writer.String(); test.wantOutput != gotOutput {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, gotOutput)
+			}
+		})
+	}
+}
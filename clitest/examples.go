@@ -0,0 +1,24 @@
+package clitest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+// Examples fails the test for each runnable example in the hierarchy
+// rooted at cmd that is no longer valid
+func Examples(t testing.TB, cmd *cli.Command) {
+	t.Helper()
+	if err := cmd.CheckExamples(); err != nil {
+		var errs cli.Errors
+		if !errors.As(err, &errs) {
+			errs = cli.Errors{err}
+		}
+
+		for _, err := range errs {
+			t.Error(err)
+		}
+	}
+}
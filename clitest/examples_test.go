@@ -0,0 +1,33 @@
+package clitest
+
+import (
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Error(args ...interface{}) {
+	r.errors = append(r.errors, args[0].(error).Error())
+}
+
+func TestExamples(t *testing.T) {
+	root := cli.New("app", cli.ErrorHandlingOption(cli.ContinueOnError))
+	root.SubCommand("add", cli.FuncOption(func(a, b int) {}),
+		cli.RunnableExampleOption("valid", "1", "2"),
+		cli.RunnableExampleOption("invalid", "1", "two"),
+		cli.RunnableExampleOption("missing", "1"),
+	)
+
+	tb := &recordingTB{TB: t}
+	Examples(tb, root)
+	if len(tb.errors) != 2 {
+		t.Errorf("Wanted 2 errors got %d: %v", len(tb.errors), tb.errors)
+	}
+}
@@ -0,0 +1,92 @@
+// Package clitest provides helpers for testing applications built with
+// the cli package
+package clitest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+// updateFlag makes GoldenUsage update golden files instead of comparing
+// against them. It is defined for every test binary that imports
+// clitest, so those tests must not define an -update flag of their own
+var updateFlag = flag.Bool("update", false, "update golden files")
+
+// UpdateEnv is an environment variable that, when it is set to a
+// non-empty value, updates golden files in the same way as the -update
+// flag. Unlike the flag, it can be given to test binaries that do not
+// import clitest, for example:
+//
+//	CLI_UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "CLI_UPDATE_GOLDEN"
+
+// update reports whether golden files should be updated
+func update() bool { return *updateFlag || os.Getenv(UpdateEnv) != "" }
+
+// Usage renders the usage of cmd and returns it as a string
+func Usage(cmd *cli.Command) string {
+	builder := &strings.Builder{}
+	cmd.RenderUsage(builder)
+	return builder.String()
+}
+
+// GoldenUsage compares the usage of cmd to the contents of the golden
+// file at path and fails the test if they differ. When the test binary
+// is run with the -update flag, or the UpdateEnv environment variable is
+// set, the golden file is (re)written instead
+func GoldenUsage(t testing.TB, cmd *cli.Command, path string) {
+	t.Helper()
+	got := Usage(cmd)
+	if update() {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(got), 0644)
+		}
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+		return
+	}
+
+	if d := diff(string(want), got); d != "" {
+		t.Errorf("Usage does not match %s (-want +got):\n%s", path, d)
+	}
+}
+
+// diff returns a line by line comparison of want and got or an
+// empty string if they are the same
+func diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	builder := &strings.Builder{}
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		switch {
+		case i >= len(wantLines):
+			fmt.Fprintf(builder, "+%s\n", gotLines[i])
+		case i >= len(gotLines):
+			fmt.Fprintf(builder, "-%s\n", wantLines[i])
+		case wantLines[i] != gotLines[i]:
+			fmt.Fprintf(builder, "-%s\n+%s\n", wantLines[i], gotLines[i])
+		default:
+			fmt.Fprintf(builder, " %s\n", wantLines[i])
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,128 @@
+package clitest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+type testTB struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (tb *testTB) Helper() {}
+
+func (tb *testTB) Errorf(format string, args ...interface{}) {
+	tb.failed = true
+	tb.msg = fmt.Sprintf(format, args...)
+}
+
+func (tb *testTB) Fatalf(format string, args ...interface{}) {
+	tb.Errorf(format, args...)
+}
+
+func testCommand() *cli.Command {
+	cmd := cli.New("app")
+	cmd.Flags.String("config", "", "config file")
+	cmd.SubCommand("foo", cli.DescOption("do foo"))
+	cmd.SubCommand("bar", cli.UsageOption("<baz>"), cli.DescOption("do bar"))
+	return cmd
+}
+
+func TestUsageRestoresOutput(t *testing.T) {
+	cmd := testCommand()
+	output := &strings.Builder{}
+	cmd.SetOutput(output)
+	if Usage(cmd) == "" {
+		t.Errorf("Expected usage to be rendered")
+	}
+
+	if cmd.Output() != output || output.Len() != 0 {
+		t.Errorf("Expected output to be restored")
+	}
+}
+
+func TestGoldenUsage(t *testing.T) {
+	GoldenUsage(t, testCommand(), filepath.Join("testdata", "usage.golden"))
+}
+
+func TestGoldenUsageMismatch(t *testing.T) {
+	tb := &testTB{}
+	cmd := testCommand()
+	cmd.SubCommand("new", cli.DescOption("a command that isn't in the golden file"))
+	GoldenUsage(tb, cmd, filepath.Join("testdata", "usage.golden"))
+	if !tb.failed {
+		t.Errorf("Expected golden usage to fail")
+	} else if !strings.Contains(tb.msg, "+new") {
+		t.Errorf("Expected diff to include the new command got %q", tb.msg)
+	}
+}
+
+func TestGoldenUsageUpdate(t *testing.T) {
+	tests := []struct {
+		desc string
+		set  func() func()
+	}{
+		{"flag", func() func() {
+			*updateFlag = true
+			return func() { *updateFlag = false }
+		}},
+		{"env", func() func() {
+			prev := os.Getenv(UpdateEnv)
+			os.Setenv(UpdateEnv, "1")
+			return func() { os.Setenv(UpdateEnv, prev) }
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			defer test.set()()
+
+			path := filepath.Join(dir, "testdata", "usage.golden")
+			GoldenUsage(t, testCommand(), path)
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected golden file to be written: %v", err)
+			}
+
+			if want := Usage(testCommand()); want != string(got) {
+				t.Errorf("Wanted %q got %q", want, string(got))
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		desc string
+		want string
+		got  string
+		diff string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{"changed", "a\nb", "a\nc", " a\n-b\n+c\n"},
+		{"added", "a", "a\nb", " a\n+b\n"},
+		{"removed", "a\nb", "a", " a\n-b\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := diff(test.want, test.got)
+			if test.diff != got {
+				t.Errorf("Wanted diff %q got %q", test.diff, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package clitest
+
+import (
+	"flag"
+
+	"github.com/abates/cli"
+)
+
+// FlagRecord is the resolved state of a flag
+type FlagRecord struct {
+	Name    string
+	Value   string
+	Default string
+
+	// Set is true if the flag was given on the command line
+	Set bool
+}
+
+// CommandRecord is the resolved state of a command that was run
+type CommandRecord struct {
+	Name string
+
+	// Args are the positional arguments passed to the command's callback
+	Args  []string
+	Flags []FlagRecord
+}
+
+// Invocation records the commands run by a Command
+type Invocation struct {
+	Commands []CommandRecord
+}
+
+// RecordInvocation prepares every command in the hierarchy rooted at
+// cmd so that the flags and arguments they resolve are recorded in the
+// returned Invocation when cmd is run. This lets tests assert on the
+// parsed input of a command rather than on its side effects. Callbacks
+// are still run as normal
+func RecordInvocation(cmd *cli.Command) *Invocation {
+	inv := &Invocation{}
+	inv.wrap(cmd)
+	return inv
+}
+
+func (inv *Invocation) wrap(cmd *cli.Command) {
+	callback := cmd.Callback
+	cmd.Callback = func(name string, args ...string) ([]string, error) {
+		inv.record(cmd, args)
+		if callback == nil {
+			return args, cli.ErrNoCommandFunc
+		}
+		return callback(name, args...)
+	}
+
+	for _, subCmd := range cmd.SubCommands {
+		inv.wrap(subCmd)
+	}
+}
+
+func (inv *Invocation) record(cmd *cli.Command, args []string) {
+	cr := CommandRecord{Name: cmd.Name, Args: append([]string{}, args...)}
+	set := make(map[string]bool)
+	cmd.Flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		cr.Flags = append(cr.Flags, FlagRecord{Name: f.Name, Value: f.Value.String(), Default: f.DefValue, Set: set[f.Name]})
+	})
+	inv.Commands = append(inv.Commands, cr)
+}
+
+// Reset clears the recorded commands
+func (inv *Invocation) Reset() {
+	inv.Commands = nil
+}
+
+// Path returns the names of the recorded commands
+func (inv *Invocation) Path() []string {
+	path := make([]string, len(inv.Commands))
+	for i, cr := range inv.Commands {
+		path[i] = cr.Name
+	}
+	return path
+}
+
+// Args returns the positional arguments passed to the last recorded
+// command
+func (inv *Invocation) Args() []string {
+	if len(inv.Commands) == 0 {
+		return nil
+	}
+	return inv.Commands[len(inv.Commands)-1].Args
+}
+
+// Flag returns the record of the named flag. If more than one command
+// defines the flag, the record from the deepest command is returned
+func (inv *Invocation) Flag(name string) (record FlagRecord, found bool) {
+	for _, cr := range inv.Commands {
+		for _, fr := range cr.Flags {
+			if fr.Name == name {
+				record, found = fr, true
+			}
+		}
+	}
+	return record, found
+}
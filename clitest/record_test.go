@@ -0,0 +1,58 @@
+package clitest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+func TestRecordInvocation(t *testing.T) {
+	ran := false
+	root := cli.New("app", cli.ErrorHandlingOption(cli.ContinueOnError))
+	root.Flags.Bool("v", false, "verbose")
+	sub := root.SubCommand("copy", cli.FuncOption(func(src, dst string) { ran = true }))
+	sub.Flags.Int("n", 1, "count")
+	root.SubCommand("empty")
+
+	inv := RecordInvocation(root)
+	if _, err := root.Run([]string{"-v", "copy", "a", "b"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !ran {
+		t.Errorf("Expected callback to run")
+	}
+
+	if got := inv.Path(); !reflect.DeepEqual([]string{"app", "copy"}, got) {
+		t.Errorf("Wanted path [app copy] got %v", got)
+	}
+
+	if got := inv.Args(); !reflect.DeepEqual([]string{"a", "b"}, got) {
+		t.Errorf("Wanted args [a b] got %v", got)
+	}
+
+	want := FlagRecord{Name: "v", Value: "true", Default: "false", Set: true}
+	if got, found := inv.Flag("v"); !found || want != got {
+		t.Errorf("Wanted %+v got %+v", want, got)
+	}
+
+	want = FlagRecord{Name: "n", Value: "1", Default: "1", Set: false}
+	if got, found := inv.Flag("n"); !found || want != got {
+		t.Errorf("Wanted %+v got %+v", want, got)
+	}
+
+	if _, found := inv.Flag("missing"); found {
+		t.Errorf("Expected missing flag not to be found")
+	}
+
+	inv.Reset()
+	if _, err := root.Run([]string{"empty"}); !errors.Is(err, cli.ErrNoCommandFunc) {
+		t.Errorf("Wanted %v got %v", cli.ErrNoCommandFunc, err)
+	}
+
+	if got := inv.Path(); !reflect.DeepEqual([]string{"app", "empty"}, got) {
+		t.Errorf("Wanted path [app empty] got %v", got)
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// valueCloner is implemented by Values that cloneValue and assignValue
+// can not copy on their own, usually because they refer to the variable
+// they set
+type valueCloner interface {
+	cloneValue() (flag.Value, error)
+	assignValue(from flag.Value)
+}
+
+// cloneFlagSet returns a copy of flags with copies of all of its values,
+// so that parsing with the copy leaves flags and its values unchanged.
+// An error is returned if any of the values can not be copied
+func cloneFlagSet(flags *flag.FlagSet) (clone *flag.FlagSet, err error) {
+	clone = flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	flags.VisitAll(func(f *flag.Flag) {
+		value, e := cloneValue(f.Value)
+		if e != nil {
+			if err == nil {
+				err = fmt.Errorf("-%s: %w", f.Name, e)
+			}
+			return
+		}
+		clone.Var(value.(flag.Value), f.Name, f.Usage)
+		clone.Lookup(f.Name).DefValue = f.DefValue
+	})
+	return clone, err
+}
+
+// cloneValue returns a copy of value, a Value or SliceValue, that can be
+// set without changing value. Pointers to values that do not refer to
+// other memory, such as those created by the flag package, are copied
+// as are slices of such values and structs that only wrap another
+// Value. ErrNotCloneable is returned for any other value that does not
+// implement valueCloner
+func cloneValue(value interface{}) (interface{}, error) {
+	if vc, ok := value.(valueCloner); ok {
+		return vc.cloneValue()
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("%w: %T", ErrNotCloneable, value)
+	}
+
+	clone := reflect.New(rv.Elem().Type())
+	clone.Elem().Set(rv.Elem())
+	switch elem := clone.Elem(); elem.Kind() {
+	case reflect.Struct:
+		for i := 0; i < elem.NumField(); i++ {
+			field := elem.Field(i)
+			if field.Kind() != reflect.Interface {
+				if !isPlain(field.Type()) {
+					return nil, fmt.Errorf("%w: %T", ErrNotCloneable, value)
+				}
+				continue
+			}
+
+			if !field.CanSet() {
+				return nil, fmt.Errorf("%w: %T", ErrNotCloneable, value)
+			} else if field.IsNil() {
+				continue
+			}
+
+			wrapped, err := cloneValue(field.Interface())
+			if err != nil {
+				return nil, err
+			}
+			field.Set(reflect.ValueOf(wrapped))
+		}
+	case reflect.Slice:
+		if !isPlain(elem.Type().Elem()) {
+			return nil, fmt.Errorf("%w: %T", ErrNotCloneable, value)
+		}
+		elem.Set(reflect.AppendSlice(reflect.MakeSlice(elem.Type(), 0, elem.Len()), elem))
+	default:
+		if !isPlain(elem.Type()) {
+			return nil, fmt.Errorf("%w: %T", ErrNotCloneable, value)
+		}
+	}
+	return clone.Interface(), nil
+}
+
+// assignValue sets value to the value of from, which is value or a
+// copy of value returned by cloneValue. Values wrapped by value are
+// assigned in turn, so that value keeps referring to them
+func assignValue(value, from interface{}) {
+	if vc, ok := value.(valueCloner); ok {
+		vc.assignValue(from.(flag.Value))
+		return
+	}
+
+	dst, src := reflect.ValueOf(value).Elem(), reflect.ValueOf(from).Elem()
+	if dst.Kind() != reflect.Struct {
+		dst.Set(src)
+		return
+	}
+
+	wrapped := make(map[int]interface{})
+	for i := 0; i < dst.NumField(); i++ {
+		if field := dst.Field(i); field.Kind() == reflect.Interface && !field.IsNil() {
+			wrapped[i] = field.Interface()
+		}
+	}
+
+	dst.Set(src)
+	for i, w := range wrapped {
+		dst.Field(i).Set(reflect.ValueOf(w))
+		assignValue(w, src.Field(i).Interface())
+	}
+}
+
+// isPlain reports whether values of type t can be copied without
+// sharing any memory
+func isPlain(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isPlain(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		return isPlain(t.Elem())
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+// clone returns a copy of args with copies of the argument values, so
+// that parsing with the copy leaves the values of args unchanged
+func (args *Arguments) clone() (*Arguments, error) {
+	clone := &Arguments{}
+	for _, arg := range args.args {
+		value, err := cloneValue(arg.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg.desc, err)
+		}
+
+		a := *arg
+		a.value = value
+		clone.args = append(clone.args, &a)
+	}
+	return clone, nil
+}
+
+func (cv *choiceValue) cloneValue() (flag.Value, error) {
+	value := *cv.p
+	return &choiceValue{p: &value, choices: cv.choices}, nil
+}
+
+func (cv *choiceValue) assignValue(from flag.Value) { *cv.p = *from.(*choiceValue).p }
+
+func (cv *credentialValue) cloneValue() (flag.Value, error) {
+	value := *cv.p
+	return &credentialValue{credentials: cv.credentials, p: &value}, nil
+}
+
+func (cv *credentialValue) assignValue(from flag.Value) { *cv.p = *from.(*credentialValue).p }
+
+func (tv *transformValue) cloneValue() (flag.Value, error) {
+	value, err := cloneValue(tv.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &transformValue{Value: value.(Value), arg: tv.arg}, nil
+}
+
+func (tv *transformValue) assignValue(from flag.Value) {
+	assignValue(tv.Value, from.(*transformValue).Value)
+}
+
+func (f *formatter) cloneValue() (flag.Value, error) {
+	clone := *f
+	return &clone, nil
+}
+
+func (f *formatter) assignValue(from flag.Value) { *f = *from.(*formatter) }
+
+// cloneValue returns a copy of the deprecated value that does not print
+// a warning when it is set
+func (dv *deprecatedValue) cloneValue() (flag.Value, error) {
+	clone := *dv
+	clone.cmd = nil
+	value, err := cloneValue(dv.Value)
+	if err != nil {
+		return nil, err
+	}
+	clone.Value = value.(flag.Value)
+
+	if dv.replacement != nil {
+		if value, err = cloneValue(dv.replacement); err != nil {
+			return nil, err
+		}
+		clone.replacement = value.(flag.Value)
+	}
+	return &clone, nil
+}
+
+func (dv *deprecatedValue) assignValue(from flag.Value) {
+	clone := from.(*deprecatedValue)
+	assignValue(dv.Value, clone.Value)
+	if dv.replacement != nil {
+		assignValue(dv.replacement, clone.replacement)
+	}
+}
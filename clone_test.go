@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+type funcValue func(string) error
+
+func (fv funcValue) String() string     { return "" }
+func (fv funcValue) Set(s string) error { return fv(s) }
+
+func TestCloneValue(t *testing.T) {
+	var b bool
+	var s string
+	var n int
+	called := false
+
+	tests := []struct {
+		desc     string
+		value    flag.Value
+		input    string
+		wantErr  error
+		original string
+	}{
+		{"func", funcValue(func(string) error { called = true; return nil }), "foo", ErrNotCloneable, ""},
+		{"bool", (*boolValue)(&b), "true", nil, "false"},
+		{"int", (*intValue)(&n), "3", nil, "0"},
+		{"secret", SecretString(&s), "foo", nil, ""},
+		{"env", EnvDefault((*intValue)(&n), "CLI_TEST_CLONE"), "3", nil, "0"},
+		{"choice", Choice(&s, "", "foo", "bar"), "foo", nil, ""},
+		{"transform", TransformValue((*stringValue)(&s), AbsPath), "foo", nil, ""},
+		{"transform func", TransformValue(funcValue(func(string) error { return nil }), AbsPath), "foo", ErrNotCloneable, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			clone, err := cloneValue(test.value)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			if err := clone.(flag.Value).Set(test.input); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if called {
+				t.Errorf("Wanted the original value not to be set")
+			}
+
+			if test.original != test.value.String() {
+				t.Errorf("Wanted original value %q got %q", test.original, test.value.String())
+			}
+
+			saved, _ := cloneValue(test.value)
+			assignValue(test.value, clone)
+			if want := clone.(flag.Value).String(); want != test.value.String() {
+				t.Errorf("Wanted assigned value %q got %q", want, test.value.String())
+			}
+
+			assignValue(test.value, saved)
+			if test.original != test.value.String() {
+				t.Errorf("Wanted restored value %q got %q", test.original, test.value.String())
+			}
+		})
+	}
+}
+
+func TestCloneArguments(t *testing.T) {
+	args := &Arguments{}
+	n := args.Int("<n>")
+	list := &intSlice{1}
+	args.VarSlice(list, "<list>")
+
+	clone, err := args.clone()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if err := clone.Parse([]string{"3", "4", "5"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if *n != 0 || !reflect.DeepEqual(*list, intSlice{1}) {
+		t.Errorf("Wanted the original arguments not to be set got %d %v", *n, *list)
+	}
+
+	if got := clone.args[1].value.(*intSlice); !reflect.DeepEqual(*got, intSlice{1, 4, 5}) {
+		t.Errorf("Wanted [1 4 5] got %v", *got)
+	}
+}
@@ -0,0 +1,65 @@
+// Command cli is a helper for applications built with the cli package.
+//
+// Usage:
+//
+//	cli new [-dir <dir>] [-commands <cmd1,cmd2>] <name>
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abates/cli"
+	"github.com/abates/cli/scaffold"
+)
+
+var version = "0.1.0"
+
+func main() {
+	app := cli.New("cli", cli.DescOption("Helper for applications built with github.com/abates/cli"))
+
+	var dir, commands string
+	newCmd := app.SubCommand("new", cli.DescOption("Generate main.go for a new application"), cli.FuncOption(func(name string) error {
+		return newApp(name, dir, commands)
+	}, "<name>"))
+	newCmd.Flags.StringVar(&dir, "dir", "", "directory to generate the application in (default <name>)")
+	newCmd.Flags.StringVar(&commands, "commands", "hello", "comma separated list of sample subcommands")
+
+	cli.AddBuiltins(app, cli.Builtins{Completion: true, Version: version})
+	app.Run(os.Args[1:])
+}
+
+func newApp(name, dir, commands string) error {
+	if dir == "" {
+		dir = name
+	}
+
+	config := scaffold.Config{Name: name}
+	for _, command := range strings.Split(commands, ",") {
+		if command = strings.TrimSpace(command); command != "" {
+			config.Commands = append(config.Commands, command)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(dir, "main.go")
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = scaffold.Generate(file, config)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		fmt.Printf("Created %s\n", filename)
+	}
+	return err
+}
@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 )
 
 type ErrorHandling int
 
 const (
-	ExitOnError     ErrorHandling = iota // Print usage and Call os.Exit(2).
+	ExitOnError     ErrorHandling = iota // Print usage and Call os.Exit(2) for user errors, os.Exit(1) otherwise.
 	ContinueOnError                      // Return a descriptive error.
 	PanicOnError                         // Call panic with a descriptive error.
 )
@@ -49,6 +53,11 @@ func (ind *indenter) Indentf(format string, a ...interface{}) {
 // then processing stops immediately
 type CommandFunc func(name string, args ...string) ([]string, error)
 
+// NotFoundFunc is called with the name and arguments of a subcommand
+// that could not be found. It can be used to implement fallbacks such
+// as running an external plugin
+type NotFoundFunc func(name string, args []string) ([]string, error)
+
 // Command represents a single cli command. The idea is that a cli app
 // is run such as:
 //    program cmd <flags>
@@ -60,12 +69,74 @@ type Command struct {
 	Name        string
 	Description string
 	UsageStr    string
+
+	// LongDescription is displayed below the usage line of the command.
+	// Paragraphs are separated by blank lines and are re-wrapped for
+	// display, while indented lines are displayed exactly as written
+	LongDescription string
+
+	// Examples are displayed in the usage of the command
+	Examples []Example
+
+	// Capabilities that are required to run the command, see
+	// RequiresOption and AuthorizerOption
+	Capabilities []string
+
 	Callback    CommandFunc
 	SubCommands []*Command
 	Flags       flag.FlagSet
 
+	// NotFoundHandler, when set, is called instead of returning
+	// ErrUnknownCommand when a subcommand can not be found
+	NotFoundHandler NotFoundFunc
+
 	errorHandling ErrorHandling
 	output        io.Writer
+	stdout        io.Writer
+	stdin         io.Reader
+	events        EventHandler
+	audit         *auditor
+	authorizer    Authorizer
+	rootMarkers   []string
+	chdir         string
+	dir           string
+	prevDir       string
+	notifier      *UpdateNotifier
+	theme         *Theme
+	helpHeader    HelpFunc
+	helpFooter    HelpFunc
+	helpArgs      bool
+	helpArgsFlag  bool
+	arguments     *Arguments
+	parseArgs     bool
+	alternatives  []*callback
+	result        interface{}
+	explain       bool
+	resolvePath   bool
+	trace         bool
+	history       *history
+	stats         *stats
+	factory       func() *Command
+	loadMu        sync.Mutex
+	layoutMu      sync.Mutex
+	usageLayout   *usageLayout
+	workspaces    []*TempWorkspace
+	pipe          *pipeStage
+	pipeSeparator string
+	sanitize      SanitizeMode
+	experimental  bool
+	formatter     *formatter
+	watch         time.Duration
+	sticky        *sticky
+	warnings      []error
+	flagErrorFunc FlagErrorFunc
+	argumentsFunc func() *Arguments
+	usageOnError  UsageVerbosity
+	path          string
+	lockName      string
+	noInput       bool
+	replaying     bool
+	inheritedFmt  *formatter
 }
 
 type Option func(*Command)
@@ -76,16 +147,118 @@ func DescOption(description string) Option {
 	return func(cmd *Command) { cmd.Description = description }
 }
 
+func LongDescOption(description string) Option {
+	return func(cmd *Command) { cmd.LongDescription = description }
+}
+
 func CallbackOption(callback CommandFunc) Option {
 	return func(cmd *Command) { cmd.Callback = callback }
 }
 
+// FuncOption sets the command callback to Callback(f, descriptions...).
+// Unlike CallbackOption, the positional arguments of f are known to the
+// command and are displayed in usage output when no usage string has
+// been set. If f returns a value other than an error, that value is
+// available from Result once the command has run
+func FuncOption(f interface{}, descriptions ...string) Option {
+	return func(cmd *Command) {
+		cb := newCallback(f, descriptions...)
+		cb.setResult = cmd.SetResult
+		cmd.Callback = cb.callback
+		cmd.arguments = &cb.arguments
+		cmd.parseArgs = false
+		cmd.alternatives = nil
+	}
+}
+
+// FuncNOption is the same as FuncOption except that it accepts argument
+// names rather than descriptions, see CallbackN. For instance
+// FuncNOption(f, "host", "port") displays "<host> <port>" in usage
+func FuncNOption(f interface{}, names ...string) Option {
+	return FuncOption(f, placeholders(names)...)
+}
+
+// ArgumentsOption declares the positional arguments of the command, see
+// SetArguments
+func ArgumentsOption(args *Arguments) Option {
+	return func(cmd *Command) { cmd.SetArguments(args) }
+}
+
+// SetArguments declares the positional arguments of the command. Before
+// the command's callback is run, its positional arguments are parsed
+// into args and the callback is given the arguments that remain. Like
+// the arguments of a callback set with FuncOption, declared arguments
+// are displayed in usage and are known to Schema, ParseOnly and Wizard
+func (cmd *Command) SetArguments(args *Arguments) {
+	cmd.arguments = args
+	cmd.parseArgs = args != nil
+	cmd.alternatives = nil
+}
+
+// ArgumentsFuncOption declares the positional arguments of the command
+// with fn, see SetArgumentsFunc
+func ArgumentsFuncOption(fn func() *Arguments) Option {
+	return func(cmd *Command) { cmd.SetArgumentsFunc(fn) }
+}
+
+// SetArgumentsFunc declares the positional arguments of the command
+// with the Arguments returned by fn. fn is called each time the command
+// runs, after its flags have been parsed and before the positional
+// arguments are, so the number and kind of arguments can depend on the
+// value of a flag. Until the command has run, usage displays the
+// arguments returned by fn for the current flag values
+func (cmd *Command) SetArgumentsFunc(fn func() *Arguments) {
+	cmd.argumentsFunc = fn
+}
+
+// finalizeArguments sets the arguments of the command from its
+// ArgumentsFunc, if it has one
+func (cmd *Command) finalizeArguments() {
+	if cmd.argumentsFunc != nil {
+		cmd.SetArguments(cmd.argumentsFunc())
+	}
+}
+
+// declaredArguments returns the positional arguments declared for the
+// command. Until the command has run, the arguments of an ArgumentsFunc
+// are those it returns for the current flag values
+func (cmd *Command) declaredArguments() *Arguments {
+	if cmd.arguments == nil && cmd.argumentsFunc != nil {
+		return cmd.argumentsFunc()
+	}
+	return cmd.arguments
+}
+
+func NotFoundOption(handler NotFoundFunc) Option {
+	return func(cmd *Command) { cmd.NotFoundHandler = handler }
+}
+
 func OutputOption(output io.Writer) Option { return func(cmd *Command) { cmd.SetOutput(output) } }
 
+func StdoutOption(stdout io.Writer) Option { return func(cmd *Command) { cmd.SetStdout(stdout) } }
+
 func ErrorHandlingOption(errorHandling ErrorHandling) Option {
 	return func(cmd *Command) { cmd.errorHandling = errorHandling }
 }
 
+// FlagErrorFunc transforms the error from parsing the flags of cmd,
+// for instance to add a hint or to translate the message
+type FlagErrorFunc func(cmd *Command, err error) error
+
+// SetFlagErrorFunc sets the function that transforms flag parse errors
+// of the command and its subcommands before they are handled. If fn
+// returns nil the original error is used. flag.ErrHelp is not passed
+// to fn
+func (cmd *Command) SetFlagErrorFunc(fn FlagErrorFunc) {
+	cmd.flagErrorFunc = fn
+}
+
+// FlagErrorFuncOption sets the command's FlagErrorFunc, see
+// SetFlagErrorFunc
+func FlagErrorFuncOption(fn FlagErrorFunc) Option {
+	return func(cmd *Command) { cmd.SetFlagErrorFunc(fn) }
+}
+
 // New will return a Command object that is initialized according
 // to the supplied command options
 func New(name string, options ...Option) *Command {
@@ -105,8 +278,7 @@ func New(name string, options ...Option) *Command {
 // SubCommand adds a subcommand to the current command hierarchy
 func (cmd *Command) SubCommand(name string, options ...Option) *Command {
 	subCommand := New(name)
-	subCommand.Flags.SetOutput(cmd.output)
-	subCommand.errorHandling = cmd.errorHandling
+	cmd.inherit(subCommand)
 	for _, option := range options {
 		option(subCommand)
 	}
@@ -114,17 +286,133 @@ func (cmd *Command) SubCommand(name string, options ...Option) *Command {
 	return subCommand
 }
 
+// inherit copies the settings that subcommands share with their parent
+func (cmd *Command) inherit(subCommand *Command) {
+	subCommand.Flags.SetOutput(cmd.output)
+	subCommand.output = cmd.output
+	subCommand.errorHandling = cmd.errorHandling
+	subCommand.stdout = cmd.stdout
+	subCommand.stdin = cmd.stdin
+	subCommand.events = cmd.events
+	subCommand.authorizer = cmd.authorizer
+	subCommand.theme = cmd.theme
+	subCommand.helpHeader = cmd.helpHeader
+	subCommand.helpFooter = cmd.helpFooter
+	subCommand.trace = cmd.trace
+	subCommand.sanitize = cmd.sanitize
+	subCommand.flagErrorFunc = cmd.flagErrorFunc
+	subCommand.noInput = cmd.noInput
+	subCommand.path = cmd.commandPath() + " " + subCommand.Name
+	if cmd.usageOnError != UsageFull {
+		UsageOnErrorOption(cmd.usageOnError)(subCommand)
+	}
+	if cmd.helpArgsFlag {
+		HelpArgsOption()(subCommand)
+	}
+}
+
 // SetOutput will set the io.Writer used for printing usage
 func (cmd *Command) SetOutput(writer io.Writer) {
 	cmd.output = writer
 }
 
+// Output returns the io.Writer used for printing usage. If the output
+// is a file that is not a terminal, ANSI escape sequences are removed
+// from everything written to it (see StripANSI)
+func (cmd *Command) Output() io.Writer {
+	output := cmd.output
+	if output == nil {
+		output = os.Stderr
+	}
+	output = plain(output)
+
+	if cmd.events != nil {
+		return &eventWriter{cmd: cmd, writer: output, stderr: true}
+	}
+	return output
+}
+
+// SetStdout will set the io.Writer used for regular (non usage and
+// non error) program output
+func (cmd *Command) SetStdout(writer io.Writer) {
+	cmd.stdout = writer
+}
+
+// Stdout returns the io.Writer used for regular program output. Unlike
+// Output, what is written to Stdout is never changed, so that binary or
+// passed through output is preserved
+func (cmd *Command) Stdout() io.Writer {
+	return cmd.stdoutWriter(false)
+}
+
+// stdoutWriter returns the command's Stdout, removing ANSI escape
+// sequences when strip is set and stdout is a file that is not a
+// terminal. It is used with strip set for styled messages
+func (cmd *Command) stdoutWriter(strip bool) io.Writer {
+	stdout := cmd.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	if strip {
+		stdout = plain(stdout)
+	}
+
+	if cmd.events != nil {
+		return &eventWriter{cmd: cmd, writer: stdout}
+	}
+	return stdout
+}
+
 func (cmd *Command) Usage() {
-	ind := &indenter{writer: cmd.output}
-	if ind.writer == nil {
-		ind.writer = os.Stderr
+	cmd.RenderUsage(cmd.Output())
+}
+
+// RenderUsage writes the usage of the command to w. Rendering does not
+// modify the command or its flags, so RenderUsage is safe to call
+// repeatedly and from multiple goroutines
+func (cmd *Command) RenderUsage(w io.Writer) {
+	ind := &indenter{writer: w}
+	if cmd.helpHeader != nil {
+		printHelpText(ind, cmd.helpHeader(cmd), true)
 	}
+
 	cmd.usage(ind)
+
+	if cmd.helpFooter != nil {
+		printHelpText(ind, cmd.helpFooter(cmd), false)
+	}
+}
+
+// synopsis returns the first line of the usage of the command
+func (cmd *Command) synopsis(numFlags int) string {
+	synopsis := "Usage: " + cmd.Name
+	if usageStr := cmd.usageStr(); usageStr != "" {
+		return synopsis + " " + usageStr
+	}
+
+	if numFlags > 0 {
+		synopsis += " [global options]"
+	}
+
+	if len(cmd.SubCommands) > 0 {
+		synopsis += " <command> [command options]"
+	}
+	return synopsis
+}
+
+// usageStr returns the UsageStr for the command or, if that is empty,
+// the usage of the command's positional arguments
+func (cmd *Command) usageStr() string {
+	arguments := cmd.declaredArguments()
+	if cmd.UsageStr == "" && len(cmd.alternatives) > 0 {
+		return alternativesUsage(cmd.alternatives)
+	} else if cmd.UsageStr == "" && arguments != nil {
+		builder := &strings.Builder{}
+		arguments.Usage(builder)
+		return builder.String()
+	}
+	return cmd.UsageStr
 }
 
 func (cmd *Command) usage(ind *indenter) {
@@ -133,75 +421,161 @@ func (cmd *Command) usage(ind *indenter) {
 	cmd.Flags.VisitAll(func(*flag.Flag) { numFlags++ })
 
 	if ind.count == 0 {
-		ind.Indentf("Usage: %s", cmd.Name)
-		if cmd.UsageStr != "" {
-			ind.Printf(" %s\n", cmd.UsageStr)
-		} else {
-			if numFlags > 0 {
-				ind.Print(" [global options]")
-			}
+		ind.Indentln(cmd.synopsis(numFlags))
 
-			if len(cmd.SubCommands) > 0 {
-				ind.Printf(" <command> [command options]\n")
-			} else {
-				ind.Println()
+		if cmd.LongDescription != "" {
+			ind.Println()
+			for _, line := range wrapText(cmd.LongDescription, usageWidth) {
+				ind.Println(line)
 			}
+			ind.Println()
 		}
 	}
-	builder := &strings.Builder{}
-	cmd.Flags.SetOutput(builder)
-	cmd.Flags.PrintDefaults()
-
-	str := builder.String()
+	str := flagDefaults(&cmd.Flags)
 	if len(str) > 0 {
 		for _, line := range strings.Split(str, "\n") {
 			ind.Indentln(line)
 		}
 	}
 
+	if ind.count == 0 && len(cmd.Examples) > 0 {
+		ind.Println("Examples:")
+		for _, ex := range cmd.Examples {
+			if ex.Description != "" {
+				ind.Printf("  # %s\n", ex.Description)
+			}
+			ind.Printf("  %s\n", strings.TrimSpace(cmd.Name+" "+ex.String()))
+		}
+		ind.Println()
+	}
+
 	if len(cmd.SubCommands) > 0 {
+		cmd.load()
+		layout := cmd.layout()
 		ind.Indentln("Commands:")
-		nameFmt := fmt.Sprintf("%%-%ds", subCommands(cmd.SubCommands).maxLen())
+		nameFmt := fmt.Sprintf("%%-%ds", layout.maxLen)
 		var prevCmd *Command
-		subCommands(cmd.SubCommands).sort()
-		for _, command := range cmd.SubCommands {
+		maxLen := layout.maxLen
+		descIndent := strings.Repeat(" ", maxLen)
+		descWidth := usageWidth - 2*ind.count - maxLen - 1
+		if descWidth < usageWidth/4 {
+			descWidth = usageWidth / 4
+		}
+		for _, command := range layout.sorted {
+			if command.hidden() {
+				continue
+			}
+
 			if prevCmd != nil && len(prevCmd.SubCommands) == 0 && len(command.SubCommands) > 0 {
 				ind.Println()
 			}
 
+			description := wrapText(command.Description, descWidth)
 			ind.Indentf(nameFmt, command.Name)
-			if command.UsageStr == "" {
-				if command.Description != "" {
-					ind.Printf(" %s\n", command.Description)
+			if usageStr := command.usageStr(); usageStr == "" {
+				if len(description) > 0 {
+					ind.Printf(" %s\n", description[0])
+					description = description[1:]
 				} else {
 					ind.Println()
 				}
 			} else {
-				ind.Printf(" %s\n", command.UsageStr)
-				if command.Description != "" {
-					ind.Indentf("%s %s\n", strings.Repeat(" ", subCommands(cmd.SubCommands).maxLen()), command.Description)
+				ind.Printf(" %s\n", usageStr)
+			}
+
+			for _, line := range description {
+				if line == "" {
+					ind.Println()
+				} else {
+					ind.Indentf("%s %s\n", descIndent, line)
 				}
 			}
 
-			command.usage(&indenter{writer: ind.writer, count: ind.count + subCommands(cmd.SubCommands).maxLen()})
+			command.usage(&indenter{writer: ind.writer, count: ind.count + maxLen})
 			prevCmd = command
 		}
 		ind.Println()
 	}
 }
 
+// envHinter is implemented by flag values that can be set from an
+// environment variable, see EnvDefault
+type envHinter interface {
+	Env() string
+}
+
+// flagDefaults renders the flags in aligned columns of name, type and
+// usage. The default value and environment variable of a flag, if any,
+// follow its usage
+func flagDefaults(flags *flag.FlagSet) string {
+	builder := &strings.Builder{}
+	tw := tabwriter.NewWriter(builder, 0, 4, 2, ' ', 0)
+	flags.VisitAll(func(f *flag.Flag) {
+		if isDeprecated(f) {
+			return
+		}
+
+		name, usage := flag.UnquoteUsage(f)
+		if !strings.Contains(f.Usage, "`") {
+			name, _ = typeName(f.Value, name)
+		}
+
+		if !isZeroValue(f) {
+			if reflect.TypeOf(f.Value).String() == "*flag.stringValue" {
+				usage += fmt.Sprintf(" (default %q)", f.DefValue)
+			} else {
+				usage += fmt.Sprintf(" (default %v)", f.DefValue)
+			}
+		}
+
+		if eh, ok := f.Value.(envHinter); ok {
+			usage += fmt.Sprintf(" [$%s]", eh.Env())
+		}
+
+		lines := strings.Split(strings.TrimSpace(usage), "\n")
+		fmt.Fprintf(tw, "  -%s\t%s\t%s\n", f.Name, name, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(tw, "\t\t%s\n", line)
+		}
+	})
+	tw.Flush()
+	return builder.String()
+}
+
+// isZeroValue determines whether the string represents the zero
+// value for a flag
+func isZeroValue(f *flag.Flag) (zero bool) {
+	typ := reflect.TypeOf(f.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+
+	// values that wrap other values can panic when they are zero
+	defer func() {
+		if recover() != nil {
+			zero = f.DefValue == "" || f.DefValue == "false" || f.DefValue == "0"
+		}
+	}()
+
+	if value, ok := z.Interface().(flag.Value); ok {
+		return f.DefValue == value.String()
+	}
+	return f.DefValue == "" || f.DefValue == "false" || f.DefValue == "0"
+}
+
 func (cmd *Command) handleErr(err error) error {
 	if err != nil {
 		if cmd.errorHandling == ExitOnError {
-			ind := &indenter{writer: cmd.output}
-			if cmd.output == nil {
-				ind.writer = os.Stderr
-			}
+			ind := &indenter{writer: cmd.Output()}
 			ind.Printf("%v\n", err)
 			if errors.Is(err, ErrUsage) {
-				cmd.usage(ind)
+				cmd.renderUsageOnError(ind.writer)
 			}
-			os.Exit(2)
+			RunAtExit()
+			osExit(exitCode(err))
 		} else if cmd.errorHandling == PanicOnError {
 			panic(err)
 		}
@@ -211,16 +585,67 @@ func (cmd *Command) handleErr(err error) error {
 
 // Run the command.
 func (cmd *Command) runCallback(args []string) ([]string, error) {
+	if err := cmd.enterDir(); err != nil {
+		return args, err
+	}
+
 	if cmd.Callback == nil {
 		return args, ErrNoCommandFunc
 	}
+
+	if cmd.lockName != "" {
+		unlock, err := Lock(cmd.lockName)
+		if err != nil {
+			return args, err
+		}
+		defer unlock()
+	}
+
+	cmd.finalizeArguments()
+	if Strict && cmd.arguments != nil && len(cmd.SubCommands) == 0 {
+		if err := cmd.arguments.checkCount(args); err != nil {
+			return args, err
+		}
+	}
+
+	if cmd.parseArgs {
+		if err := cmd.arguments.Parse(args); err != nil {
+			return args, err
+		}
+		args = cmd.arguments.Args()
+	}
+
+	if cmd.watch > 0 {
+		return cmd.watchCallback(args)
+	}
 	return cmd.Callback(cmd.Name, args...)
 }
 
+// SetResult sets the result of running the command. It is intended to
+// be called from a callback so that the program embedding the command
+// can retrieve a value with Result after Run returns
+func (cmd *Command) SetResult(result interface{}) {
+	cmd.result = result
+}
+
+// Result returns the result set by the most recent Run of the command.
+// If a subcommand was run and it set a result, then the subcommand's
+// result is returned
+func (cmd *Command) Result() interface{} {
+	return cmd.result
+}
+
 func (cmd *Command) Lookup(name string) (subcmd *Command, found bool) {
 	subcmd = subCommands(cmd.SubCommands).get(name)
+	if subcmd != nil && subcmd.hidden() {
+		subcmd = nil
+	}
+
 	if subcmd != nil {
 		found = true
+		if subcmd.factory != nil {
+			subcmd = cmd.loadOne(subcmd)
+		}
 	}
 	return
 }
@@ -234,10 +659,17 @@ func (cmd *Command) runSubcommand(args []string) ([]string, error) {
 			subCmdName := args[0]
 			subCmdArgs := args[1:]
 			subCmd, found := cmd.Lookup(subCmdName)
-			if !found {
+			if !found && cmd.NotFoundHandler != nil {
+				args, err = cmd.NotFoundHandler(subCmdName, subCmdArgs)
+			} else if !found {
 				err = fmt.Errorf("%w %q", ErrUnknownCommand, subCmdName)
 			} else {
+				subCmd.inheritedFmt = cmd.resultFormatter()
 				args, err = subCmd.Run(subCmdArgs)
+				if subCmd.result != nil {
+					cmd.result = subCmd.result
+				}
+				cmd.warnings = append(cmd.warnings, subCmd.warnings...)
 			}
 		}
 	} else {
@@ -247,15 +679,137 @@ func (cmd *Command) runSubcommand(args []string) ([]string, error) {
 }
 
 func (cmd *Command) Run(args []string) ([]string, error) {
-	err := cmd.Flags.Parse(args)
-	if err == nil {
+	defer recoverAtExit()
+	if cmd.pipeSeparator != "" && cmd.pipe == nil {
+		if stages := splitPipeline(args, cmd.pipeSeparator); len(stages) > 1 {
+			return cmd.RunPipeline(stages...)
+		}
+	}
+
+	cmd.result = nil
+	cmd.warnings = nil
+	if cmd.audit != nil && !cmd.replaying {
+		if err := cmd.audit.record(cmd, args); err != nil {
+			return args, cmd.handleErr(err)
+		}
+	}
+
+	if err := cmd.chdirRoot(); err != nil {
+		return args, cmd.handleErr(err)
+	}
+
+	var update <-chan updateCheck
+	if cmd.notifier != nil && !Quiet {
+		update = cmd.notifier.start()
+	}
+
+	args, err := cmd.sanitizeArgs(args)
+	if err != nil {
+		return args, cmd.handleErr(err)
+	}
+
+	if cmd.resolvePath {
+		args = cmd.resolveArgs(args)
+	}
+
+	if cmd.tracing() {
+		cmd.printTrace(args)
+	}
+
+	if cmd.sticky != nil {
+		if serr := cmd.sticky.apply(cmd); serr != nil {
+			cmd.Warnf("sticky flags: %v", serr)
+		}
+	}
+
+	input := args
+	err = cmd.parseFlags(args)
+	if err != nil {
+		if cmd.flagErrorFunc != nil && !errors.Is(err, flag.ErrHelp) {
+			if ferr := cmd.flagErrorFunc(cmd, err); ferr != nil {
+				err = ferr
+			}
+		}
+		cmd.printFlagError(err)
+		err = &UserError{err}
+	} else if cmd.explain {
+		return cmd.Flags.Args(), cmd.handleErr(classify(cmd.Explain(cmd.Stdout(), input)))
+	} else if cmd.helpArgs {
+		return cmd.Flags.Args(), cmd.handleErr(cmd.helpArgsCommand(cmd.Flags.Args()).HelpArgs(cmd.Stdout()))
+	} else if err = cmd.checkRun(); err == nil {
 		args = cmd.Flags.Args()
+		cmd.emit(Event{Type: EventStarted, Args: args})
 		args, err = cmd.runCallback(args)
+		err = cmd.warn(err)
 
 		if len(cmd.SubCommands) > 0 && (err == nil || errors.Is(err, ErrNoCommandFunc)) {
 			args, err = cmd.runSubcommand(args)
 		}
 	}
 
+	cmd.cleanupWorkspaces()
+	if derr := cmd.leaveDir(); err == nil {
+		err = derr
+	}
+
+	if err == nil && cmd.formatter != nil && cmd.result != nil {
+		err = cmd.formatter.write(cmd.Stdout(), cmd.result)
+	}
+
+	err = classify(err)
+	if err == nil && cmd.sticky != nil && !cmd.replaying {
+		if serr := cmd.sticky.save(cmd); serr != nil {
+			cmd.Warnf("sticky flags: %v", serr)
+		}
+	}
+
+	if err == nil && cmd.history != nil && !cmd.replaying {
+		if herr := cmd.history.record(cmd, input); herr != nil {
+			cmd.Warnf("history: %v", herr)
+		}
+	}
+
+	if err == nil && cmd.stats != nil && !cmd.replaying {
+		if serr := cmd.stats.record(cmd, input); serr != nil {
+			cmd.Warnf("stats: %v", serr)
+		}
+	}
+	cmd.emit(Event{Type: EventFinished, Err: err})
+	if update != nil {
+		if notice := cmd.notifier.notice(update); notice != "" {
+			fmt.Fprintln(cmd.Output(), notice)
+		}
+	}
 	return args, cmd.handleErr(err)
 }
+
+// RunWithErrorHandling runs the command the same as Run, except that
+// the error handling of the command and all of its subcommands is
+// errorHandling for this run only. This allows a command hierarchy that
+// exits on errors to be dispatched repeatedly, for instance from an
+// interactive loop, without a failing command ending the program
+func (cmd *Command) RunWithErrorHandling(errorHandling ErrorHandling, args []string) ([]string, error) {
+	restore := cmd.overrideErrorHandling(errorHandling)
+	defer restore()
+	return cmd.Run(args)
+}
+
+// overrideErrorHandling sets the error handling of cmd and all of its
+// subcommands. The returned function restores the previous settings
+func (cmd *Command) overrideErrorHandling(errorHandling ErrorHandling) (restore func()) {
+	prev := cmd.errorHandling
+	cmd.errorHandling = errorHandling
+
+	cmd.load()
+	restores := []func(){}
+	for _, subCmd := range cmd.SubCommands {
+		restores = append(restores, subCmd.overrideErrorHandling(errorHandling))
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+		cmd.errorHandling = prev
+	}
+}
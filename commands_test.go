@@ -2,6 +2,8 @@ package cli
 
 import (
 	"errors"
+	"flag"
+	"fmt"
 	"os"
 	"reflect"
 	"strings"
@@ -20,6 +22,7 @@ func TestOptions(t *testing.T) {
 		{"DescOption", DescOption("useless description"), &Command{Description: "useless description", output: os.Stderr}},
 		{"CallbackOption", CallbackOption(cb), &Command{Callback: cb, output: os.Stderr}},
 		{"OutputOption", OutputOption(os.Stdout), &Command{output: os.Stdout}},
+		{"StdoutOption", StdoutOption(os.Stderr), &Command{output: os.Stderr, stdout: os.Stderr}},
 		{"ErrorHandlingOption", ErrorHandlingOption(PanicOnError), &Command{errorHandling: PanicOnError, output: os.Stderr}},
 	}
 
@@ -48,10 +51,14 @@ func TestCommandUsage(t *testing.T) {
 	}{
 		{"usage str", func(cmd *Command) { cmd.UsageStr = "foobar" }, "Usage: usage str foobar\n"},
 		{"no flags", func(*Command) {}, "Usage: no flags\n"},
-		{"one flag", func(cmd *Command) { cmd.Flags.Var(&testValue{}, "foo", "bar") }, "Usage: one flag [global options]\n  -foo value\n    \tbar\n\n"},
+		{"one flag", func(cmd *Command) { cmd.Flags.Var(&testValue{}, "foo", "bar") }, "Usage: one flag [global options]\n  -foo  value  bar\n\n"},
 		{"subcommand", func(cmd *Command) { cmd.SubCommand("foo") }, "Usage: subcommand <command> [command options]\nCommands:\nfoo\n\n"},
 		{"subcommand (description)", func(cmd *Command) { cmd.SubCommand("foo", DescOption("bar")) }, "Usage: subcommand (description) <command> [command options]\nCommands:\nfoo bar\n\n"},
 		{"subcommand (usage)", func(cmd *Command) { cmd.SubCommand("foo", UsageOption("bar")) }, "Usage: subcommand (usage) <command> [command options]\nCommands:\nfoo bar\n\n"},
+		{"arguments", func(cmd *Command) { cmd.SubCommand("foo", FuncOption(func(int, string) {})) }, "Usage: arguments <command> [command options]\nCommands:\nfoo <int> <string>\n\n"},
+		{"long description", func(cmd *Command) { cmd.LongDescription = "foo\nbar\n\n  code" }, "Usage: long description\n\nfoo bar\n\n  code\n\n"},
+		{"subcommand (multi-paragraph description)", func(cmd *Command) { cmd.SubCommand("foo", DescOption("bar\n\nbaz")) }, "Usage: subcommand (multi-paragraph description) <command> [command options]\nCommands:\nfoo bar\n\n    baz\n\n"},
+		{"subcommand (wrapped description)", func(cmd *Command) { cmd.SubCommand("foo", DescOption(strings.Repeat("bar ", 20))) }, "Usage: subcommand (wrapped description) <command> [command options]\nCommands:\nfoo " + strings.TrimSpace(strings.Repeat("bar ", 19)) + "\n    bar\n\n"},
 		{"subcommand (usage, description)", func(cmd *Command) { cmd.SubCommand("foo", UsageOption("bar"), DescOption("foobar")) }, "Usage: subcommand (usage, description) <command> [command options]\nCommands:\nfoo bar\n    foobar\n\n"},
 	}
 
@@ -68,6 +75,51 @@ func TestCommandUsage(t *testing.T) {
 	}
 }
 
+func TestRenderUsage(t *testing.T) {
+	output := &strings.Builder{}
+	cmd := New("test", ErrorHandlingOption(ContinueOnError))
+	cmd.Flags.SetOutput(output)
+	cmd.Flags.String("s", "default", "string flag")
+	cmd.Flags.Int("number", 0, "int flag")
+	cmd.Flags.Bool("b", true, "a `boolean`\nflag")
+	cmd.SubCommand("foo")
+	cmd.SubCommand("bar")
+
+	want := &strings.Builder{}
+	wantDefaults := "  -b       boolean  a boolean\n                    flag (default true)\n  -number  int      int flag\n  -s       string   string flag (default \"default\")\n"
+	if got := flagDefaults(&cmd.Flags); wantDefaults != got {
+		t.Errorf("Wanted defaults %q got %q", wantDefaults, got)
+	}
+
+	cmd.usage(&indenter{writer: want})
+	done := make(chan string)
+	for i := 0; i < 4; i++ {
+		go func() {
+			builder := &strings.Builder{}
+			cmd.RenderUsage(builder)
+			done <- builder.String()
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		if got := <-done; want.String() != got {
+			t.Errorf("Wanted usage %q got %q", want.String(), got)
+		}
+	}
+
+	if cmd.Flags.Output() != output {
+		t.Errorf("Expected flag output to be unchanged")
+	}
+
+	if cmd.SubCommands[0].Name != "foo" {
+		t.Errorf("Expected subcommands to remain in insertion order")
+	}
+
+	if output.Len() != 0 {
+		t.Errorf("Expected nothing written to flag output got %q", output.String())
+	}
+}
+
 func TestCommandRun(t *testing.T) {
 	runErr := errors.New("Run Error!")
 
@@ -95,9 +147,251 @@ func TestCommandRun(t *testing.T) {
 			cmd := New(test.name, ErrorHandlingOption(ContinueOnError))
 			test.prepare(cmd)
 			_, gotErr := cmd.Run(test.args)
-			if test.wantErr != gotErr {
+			if !errors.Is(gotErr, test.wantErr) {
 				t.Errorf("Wanted error %v got %v", test.wantErr, gotErr)
 			}
 		})
 	}
 }
+
+func TestCommandResult(t *testing.T) {
+	tests := []struct {
+		desc  string
+		setup func(*Command)
+		args  []string
+		want  interface{}
+	}{
+		{"no result", func(cmd *Command) { cmd.Callback = Callback(func() {}) }, nil, nil},
+		{"error only", func(cmd *Command) { cmd.SetResult("stale"); cmd.Callback = Callback(func() error { return nil }) }, nil, nil},
+		{"func result", func(cmd *Command) { FuncOption(func(i int) (int, error) { return i * 2, nil })(cmd) }, []string{"21"}, 42},
+		{"func result without error", func(cmd *Command) { FuncOption(func(s string) string { return s })(cmd) }, []string{"foo"}, "foo"},
+		{"SetResult", func(cmd *Command) {
+			cmd.Callback = func(string, ...string) ([]string, error) { cmd.SetResult("bar"); return nil, nil }
+		}, nil, "bar"},
+		{"subcommand result", func(cmd *Command) {
+			cmd.SubCommand("foo", FuncOption(func() (string, error) { return "foo", nil }))
+		}, []string{"foo"}, "foo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New(test.desc, ErrorHandlingOption(ContinueOnError))
+			test.setup(cmd)
+			_, err := cmd.Run(test.args)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := cmd.Result(); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("Wanted result %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestNotFoundHandler(t *testing.T) {
+	var gotName string
+	var gotArgs []string
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), NotFoundOption(func(name string, args []string) ([]string, error) {
+		gotName, gotArgs = name, args
+		return nil, nil
+	}))
+	cmd.SubCommand("foo", CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+
+	if _, err := cmd.Run([]string{"bar", "a", "b"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if gotName != "bar" || !reflect.DeepEqual(gotArgs, []string{"a", "b"}) {
+		t.Errorf("Wanted handler to be called with bar [a b] got %s %v", gotName, gotArgs)
+	}
+
+	pi, err := cmd.ParseOnly([]string{"bar", "a"})
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	} else if !reflect.DeepEqual(pi.Args, []string{"bar", "a"}) {
+		t.Errorf("Wanted args [bar a] got %v", pi.Args)
+	}
+}
+
+func TestFlagErrorFunc(t *testing.T) {
+	errHint := errors.New("did you mean -verbose?")
+	tests := []struct {
+		desc    string
+		fn      FlagErrorFunc
+		args    []string
+		wantErr error
+	}{
+		{"hint", func(cmd *Command, err error) error { return fmt.Errorf("%v: %w", err, errHint) }, []string{"sub", "-verbos"}, errHint},
+		{"nil keeps error", func(cmd *Command, err error) error { return nil }, []string{"sub", "-verbos"}, nil},
+		{"help not passed", func(cmd *Command, err error) error { return errHint }, []string{"sub", "-help"}, flag.ErrHelp},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}), FlagErrorFuncOption(test.fn))
+			sub := cmd.SubCommand("sub", CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+			sub.Flags.Bool("verbose", false, "")
+
+			_, err := cmd.Run(test.args)
+			var ue *UserError
+			if !errors.As(err, &ue) {
+				t.Errorf("Wanted a UserError got %v", err)
+			}
+
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			} else if test.wantErr == nil && !strings.Contains(fmt.Sprint(err), "flag provided but not defined") {
+				t.Errorf("Wanted the original error got %v", err)
+			}
+		})
+	}
+}
+
+func TestArgumentsOption(t *testing.T) {
+	tests := []struct {
+		desc      string
+		args      []string
+		wantName  string
+		wantCount int
+		wantRest  []string
+		wantErr   error
+	}{
+		{"parsed", []string{"scale", "web", "3"}, "web", 3, []string{}, nil},
+		{"remaining", []string{"scale", "web", "3", "extra"}, "web", 3, []string{"extra"}, nil},
+		{"missing", []string{"scale", "web"}, "", 0, nil, ErrUsage},
+		{"invalid", []string{"scale", "web", "three"}, "", 0, nil, ErrParse},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := &Arguments{}
+			name := args.String("<service>")
+			count := args.Int("<count>")
+
+			var rest []string
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}))
+			scale := cmd.SubCommand("scale", ArgumentsOption(args), CallbackOption(func(name string, args ...string) ([]string, error) {
+				rest = args
+				return nil, nil
+			}))
+
+			if got := scale.usageStr(); got != "<service> <count>" {
+				t.Errorf("Wanted usage %q got %q", "<service> <count>", got)
+			}
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			if *name != test.wantName || *count != test.wantCount {
+				t.Errorf("Wanted %q %d got %q %d", test.wantName, test.wantCount, *name, *count)
+			}
+
+			if !reflect.DeepEqual(test.wantRest, rest) {
+				t.Errorf("Wanted remaining %v got %v", test.wantRest, rest)
+			}
+		})
+	}
+}
+
+func TestArgumentsFuncOption(t *testing.T) {
+	tests := []struct {
+		desc      string
+		args      []string
+		wantUsage string
+		wantPairs []string
+		wantErr   error
+	}{
+		{"default", []string{"set", "a", "1"}, "<key> <value>", []string{"a=1"}, nil},
+		{"pairs", []string{"set", "-pairs", "2", "a", "1", "b", "2"}, "<key> <value> <key> <value>", []string{"a=1", "b=2"}, nil},
+		{"too few", []string{"set", "-pairs", "2", "a", "1"}, "<key> <value> <key> <value>", nil, ErrUsage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var keys, values []*string
+			var pairs []string
+
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}))
+			set := cmd.SubCommand("set", CallbackOption(func(name string, args ...string) ([]string, error) {
+				for i := range keys {
+					pairs = append(pairs, *keys[i]+"="+*values[i])
+				}
+				return args, nil
+			}))
+			n := set.Flags.Int("pairs", 1, "number of key value pairs")
+			ArgumentsFuncOption(func() *Arguments {
+				args := &Arguments{}
+				keys, values = nil, nil
+				for i := 0; i < *n; i++ {
+					keys = append(keys, args.String("<key>"))
+					values = append(values, args.String("<value>"))
+				}
+				return args
+			})(set)
+
+			if got := set.usageStr(); got != "<key> <value>" {
+				t.Errorf("Wanted usage %q before running got %q", "<key> <value>", got)
+			}
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got := set.usageStr(); test.wantUsage != got {
+				t.Errorf("Wanted usage %q got %q", test.wantUsage, got)
+			}
+
+			if !reflect.DeepEqual(test.wantPairs, pairs) {
+				t.Errorf("Wanted %v got %v", test.wantPairs, pairs)
+			}
+		})
+	}
+}
+
+func TestRunWithErrorHandling(t *testing.T) {
+	exited := false
+	osExit = func(int) { exited = true }
+	defer func() { osExit = os.Exit }()
+
+	cmd := New("app", OutputOption(&strings.Builder{}))
+	cmd.SubCommand("fail", FuncOption(func() error { return errors.New("failed") }))
+
+	_, err := cmd.RunWithErrorHandling(ContinueOnError, []string{"fail"})
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("Wanted error %q got %v", "failed", err)
+	}
+
+	if exited {
+		t.Errorf("Wanted the command not to exit")
+	}
+
+	if cmd.errorHandling != ExitOnError || cmd.SubCommands[0].errorHandling != ExitOnError {
+		t.Errorf("Wanted error handling to be restored")
+	}
+
+	cmd.Run([]string{"fail"})
+	if !exited {
+		t.Errorf("Wanted the command to exit")
+	}
+}
+
+func TestFlagErrorFuncOutput(t *testing.T) {
+	output := &strings.Builder{}
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), FlagErrorFuncOption(func(cmd *Command, err error) error {
+		return errors.New("-n must be a number")
+	}))
+	cmd.Flags.SetOutput(output)
+	cmd.Flags.Int("n", 0, "count")
+
+	cmd.Run([]string{"-n", "x"})
+	want := "-n must be a number\nUsage:\n  -n int\n    \tcount\n"
+	if output.String() != want {
+		t.Errorf("Wanted %q got %q", want, output.String())
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var funcNameRe = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// WriteBashCompletion writes a bash completion script for the command
+// hierarchy to w. The script completes subcommand names and flags
+func (cmd *Command) WriteBashCompletion(w io.Writer) error {
+	funcName := fmt.Sprintf("_%s_completion", funcNameRe.ReplaceAllString(cmd.Name, "_"))
+	paths := []string{}
+	cases := &strings.Builder{}
+	cmd.bashCases(cmd.Name, &paths, cases)
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "# bash completion for %s\n", cmd.Name)
+	fmt.Fprintf(builder, "%s() {\n", funcName)
+	fmt.Fprintf(builder, "\tlocal cur path i\n")
+	fmt.Fprintf(builder, "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(builder, "\tpath=%q\n", cmd.Name)
+	fmt.Fprintf(builder, "\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(builder, "\t\tcase \"$path ${COMP_WORDS[i]}\" in\n")
+	if len(paths) > 0 {
+		fmt.Fprintf(builder, "\t\t%s) path=\"$path ${COMP_WORDS[i]}\" ;;\n", strings.Join(paths, "|"))
+	}
+	fmt.Fprintf(builder, "\t\tesac\n")
+	fmt.Fprintf(builder, "\tdone\n")
+	fmt.Fprintf(builder, "\tcase \"$path\" in\n")
+	builder.WriteString(cases.String())
+	fmt.Fprintf(builder, "\tesac\n")
+	fmt.Fprintf(builder, "}\n")
+	fmt.Fprintf(builder, "complete -F %s %s\n", funcName, cmd.Name)
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func (cmd *Command) bashCases(path string, paths *[]string, cases *strings.Builder) {
+	words := []string{}
+	cmd.load()
+	subCommands(cmd.SubCommands).sort()
+	for _, subCmd := range cmd.SubCommands {
+		if !subCmd.hidden() {
+			words = append(words, subCmd.Name)
+		}
+	}
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if !isDeprecated(f) {
+			words = append(words, "-"+f.Name)
+		}
+	})
+	fmt.Fprintf(cases, "\t%q) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", path, strings.Join(words, " "))
+
+	for _, subCmd := range cmd.SubCommands {
+		if subCmd.hidden() {
+			continue
+		}
+
+		subPath := path + " " + subCmd.Name
+		*paths = append(*paths, fmt.Sprintf("%q", subPath))
+		subCmd.bashCases(subPath, paths, cases)
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteBashCompletion(t *testing.T) {
+	root := New("my-app")
+	root.Flags.Bool("v", false, "")
+	foo := root.SubCommand("foo")
+	foo.Flags.Int("n", 0, "")
+	foo.SubCommand("bar")
+
+	builder := &strings.Builder{}
+	if err := root.WriteBashCompletion(builder); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := builder.String()
+
+	for _, want := range []string{
+		"_my_app_completion() {\n",
+		"\t\t\"my-app foo\"|\"my-app foo bar\") path=\"$path ${COMP_WORDS[i]}\" ;;\n",
+		"\t\"my-app\") COMPREPLY=($(compgen -W \"foo -v\" -- \"$cur\")) ;;\n",
+		"\t\"my-app foo\") COMPREPLY=($(compgen -W \"bar -n\" -- \"$cur\")) ;;\n",
+		"complete -F _my_app_completion my-app\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wanted completion to contain %q got %q", want, got)
+		}
+	}
+}
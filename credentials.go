@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// keyring is a secret store
+type keyring interface {
+	Get(service, name string) (string, error)
+	Set(service, name, secret string) error
+	Delete(service, name string) error
+}
+
+// Credentials stores secrets, such as API tokens, for an application.
+// Secrets are kept in the operating system's keychain when one is
+// available (the security tool on macOS and secret-tool on Linux),
+// otherwise they are kept in an encrypted file
+type Credentials struct {
+	// Service identifies the application, usually the application name
+	Service string
+
+	// File is the path of the encrypted file used when no system
+	// keychain is available. It defaults to "credentials" in the Data
+	// directory returned by Paths(Service)
+	File string
+
+	// Passphrase returns the passphrase used to encrypt File
+	Passphrase func() ([]byte, error)
+
+	keyring keyring
+}
+
+func (c *Credentials) store() keyring {
+	if c.keyring == nil {
+		c.keyring = systemKeyring()
+		if c.keyring == nil {
+			c.keyring = &fileKeyring{credentials: c}
+		}
+	}
+	return c.keyring
+}
+
+// Get returns the named secret or ErrCredentialNotFound
+func (c *Credentials) Get(name string) (string, error) { return c.store().Get(c.Service, name) }
+
+// Set stores the named secret
+func (c *Credentials) Set(name, secret string) error { return c.store().Set(c.Service, name, secret) }
+
+// Delete removes the named secret
+func (c *Credentials) Delete(name string) error { return c.store().Delete(c.Service, name) }
+
+type credentialValue struct {
+	credentials *Credentials
+	p           *string
+}
+
+func (cv *credentialValue) String() string {
+	if cv.p == nil {
+		return ""
+	}
+	return *cv.p
+}
+
+func (cv *credentialValue) Set(s string) (err error) {
+	if strings.HasPrefix(s, "keyring:") {
+		s, err = cv.credentials.Get(s[len("keyring:"):])
+	}
+
+	if err == nil {
+		*cv.p = s
+	}
+	return err
+}
+
+// CredentialValue returns a secret Value (see Secret) that sets p. If
+// the input starts with "keyring:" then the rest of the input is the
+// name of a secret that is looked up in credentials
+func CredentialValue(credentials *Credentials, p *string) Value {
+	return Secret(&credentialValue{credentials: credentials, p: p})
+}
+
+func systemKeyring() keyring {
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := exec.LookPath("security"); err == nil {
+			return &macKeyring{path}
+		}
+	case "linux", "freebsd", "openbsd":
+		if path, err := exec.LookPath("secret-tool"); err == nil {
+			return &secretToolKeyring{path}
+		}
+	}
+	return nil
+}
+
+// runKeyringTool runs a keychain tool, returning ErrCredentialNotFound
+// if the tool exits unsuccessfully without any error output
+func runKeyringTool(stdin string, path string, args ...string) (string, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && strings.TrimSpace(stderr.String()) == "" {
+			err = ErrCredentialNotFound
+		} else if ok {
+			err = fmt.Errorf("%s: %s", path, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return strings.TrimSuffix(string(out), "\n"), err
+}
+
+type macKeyring struct {
+	path string
+}
+
+func (mk *macKeyring) Get(service, name string) (string, error) {
+	secret, err := runKeyringTool("", mk.path, "find-generic-password", "-s", service, "-a", name, "-w")
+	if err != nil && strings.Contains(err.Error(), "could not be found") {
+		err = ErrCredentialNotFound
+	}
+	return secret, err
+}
+
+func (mk *macKeyring) Set(service, name, secret string) error {
+	// -w without a value must be the last argument, security then
+	// prompts for the secret, and for confirmation, on stdin so the
+	// secret never appears in the process list
+	stdin := secret + "\n" + secret + "\n"
+	_, err := runKeyringTool(stdin, mk.path, "add-generic-password", "-U", "-s", service, "-a", name, "-w")
+	return err
+}
+
+func (mk *macKeyring) Delete(service, name string) error {
+	_, err := runKeyringTool("", mk.path, "delete-generic-password", "-s", service, "-a", name)
+	return err
+}
+
+type secretToolKeyring struct {
+	path string
+}
+
+func (sk *secretToolKeyring) Get(service, name string) (string, error) {
+	return runKeyringTool("", sk.path, "lookup", "service", service, "account", name)
+}
+
+func (sk *secretToolKeyring) Set(service, name, secret string) error {
+	_, err := runKeyringTool(secret, sk.path, "store", "--label", service+" "+name, "service", service, "account", name)
+	return err
+}
+
+func (sk *secretToolKeyring) Delete(service, name string) error {
+	_, err := runKeyringTool("", sk.path, "clear", "service", service, "account", name)
+	return err
+}
+
+const (
+	saltSize         = 16
+	keySize          = 32
+	pbkdf2Iterations = 100000
+)
+
+// fileKeyring stores secrets in a file encrypted with AES-GCM using a
+// key derived from the passphrase with PBKDF2-HMAC-SHA256. The file
+// consists of the salt, the nonce and then the encrypted secrets
+type fileKeyring struct {
+	credentials *Credentials
+}
+
+// file returns the path of the credentials file
+func (fk *fileKeyring) file() string {
+	if fk.credentials.File != "" {
+		return fk.credentials.File
+	}
+
+	if paths, err := Paths(fk.credentials.Service); err == nil {
+		return filepath.Join(paths.Data, "credentials")
+	}
+	return ""
+}
+
+func (fk *fileKeyring) aead(salt []byte) (cipher.AEAD, error) {
+	if fk.credentials.Passphrase == nil || fk.file() == "" {
+		return nil, ErrNoKeyring
+	}
+
+	passphrase, err := fk.credentials.Passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(pbkdf2(passphrase, salt, pbkdf2Iterations, keySize))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// load returns the secrets for all services
+func (fk *fileKeyring) load() (map[string]map[string]string, error) {
+	secrets := make(map[string]map[string]string)
+	buf, err := ioutil.ReadFile(fk.file())
+	if os.IsNotExist(err) {
+		return secrets, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(buf) < saltSize {
+		return nil, ErrCredentialsCorrupt
+	}
+
+	aead, err := fk.aead(buf[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	buf = buf[saltSize:]
+	if len(buf) < aead.NonceSize() {
+		return nil, ErrCredentialsCorrupt
+	}
+
+	plaintext, err := aead.Open(nil, buf[:aead.NonceSize()], buf[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrCredentialsCorrupt
+	}
+	return secrets, json.Unmarshal(plaintext, &secrets)
+}
+
+func (fk *fileKeyring) save(secrets map[string]map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+
+	aead, err := fk.aead(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	buf := append(append(salt, nonce...), aead.Seal(nil, nonce, plaintext, nil)...)
+	if err := MkdirAll(filepath.Dir(fk.file()), 0700); err != nil {
+		return err
+	}
+	return WriteFile(fk.file(), buf, 0600)
+}
+
+func (fk *fileKeyring) Get(service, name string) (string, error) {
+	secrets, err := fk.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, found := secrets[service][name]
+	if !found {
+		return "", ErrCredentialNotFound
+	}
+	return secret, nil
+}
+
+func (fk *fileKeyring) Set(service, name, secret string) error {
+	secrets, err := fk.load()
+	if err == nil {
+		if secrets[service] == nil {
+			secrets[service] = make(map[string]string)
+		}
+		secrets[service][name] = secret
+		err = fk.save(secrets)
+	}
+	return err
+}
+
+func (fk *fileKeyring) Delete(service, name string) error {
+	secrets, err := fk.load()
+	if err == nil {
+		if _, found := secrets[service][name]; !found {
+			return ErrCredentialNotFound
+		}
+		delete(secrets[service], name)
+		err = fk.save(secrets)
+	}
+	return err
+}
+
+// pbkdf2 derives a key from password and salt using PBKDF2 (RFC 8018)
+// with HMAC-SHA256
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, numBlocks*hashLen)
+	buf := make([]byte, 4)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
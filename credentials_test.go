@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// test vector from RFC 7914 section 11
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	got := hex.EncodeToString(pbkdf2([]byte("passwd"), []byte("salt"), 1, 64))
+	if want != got {
+		t.Errorf("Wanted %s got %s", want, got)
+	}
+}
+
+func testCredentials(t *testing.T, passphrase string) (*Credentials, func()) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	c := &Credentials{
+		Service:    "app",
+		File:       filepath.Join(dir, "credentials"),
+		Passphrase: func() ([]byte, error) { return []byte(passphrase), nil },
+	}
+	c.keyring = &fileKeyring{credentials: c}
+	return c, func() { os.RemoveAll(dir) }
+}
+
+func TestFileCredentials(t *testing.T) {
+	c, cleanup := testCredentials(t, "passphrase")
+	defer cleanup()
+
+	if _, err := c.Get("token"); err != ErrCredentialNotFound {
+		t.Errorf("Wanted %v got %v", ErrCredentialNotFound, err)
+	}
+
+	if err := c.Set("token", "hunter2"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if got, err := c.Get("token"); err != nil || got != "hunter2" {
+		t.Errorf("Wanted %q got %q (%v)", "hunter2", got, err)
+	}
+
+	if buf, _ := ioutil.ReadFile(c.File); len(buf) == 0 || string(buf) == "hunter2" {
+		t.Errorf("Expected credentials file to be encrypted")
+	}
+
+	if fi, err := os.Stat(c.File); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("Expected credentials file to have mode 0600")
+	}
+
+	wrong := &Credentials{Service: "app", File: c.File, Passphrase: func() ([]byte, error) { return []byte("wrong"), nil }}
+	wrong.keyring = &fileKeyring{credentials: wrong}
+	if _, err := wrong.Get("token"); err != ErrCredentialsCorrupt {
+		t.Errorf("Wanted %v got %v", ErrCredentialsCorrupt, err)
+	}
+
+	if err := c.Delete("token"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if err := c.Delete("token"); err != ErrCredentialNotFound {
+		t.Errorf("Wanted %v got %v", ErrCredentialNotFound, err)
+	}
+}
+
+func TestFileCredentialsNotConfigured(t *testing.T) {
+	c := &Credentials{Service: "app"}
+	c.keyring = &fileKeyring{credentials: c}
+	if err := c.Set("token", "hunter2"); err != ErrNoKeyring {
+		t.Errorf("Wanted %v got %v", ErrNoKeyring, err)
+	}
+}
+
+func TestCredentialValue(t *testing.T) {
+	c, cleanup := testCredentials(t, "passphrase")
+	defer cleanup()
+	c.Set("token", "hunter2")
+
+	tests := []struct {
+		desc    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"literal", "foo", "foo", nil},
+		{"keyring", "keyring:token", "hunter2", nil},
+		{"missing", "keyring:missing", "", ErrCredentialNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got string
+			value := CredentialValue(c, &got)
+			err := value.Set(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+
+			if got != "" && value.String() != redacted {
+				t.Errorf("Expected value to be masked got %q", value.String())
+			}
+		})
+	}
+}
+
+func TestFileCredentialsDefaultFile(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("default file location depends on XDG_DATA_HOME")
+	}
+
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	prev := os.Getenv("XDG_DATA_HOME")
+	os.Setenv("XDG_DATA_HOME", dir)
+	defer os.Setenv("XDG_DATA_HOME", prev)
+
+	c := &Credentials{Service: "app", Passphrase: func() ([]byte, error) { return []byte("passphrase"), nil }}
+	c.keyring = &fileKeyring{credentials: c}
+	if err := c.Set("token", "hunter2"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	fi, err := os.Stat(filepath.Join(dir, "app", "credentials"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("Wanted mode 0600 got %v", fi.Mode().Perm())
+	}
+}
+
+func TestMacKeyringSet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a shell script in place of security")
+	}
+
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tool := filepath.Join(dir, "security")
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat > " + filepath.Join(dir, "stdin") + "\n"
+	if err := ioutil.WriteFile(tool, []byte(script), 0700); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	mk := &macKeyring{path: tool}
+	if err := mk.Set("app", "token", "hunter2"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	args, _ := ioutil.ReadFile(filepath.Join(dir, "args"))
+	if want := "add-generic-password -U -s app -a token -w\n"; want != string(args) {
+		t.Errorf("Wanted args %q got %q", want, string(args))
+	}
+
+	stdin, _ := ioutil.ReadFile(filepath.Join(dir, "stdin"))
+	if want := "hunter2\nhunter2\n"; want != string(stdin) {
+		t.Errorf("Wanted stdin %q got %q", want, string(stdin))
+	}
+}
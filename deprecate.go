@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+// deprecatedValue wraps the value of a deprecated flag so that setting
+// it prints a warning and, when the flag has been replaced, sets the
+// replacement flag instead
+type deprecatedValue struct {
+	flag.Value
+	cmd         *Command
+	name        string
+	message     string
+	replacement flag.Value
+}
+
+func (dv *deprecatedValue) Set(s string) error {
+	if dv.cmd != nil {
+		dv.cmd.Warnf("flag -%s is deprecated, %s", dv.name, dv.message)
+	}
+	if dv.replacement != nil {
+		return dv.replacement.Set(s)
+	}
+	return dv.Value.Set(s)
+}
+
+func (dv *deprecatedValue) Unwrap() Value { return dv.Value }
+
+func (dv *deprecatedValue) IsBoolFlag() bool {
+	bf, ok := dv.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+func isDeprecated(f *flag.Flag) bool {
+	_, ok := f.Value.(*deprecatedValue)
+	return ok
+}
+
+// MarkFlagDeprecated marks the flag name as deprecated. Using the flag
+// still works, but prints a warning including message, such as "use
+// -endpoint instead", to the command's Output. Deprecated flags are
+// not displayed in usage or completions. ErrFlagNotFound is returned
+// if the command has no flag called name
+func (cmd *Command) MarkFlagDeprecated(name, message string) error {
+	return cmd.deprecateFlag(name, message, nil)
+}
+
+// MarkFlagReplaced marks the flag name as deprecated in the same way
+// as MarkFlagDeprecated, except that values given to the flag are set
+// on the replacement flag. If message is empty then the warning tells
+// the user to use the replacement instead
+func (cmd *Command) MarkFlagReplaced(name, replacement, message string) error {
+	f := cmd.Flags.Lookup(replacement)
+	if f == nil {
+		return fmt.Errorf("%w: -%s", ErrFlagNotFound, replacement)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("use -%s instead", replacement)
+	}
+	return cmd.deprecateFlag(name, message, f.Value)
+}
+
+func (cmd *Command) deprecateFlag(name, message string, replacement flag.Value) error {
+	f := cmd.Flags.Lookup(name)
+	if f == nil {
+		return fmt.Errorf("%w: -%s", ErrFlagNotFound, name)
+	}
+
+	if dv, ok := f.Value.(*deprecatedValue); ok {
+		dv.message, dv.replacement = message, replacement
+		return nil
+	}
+
+	f.Value = &deprecatedValue{Value: f.Value, cmd: cmd, name: name, message: message, replacement: replacement}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMarkFlagDeprecated(t *testing.T) {
+	tests := []struct {
+		desc         string
+		args         []string
+		replace      bool
+		wantHost     string
+		wantEndpoint string
+		wantVerbose  bool
+		wantOutput   string
+	}{
+		{"not used", []string{}, false, "", "", false, ""},
+		{"deprecated", []string{"-host", "foo"}, false, "foo", "", false, "warning: flag -host is deprecated, use -endpoint instead\n"},
+		{"replaced", []string{"-host", "foo"}, true, "", "foo", false, "warning: flag -host is deprecated, use -endpoint instead\n"},
+		{"bool", []string{"-v"}, false, "", "", true, "warning: flag -v is deprecated, it has no effect\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			output := &strings.Builder{}
+			cmd := New("app", OutputOption(output))
+			host := cmd.Flags.String("host", "", "")
+			endpoint := cmd.Flags.String("endpoint", "", "")
+			verbose := cmd.Flags.Bool("v", false, "")
+
+			var err error
+			if test.replace {
+				err = cmd.MarkFlagReplaced("host", "endpoint", "")
+			} else {
+				err = cmd.MarkFlagDeprecated("host", "use -endpoint instead")
+			}
+			if err == nil {
+				err = cmd.MarkFlagDeprecated("v", "it has no effect")
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if err := cmd.parseFlags(test.args); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if *host != test.wantHost {
+				t.Errorf("Wanted host %q got %q", test.wantHost, *host)
+			}
+
+			if *endpoint != test.wantEndpoint {
+				t.Errorf("Wanted endpoint %q got %q", test.wantEndpoint, *endpoint)
+			}
+
+			if *verbose != test.wantVerbose {
+				t.Errorf("Wanted verbose %v got %v", test.wantVerbose, *verbose)
+			}
+
+			if got := output.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+		})
+	}
+}
+
+func TestMarkFlagDeprecatedNotFound(t *testing.T) {
+	cmd := New("app")
+	cmd.Flags.String("host", "", "")
+	if err := cmd.MarkFlagDeprecated("port", ""); !errors.Is(err, ErrFlagNotFound) {
+		t.Errorf("Wanted %v got %v", ErrFlagNotFound, err)
+	}
+
+	if err := cmd.MarkFlagReplaced("host", "endpoint", ""); !errors.Is(err, ErrFlagNotFound) {
+		t.Errorf("Wanted %v got %v", ErrFlagNotFound, err)
+	}
+}
+
+func TestDeprecatedFlagUsage(t *testing.T) {
+	cmd := New("app")
+	cmd.Flags.String("host", "", "the host")
+	cmd.Flags.String("endpoint", "", "the endpoint")
+	cmd.MarkFlagDeprecated("host", "use -endpoint instead")
+
+	want := "  -endpoint  string  the endpoint\n"
+	if got := flagDefaults(&cmd.Flags); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
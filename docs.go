@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDocs writes markdown documentation for the command and all of
+// its subcommands to w
+func (cmd *Command) WriteDocs(w io.Writer) error {
+	builder := &strings.Builder{}
+	cmd.writeDocs(builder, cmd.Name, 1)
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+func (cmd *Command) writeDocs(builder *strings.Builder, path string, level int) {
+	fmt.Fprintf(builder, "%s %s\n\n", strings.Repeat("#", level), path)
+	if cmd.Description != "" {
+		fmt.Fprintf(builder, "%s\n\n", cmd.Description)
+	}
+
+	if cmd.LongDescription != "" {
+		fmt.Fprintf(builder, "%s\n\n", strings.TrimSpace(cmd.LongDescription))
+	}
+
+	synopsis := path
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintf(builder, "Examples:\n\n```\n")
+		for _, ex := range cmd.Examples {
+			if ex.Description != "" {
+				fmt.Fprintf(builder, "# %s\n", ex.Description)
+			}
+			fmt.Fprintf(builder, "%s\n", strings.TrimSpace(path+" "+ex.String()))
+		}
+		fmt.Fprintf(builder, "```\n\n")
+	}
+
+	if hasFlags(&cmd.Flags) {
+		synopsis += " [options]"
+	}
+
+	if usageStr := cmd.usageStr(); usageStr != "" {
+		synopsis += " " + usageStr
+	} else if len(cmd.SubCommands) > 0 {
+		synopsis += " <command>"
+	}
+	fmt.Fprintf(builder, "```\n%s\n```\n\n", synopsis)
+
+	if hasFlags(&cmd.Flags) {
+		fmt.Fprintf(builder, "Options:\n\n```\n%s```\n\n", flagDefaults(&cmd.Flags))
+	}
+
+	if len(cmd.SubCommands) > 0 {
+		cmd.load()
+		sorted := subCommands(cmd.SubCommands).sorted()
+		fmt.Fprintf(builder, "Commands:\n\n")
+		for _, subCmd := range sorted {
+			if subCmd.hidden() {
+				continue
+			}
+
+			fmt.Fprintf(builder, "* %s", subCmd.Name)
+			if subCmd.Description != "" {
+				fmt.Fprintf(builder, " - %s", subCmd.Description)
+			}
+			fmt.Fprintln(builder)
+		}
+		fmt.Fprintln(builder)
+
+		for _, subCmd := range sorted {
+			if !subCmd.hidden() {
+				subCmd.writeDocs(builder, path+" "+subCmd.Name, level+1)
+			}
+		}
+	}
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
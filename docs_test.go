@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDocs(t *testing.T) {
+	root := New("app", DescOption("the app"))
+	root.Flags.String("config", "", "config file")
+	foo := root.SubCommand("foo", DescOption("do foo"))
+	foo.SubCommand("bar", FuncOption(func(int) {}, "<count>"))
+
+	builder := &strings.Builder{}
+	if err := root.WriteDocs(builder); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := builder.String()
+
+	for _, want := range []string{
+		"# app\n\nthe app\n\n```\napp [options] <command>\n```\n",
+		"Options:\n\n```\n  -config  string  config file\n```\n",
+		"* foo - do foo\n",
+		"## app foo\n\ndo foo\n\n```\napp foo <command>\n```\n",
+		"### app foo bar\n\n```\napp foo bar <count>\n```\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wanted docs to contain %q got %q", want, got)
+		}
+	}
+}
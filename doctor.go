@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+// Status is the outcome of a diagnostic check
+type Status int
+
+const (
+	StatusPass Status = iota // Nothing is wrong
+	StatusWarn               // Something may be wrong
+	StatusFail               // Something is definitely wrong
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "pass"
+	case StatusWarn:
+		return "warn"
+	case StatusFail:
+		return "fail"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// CheckResult is the result of a diagnostic check
+type CheckResult struct {
+	Status  Status
+	Message string
+}
+
+// Check is a diagnostic check for troubleshooting an application's
+// environment, such as checking that a config file exists or that a
+// server can be reached
+type Check func(ctx context.Context) CheckResult
+
+// DiagnosticResult is the result of running a named check
+type DiagnosticResult struct {
+	Name string
+	CheckResult
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Diagnostics is a registry of checks
+type Diagnostics struct {
+	mu     sync.Mutex
+	checks []namedCheck
+}
+
+// DefaultDiagnostics is the registry used by RegisterCheck
+var DefaultDiagnostics = &Diagnostics{}
+
+// RegisterCheck registers a check with DefaultDiagnostics
+func RegisterCheck(name string, check Check) {
+	DefaultDiagnostics.Register(name, check)
+}
+
+// Register adds a named check to the registry
+func (d *Diagnostics) Register(name string, check Check) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checks = append(d.checks, namedCheck{name, check})
+}
+
+// Run runs every check, in the order they were registered. A check
+// that panics is reported as having failed
+func (d *Diagnostics) Run(ctx context.Context) []DiagnosticResult {
+	d.mu.Lock()
+	checks := append([]namedCheck{}, d.checks...)
+	d.mu.Unlock()
+
+	results := make([]DiagnosticResult, len(checks))
+	for i, nc := range checks {
+		results[i] = DiagnosticResult{Name: nc.name, CheckResult: runCheck(ctx, nc.check)}
+	}
+	return results
+}
+
+func runCheck(ctx context.Context, check Check) (result CheckResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = CheckResult{Status: StatusFail, Message: fmt.Sprintf("check panicked: %v", r)}
+		}
+	}()
+	return check(ctx)
+}
+
+// RenderDiagnostics writes results to w as a table
+func RenderDiagnostics(w io.Writer, results []DiagnosticResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "STATUS\tCHECK\tMESSAGE\n")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Status, result.Name, result.Message)
+	}
+	return tw.Flush()
+}
+
+// AddDoctor adds a "doctor" command to parent that runs the checks in d
+// and prints the results to parent's Stdout. The command returns
+// ErrDiagnosticsFailed if any of the checks fail
+func AddDoctor(parent *Command, d *Diagnostics) *Command {
+	return parent.SubCommand("doctor",
+		DescOption("Diagnose problems with the environment"),
+		FuncOption(func() error {
+			results := d.Run(context.Background())
+			err := RenderDiagnostics(parent.Stdout(), results)
+			for _, result := range results {
+				if err == nil && result.Status == StatusFail {
+					err = ErrDiagnosticsFailed
+				}
+			}
+			return err
+		}),
+	)
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		desc    string
+		checks  map[string]Check
+		want    string
+		wantErr error
+	}{
+		{"pass", map[string]Check{
+			"config": func(context.Context) CheckResult { return CheckResult{StatusPass, "found"} },
+		}, "STATUS  CHECK   MESSAGE\npass    config  found\n", nil},
+		{"warn", map[string]Check{
+			"disk": func(context.Context) CheckResult { return CheckResult{StatusWarn, "90% full"} },
+		}, "STATUS  CHECK  MESSAGE\nwarn    disk   90% full\n", nil},
+		{"fail", map[string]Check{
+			"network": func(context.Context) CheckResult { return CheckResult{StatusFail, "unreachable"} },
+		}, "STATUS  CHECK    MESSAGE\nfail    network  unreachable\n", ErrDiagnosticsFailed},
+		{"panic", map[string]Check{
+			"bug": func(context.Context) CheckResult { panic("oops") },
+		}, "STATUS  CHECK  MESSAGE\nfail    bug    check panicked: oops\n", ErrDiagnosticsFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			d := &Diagnostics{}
+			for name, check := range test.checks {
+				d.Register(name, check)
+			}
+
+			stdout := &strings.Builder{}
+			root := New("app", StdoutOption(stdout), ErrorHandlingOption(ContinueOnError))
+			AddDoctor(root, d)
+
+			_, err := root.Run([]string{"doctor"})
+			if err != test.wantErr {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got := stdout.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRegisterCheck(t *testing.T) {
+	defer func(d *Diagnostics) { DefaultDiagnostics = d }(DefaultDiagnostics)
+	DefaultDiagnostics = &Diagnostics{}
+
+	RegisterCheck("foo", func(context.Context) CheckResult { return CheckResult{} })
+	results := DefaultDiagnostics.Run(context.Background())
+	if len(results) != 1 || results[0].Name != "foo" || results[0].Status != StatusPass {
+		t.Errorf("Unexpected results %+v", results)
+	}
+}
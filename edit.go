@@ -9,31 +9,35 @@ import (
 
 var ErrNoEditor = errors.New("No editor found in environment")
 
+// editCmd holds extra arguments and the environment for the editor.
+// A new exec.Cmd is created from it for every edit, since an exec.Cmd
+// can only be run once
 var editCmd = &exec.Cmd{}
 
 func Edit(input []byte) (output []byte, err error) {
-	editCmd.Path = os.Getenv("EDITOR")
-	if editCmd.Path == "" {
+	path := os.Getenv("EDITOR")
+	if path == "" {
 		err = ErrNoEditor
 	} else {
-		editCmd.Path, err = exec.LookPath(editCmd.Path)
+		path, err = exec.LookPath(path)
 		if err == nil {
-			editCmd.Args = append([]string{editCmd.Path}, editCmd.Args...)
-			var tmpfile *os.File
-			tmpfile, err = ioutil.TempFile("", "")
+			var ws *TempWorkspace
+			ws, err = Workspace("edit")
 			if err == nil {
-				defer os.Remove(tmpfile.Name())
-				_, err = tmpfile.Write(input)
+				defer ws.Cleanup()
+				var filename string
+				if filename, err = ws.WriteFile("edit", input); err == nil {
+					cmd := &exec.Cmd{
+						Path:   path,
+						Args:   append(append([]string{path}, editCmd.Args...), filename),
+						Env:    editCmd.Env,
+						Stdin:  os.Stdin,
+						Stdout: os.Stdout,
+						Stderr: os.Stderr,
+					}
 
-				if err = tmpfile.Close(); err == nil {
-					editCmd.Args = append(editCmd.Args, tmpfile.Name())
-					editCmd.Stdin = os.Stdin
-					editCmd.Stdout = os.Stdout
-					editCmd.Stderr = os.Stderr
-					editCmd.Start()
-					err = editCmd.Wait()
-					if err == nil {
-						output, err = ioutil.ReadFile(tmpfile.Name())
+					if err = cmd.Run(); err == nil {
+						output, err = ioutil.ReadFile(filename)
 					}
 				}
 			}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -13,21 +15,165 @@ var (
 	ErrRequiredCommand = fmt.Errorf("%w A command is required", ErrUsage)
 	ErrNoCommandFunc   = fmt.Errorf("%w No callback function was provided", ErrUsage)
 
-	errParse        = errors.New("parse error")
-	errRange        = errors.New("value out of range")
-	errNumArguments = fmt.Errorf("%w not enough arguments given", ErrUsage)
+	ErrParse = fmt.Errorf("%w parse error", ErrUsage)
+	ErrRange = fmt.Errorf("%w value out of range", ErrUsage)
+
+	ErrNumArguments     = fmt.Errorf("%w not enough arguments given", ErrUsage)
+	ErrTooManyArguments = fmt.Errorf("%w too many arguments given", ErrUsage)
+
+	ErrDuplicateCommand = errors.New("Duplicate command")
+	ErrNoAction         = errors.New("Command has neither a callback nor subcommands")
+	ErrNoDescription    = errors.New("Command is missing a description")
+
+	ErrEnvNotSet = errors.New("Environment variable not set")
+
+	ErrFlagConflict = errors.New("Flag conflict")
+	ErrFlagNotFound = errors.New("Flag not defined")
+
+	ErrNonInteractive  = errors.New("Input required but prompting is disabled")
+	ErrTooManyAttempts = errors.New("Too many invalid responses")
+
+	ErrDiagnosticsFailed = errors.New("One or more diagnostic checks failed")
+
+	ErrCredentialNotFound = errors.New("Credential not found")
+	ErrCredentialsCorrupt = errors.New("Credentials file is corrupt or the passphrase is wrong")
+	ErrNoKeyring          = errors.New("No keychain available and no credentials file configured")
+
+	ErrFrameSize = errors.New("Frame is too large")
+
+	ErrPluginVersion = errors.New("Plugin protocol version mismatch")
+
+	ErrForbidden = errors.New("Permission denied")
+
+	ErrAlreadyRunning = errors.New("Another instance is running")
+
+	ErrNoRoot = errors.New("Project root not found")
+
+	ErrInputTooLarge = errors.New("Input is too large")
+	ErrNoInput       = errors.New("No input given")
+
+	ErrTooManyMatches = fmt.Errorf("%w Pattern matches too many files", ErrUsage)
+
+	ErrUnsafeInput = errors.New("Input contains control characters or invalid UTF-8")
+
+	ErrInvalidArgumentValue = errors.New("Argument value must implement Value or SliceValue")
+
+	ErrNotCloneable = errors.New("Value can not be copied")
+
+	ErrNoSignatures = errors.New("OneOfOption was given no signatures")
 )
 
+// flagError is an error returned by the flag package. The message is
+// kept as is, but the error matches ErrParse when a flag value could
+// not be set and ErrUsage otherwise
+type flagError struct {
+	err   error
+	cause error
+}
+
+func (e *flagError) Error() string { return e.err.Error() }
+func (e *flagError) Unwrap() error { return e.cause }
+
+func newFlagError(err error) error {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+
+	cause := ErrUsage
+	if strings.HasPrefix(err.Error(), "invalid value ") {
+		cause = ErrParse
+	}
+	return &flagError{err: err, cause: cause}
+}
+
 func numError(err error) error {
 	ne, ok := err.(*strconv.NumError)
 	if !ok {
 		return err
 	}
 	if ne.Err == strconv.ErrSyntax {
-		return errParse
+		return ErrParse
 	}
 	if ne.Err == strconv.ErrRange {
-		return errRange
+		return ErrRange
 	}
 	return ne.Err
 }
+
+// UserError wraps errors that were caused by the user, such as an
+// unknown command or a value that could not be parsed. When the error
+// handling is ExitOnError, user errors exit with status code 2
+type UserError struct {
+	Err error
+}
+
+func (e *UserError) Error() string { return e.Err.Error() }
+func (e *UserError) Unwrap() error { return e.Err }
+
+// InternalError wraps errors that were caused by the program itself,
+// such as a command without a callback. When the error handling is
+// ExitOnError, internal errors exit with status code 1
+type InternalError struct {
+	Err error
+}
+
+func (e *InternalError) Error() string { return e.Err.Error() }
+func (e *InternalError) Unwrap() error { return e.Err }
+
+// WarnError is returned by a callback to report a problem that should
+// not stop the command. The warning is printed, and can be retrieved
+// with Warnings, but the command continues as though the callback
+// returned nil, unless Strict is set
+type WarnError struct {
+	Err error
+}
+
+func (e *WarnError) Error() string { return e.Err.Error() }
+func (e *WarnError) Unwrap() error { return e.Err }
+
+// Warn wraps err in a WarnError. It returns nil if err is nil
+func Warn(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &WarnError{err}
+}
+
+// ForbiddenError is returned when a command is not authorized to run.
+// It matches ErrForbidden with errors.Is
+type ForbiddenError struct {
+	Command      string
+	Capabilities []string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%v: %s requires %s", ErrForbidden, e.Command, strings.Join(e.Capabilities, ", "))
+}
+
+func (e *ForbiddenError) Is(target error) bool { return target == ErrForbidden }
+
+// classify wraps err in either a UserError or an InternalError when the
+// cause of err is known. Errors that are already classified, and errors
+// returned by callbacks, are returned unchanged
+func classify(err error) error {
+	var ue *UserError
+	var ie *InternalError
+	switch {
+	case err == nil || errors.As(err, &ue) || errors.As(err, &ie):
+		return err
+	case errors.Is(err, ErrNoCommandFunc):
+		return &InternalError{err}
+	case errors.Is(err, ErrUsage) || errors.Is(err, flag.ErrHelp):
+		return &UserError{err}
+	}
+	return err
+}
+
+// exitCode returns the status code a program should exit with for err
+func exitCode(err error) int {
+	var ue *UserError
+	if errors.As(err, &ue) {
+		return 2
+	}
+	return 1
+}
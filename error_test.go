@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	callbackErr := errors.New("callback error")
+	userErr := &UserError{callbackErr}
+
+	tests := []struct {
+		desc     string
+		input    error
+		want     error
+		wantCode int
+	}{
+		{"nil", nil, nil, 1},
+		{"callback", callbackErr, callbackErr, 1},
+		{"already classified", userErr, userErr, 2},
+		{"no command func", ErrNoCommandFunc, &InternalError{ErrNoCommandFunc}, 1},
+		{"unknown command", ErrUnknownCommand, &UserError{ErrUnknownCommand}, 2},
+		{"wrapped usage", fmt.Errorf("%w foo", ErrRequiredCommand), &UserError{fmt.Errorf("%w foo", ErrRequiredCommand)}, 2},
+		{"parse", ErrParse, &UserError{ErrParse}, 2},
+		{"range", ErrRange, &UserError{ErrRange}, 2},
+		{"help", flag.ErrHelp, &UserError{flag.ErrHelp}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := classify(test.input)
+			if fmt.Sprintf("%T %v", test.want, test.want) != fmt.Sprintf("%T %v", got, got) {
+				t.Errorf("Wanted %T %v got %T %v", test.want, test.want, got, got)
+			}
+
+			if test.input != nil && !errors.Is(got, test.input) {
+				t.Errorf("Wanted %v to wrap %v", got, test.input)
+			}
+
+			if gotCode := exitCode(got); test.wantCode != gotCode {
+				t.Errorf("Wanted exit code %d got %d", test.wantCode, gotCode)
+			}
+		})
+	}
+}
+
+func TestRunClassifiesErrors(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), FuncOption(func(int) {}))
+	_, err := cmd.Run([]string{"-foo"})
+	var ue *UserError
+	if !errors.As(err, &ue) {
+		t.Errorf("Wanted flag error to be a UserError got %T", err)
+	}
+
+	_, err = cmd.Run([]string{"one"})
+	if !errors.As(err, &ue) {
+		t.Errorf("Wanted argument error to be a UserError got %T", err)
+	}
+
+	var ie *InternalError
+	_, err = New("app", ErrorHandlingOption(ContinueOnError)).Run(nil)
+	if !errors.As(err, &ie) {
+		t.Errorf("Wanted missing callback to be an InternalError got %T", err)
+	}
+}
+
+func TestUsageSentinels(t *testing.T) {
+	for _, err := range []error{ErrParse, ErrRange, ErrNumArguments, ErrTooManyArguments, ErrTooManyMatches} {
+		if !errors.Is(err, ErrUsage) {
+			t.Errorf("Wanted %v to match ErrUsage", err)
+		}
+
+		if !errors.Is(fmt.Errorf("wrapped: %w", err), err) {
+			t.Errorf("Wanted wrapped %v to match itself", err)
+		}
+	}
+
+}
+
+func TestFlagErrors(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input []string
+		want  error
+	}{
+		{"invalid value", []string{"-n", "one"}, ErrParse},
+		{"unknown flag", []string{"-x"}, ErrUsage},
+		{"missing value", []string{"-n"}, ErrUsage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), CallbackOption(Callback(func() {})))
+			cmd.Flags.Int("n", 0, "count")
+			cmd.Flags.SetOutput(ioutil.Discard)
+			_, err := cmd.Run(test.input)
+			var ue *UserError
+			if !errors.As(err, &ue) {
+				t.Errorf("Wanted a UserError got %T", err)
+			}
+
+			if !errors.Is(err, test.want) {
+				t.Errorf("Wanted %v to match %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// EventType identifies the kind of an Event
+type EventType int
+
+const (
+	EventStarted  EventType = iota // A command is about to run its callback
+	EventOutput                    // A command wrote to Stdout or Output
+	EventPrompt                    // A command is prompting for input
+	EventFinished                  // A command has finished running
+)
+
+func (et EventType) String() string {
+	switch et {
+	case EventStarted:
+		return "started"
+	case EventOutput:
+		return "output"
+	case EventPrompt:
+		return "prompt"
+	case EventFinished:
+		return "finished"
+	}
+	return "unknown"
+}
+
+// Event describes something that happened while a command was run
+type Event struct {
+	Type    EventType
+	Command *Command
+
+	// Args are the positional arguments for EventStarted events
+	Args []string
+
+	// Data is the output written for EventOutput events and the prompt
+	// message for EventPrompt events
+	Data []byte
+
+	// Stderr is true for EventOutput events written to Output rather
+	// than Stdout
+	Stderr bool
+
+	// Err is the result of running the command for EventFinished events
+	Err error
+}
+
+// EventHandler is called, synchronously, for each event that happens
+// while running a command. An EventHandler allows a front-end, such as
+// a text user interface, to embed a command hierarchy and display its
+// execution
+type EventHandler func(Event)
+
+// EventsOption sets the EventHandler for the command. Subcommands
+// created after the handler has been set inherit it
+func EventsOption(handler EventHandler) Option {
+	return func(cmd *Command) { cmd.SetEventHandler(handler) }
+}
+
+func StdinOption(stdin io.Reader) Option { return func(cmd *Command) { cmd.SetStdin(stdin) } }
+
+// SetEventHandler sets the function that is called for each event that
+// happens while running the command
+func (cmd *Command) SetEventHandler(handler EventHandler) {
+	cmd.events = handler
+}
+
+func (cmd *Command) emit(event Event) {
+	if cmd.events != nil {
+		event.Command = cmd
+		cmd.events(event)
+	}
+}
+
+// SetStdin will set the io.Reader that prompts read from. Prompts read
+// from a file that is set here even when it is not a terminal, so
+// setting os.Stdin lets scripts pipe responses to the command
+func (cmd *Command) SetStdin(reader io.Reader) {
+	cmd.stdin = reader
+}
+
+// Stdin returns the io.Reader that prompts read from
+func (cmd *Command) Stdin() io.Reader {
+	if cmd.stdin == nil {
+		return os.Stdin
+	}
+	return cmd.stdin
+}
+
+// promptReader returns the reader that prompts read from, which is Stdin
+// unless input has been disabled (see DisableInputOption). A file set
+// with SetStdin is read even when it is not a terminal (see Interactive)
+func (cmd *Command) promptReader() io.Reader {
+	if cmd.noInput {
+		return noInputReader{}
+	} else if file, ok := cmd.stdin.(*os.File); ok {
+		return scriptedReader{file}
+	}
+	return cmd.Stdin()
+}
+
+// scriptedReader hides that a file given to SetStdin is a file, so that
+// prompts read responses from it even when it is not a terminal
+type scriptedReader struct {
+	io.Reader
+}
+
+// Query is the same as the Query function, but reads from Stdin and
+// writes to Stdout of the command. An EventPrompt event is emitted
+// before prompting. As with the Query function, an empty string is
+// returned when no response could be read
+func (cmd *Command) Query(message string, acceptable ...string) string {
+	resp, _ := cmd.QueryContext(context.Background(), message, acceptable...)
+	return resp
+}
+
+// QueryContext is the same as the QueryContext function, but reads from
+// Stdin and writes to Stdout of the command. An EventPrompt event is
+// emitted before prompting
+func (cmd *Command) QueryContext(ctx context.Context, message string, acceptable ...string) (string, error) {
+	cmd.emit(Event{Type: EventPrompt, Data: []byte(message)})
+	return queryContext(ctx, cmd.promptReader(), cmd.Stdout(), cmd.Theme(), message, acceptable...)
+}
+
+// Ask is the same as the Ask function, but reads from Stdin and writes
+// to Stdout of the command. An EventPrompt event is emitted before
+// prompting
+func (cmd *Command) Ask(message string, options ...QueryOption) (string, error) {
+	cmd.emit(Event{Type: EventPrompt, Data: []byte(message)})
+	return ask(context.Background(), cmd.promptReader(), cmd.Stdout(), cmd.Theme(), message, options...)
+}
+
+// QueryFunc is the same as the QueryFunc function, but reads from Stdin
+// and writes to Stdout of the command. An EventPrompt event is emitted
+// before prompting
+func (cmd *Command) QueryFunc(message string, validate func(string) error) (string, error) {
+	cmd.emit(Event{Type: EventPrompt, Data: []byte(message)})
+	return queryFunc(context.Background(), cmd.promptReader(), cmd.Stdout(), cmd.Theme(), message, validate)
+}
+
+// Confirm is the same as the Confirm function, but reads from Stdin and
+// writes to Stdout of the command. An EventPrompt event is emitted
+// before prompting
+func (cmd *Command) Confirm(message string, def bool) bool {
+	cmd.emit(Event{Type: EventPrompt, Data: []byte(message)})
+	return confirm(cmd.promptReader(), cmd.Stdout(), cmd.Theme(), message, def)
+}
+
+// Select is the same as the Select function, but reads from Stdin and
+// writes to Stdout of the command. An EventPrompt event is emitted
+// before prompting
+func (cmd *Command) Select(message string, choices []string, def int) (int, error) {
+	cmd.emit(Event{Type: EventPrompt, Data: []byte(message)})
+	return selectChoice(cmd.promptReader(), cmd.Stdout(), cmd.Theme(), message, choices, def)
+}
+
+// eventWriter emits an EventOutput event for everything written to it
+type eventWriter struct {
+	cmd    *Command
+	writer io.Writer
+	stderr bool
+}
+
+func (ew *eventWriter) Write(p []byte) (int, error) {
+	ew.cmd.emit(Event{Type: EventOutput, Data: append([]byte{}, p...), Stderr: ew.stderr})
+	return ew.writer.Write(p)
+}
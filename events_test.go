@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	got := []string{}
+	handler := func(event Event) {
+		str := fmt.Sprintf("%s %s", event.Type, event.Command.Name)
+		switch event.Type {
+		case EventStarted:
+			str += fmt.Sprintf(" %v", event.Args)
+		case EventOutput, EventPrompt:
+			str += fmt.Sprintf(" %q %v", event.Data, event.Stderr)
+		case EventFinished:
+			str += fmt.Sprintf(" %v", event.Err)
+		}
+		got = append(got, str)
+	}
+
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	cmd := New("root", EventsOption(handler), StdoutOption(stdout), OutputOption(stderr), StdinOption(strings.NewReader("y\n")), ErrorHandlingOption(ContinueOnError))
+	var sub *Command
+	sub = cmd.SubCommand("sub", FuncOption(func(name string) {
+		fmt.Fprintf(sub.Stdout(), "hello %s", name)
+		fmt.Fprintf(sub.Output(), "oops")
+		sub.Confirm("ok?", false)
+	}))
+
+	if _, err := cmd.Run([]string{"sub", "world"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := []string{
+		"started root [sub world]",
+		"started sub [world]",
+		`output sub "hello world" false`,
+		`output sub "oops" true`,
+		`prompt sub "ok?" false`,
+		`output sub "ok? [y/N] " false`,
+		"finished sub <nil>",
+		"finished root <nil>",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted events %q got %q", want, got)
+	}
+
+	if stdout.String() != "hello worldok? [y/N] " {
+		t.Errorf("Wanted stdout %q got %q", "hello worldok? [y/N] ", stdout.String())
+	}
+
+	if stderr.String() != "oops" {
+		t.Errorf("Wanted output %q got %q", "oops", stderr.String())
+	}
+}
+
+func TestEventTypeString(t *testing.T) {
+	tests := []struct {
+		input EventType
+		want  string
+	}{
+		{EventStarted, "started"},
+		{EventOutput, "output"},
+		{EventPrompt, "prompt"},
+		{EventFinished, "finished"},
+		{EventType(42), "unknown"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.input.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Example is an example invocation of a command that is displayed in
+// the command's usage
+type Example struct {
+	Description string
+
+	// Args are the command line arguments following the command name
+	Args []string
+
+	// Runnable examples are checked by CheckExamples
+	Runnable bool
+}
+
+// String returns the example arguments as they would be typed on the
+// command line
+func (ex Example) String() string {
+	return quoteArgs(ex.Args)
+}
+
+// quoteArgs joins args with spaces, quoting the arguments that contain
+// whitespace or quotes
+func quoteArgs(args []string) string {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		strs[i] = arg
+	}
+	return strings.Join(strs, " ")
+}
+
+func ExampleOption(description string, args ...string) Option {
+	return func(cmd *Command) {
+		cmd.Examples = append(cmd.Examples, Example{Description: description, Args: args})
+	}
+}
+
+// RunnableExampleOption adds an example that is checked by
+// CheckExamples to make sure it remains valid as the command changes
+func RunnableExampleOption(description string, args ...string) Option {
+	return func(cmd *Command) {
+		cmd.Examples = append(cmd.Examples, Example{Description: description, Args: args, Runnable: true})
+	}
+}
+
+// CheckExamples parses the arguments of every runnable example in the
+// command hierarchy, including the positional arguments expected by
+// callbacks created with FuncOption or OneOfOption and those declared
+// with SetArguments or SetArgumentsFunc, the same way ParseOnly and
+// Explain do. Callbacks are never run and the values of flags and
+// arguments are left unchanged. It is intended to be called from an
+// application's tests. A nil error is returned when all of the examples
+// are valid, otherwise the returned error is of type Errors
+func (cmd *Command) CheckExamples() error {
+	var errs Errors
+	cmd.checkExamples(cmd.Name, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (cmd *Command) checkExamples(path string, errs *Errors) {
+	for _, ex := range cmd.Examples {
+		if !ex.Runnable {
+			continue
+		}
+
+		if err := cmd.checkExample(ex.Args); err != nil {
+			*errs = append(*errs, fmt.Errorf("example %q: %w", strings.TrimSpace(path+" "+ex.String()), err))
+		}
+	}
+
+	cmd.load()
+	for _, subCmd := range cmd.SubCommands {
+		subCmd.checkExamples(path+" "+subCmd.Name, errs)
+	}
+}
+
+func (cmd *Command) checkExample(args []string) error {
+	pi, err := cmd.ParseOnly(args)
+	if err != nil {
+		return err
+	}
+
+	arguments, err := pi.arguments()
+	if err != nil {
+		return err
+	} else if arguments != nil {
+		_, err = arguments.parse(pi.Args)
+		return classify(err)
+	}
+
+	alternatives := pi.Command().alternatives
+	for i, cb := range alternatives {
+		if arguments, err = cb.arguments.clone(); err != nil {
+			return err
+		}
+
+		if _, err = arguments.parse(pi.Args); err == nil || i == len(alternatives)-1 {
+			break
+		}
+	}
+	return classify(err)
+}
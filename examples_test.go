@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExampleString(t *testing.T) {
+	tests := []struct {
+		desc string
+		args []string
+		want string
+	}{
+		{"empty", nil, ""},
+		{"plain", []string{"-v", "foo"}, "-v foo"},
+		{"quoted", []string{"foo bar", ""}, `"foo bar" ""`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := Example{Args: test.args}.String()
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+// addPairsCommand adds a command that takes the number of <key> <value>
+// pairs given with its -n flag
+func addPairsCommand(cmd *Command) *Command {
+	pairs := cmd.SubCommand("pairs", CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	n := pairs.Flags.Int("n", 1, "number of pairs")
+	pairs.SetArgumentsFunc(func() *Arguments {
+		args := &Arguments{}
+		for i := 0; i < *n; i++ {
+			args.String("<key>")
+			args.Int("<value>")
+		}
+		return args
+	})
+	return pairs
+}
+
+func TestCheckExamples(t *testing.T) {
+	tests := []struct {
+		desc    string
+		setup   func(*Command)
+		wantErr error
+	}{
+		{"not runnable", func(cmd *Command) { cmd.SubCommand("foo", ExampleOption("", "bar")) }, nil},
+		{"valid", func(cmd *Command) {
+			cmd.SubCommand("foo", FuncOption(func(int) {}), RunnableExampleOption("", "1"))
+		}, nil},
+		{"bad argument", func(cmd *Command) {
+			cmd.SubCommand("foo", FuncOption(func(int) {}), RunnableExampleOption("", "bar"))
+		}, ErrParse},
+		{"missing argument", func(cmd *Command) {
+			cmd.SubCommand("foo", FuncOption(func(int) {}), RunnableExampleOption(""))
+		}, ErrUsage},
+		{"unknown command", func(cmd *Command) {
+			cmd.SubCommand("foo", CallbackOption(func(string, ...string) ([]string, error) { t.Fatalf("Callback should not run"); return nil, nil }))
+			ExampleOption("", "bar")(cmd)
+			RunnableExampleOption("", "baz")(cmd)
+		}, ErrUnknownCommand},
+		{"alternatives", func(cmd *Command) {
+			cmd.SubCommand("foo", OneOfOption(Sig(func(int) {}), Sig(func(string, string) {})), RunnableExampleOption("", "a", "b"))
+		}, nil},
+		{"bad alternatives", func(cmd *Command) {
+			cmd.SubCommand("foo", OneOfOption(Sig(func(int) {}), Sig(func(string, string) {})), RunnableExampleOption("", "a"))
+		}, ErrUsage},
+		{"arguments func", func(cmd *Command) {
+			RunnableExampleOption("", "-n", "2", "a", "1", "b", "2")(addPairsCommand(cmd))
+		}, nil},
+		{"bad arguments func", func(cmd *Command) {
+			RunnableExampleOption("", "-n", "1", "notanint", "x")(addPairsCommand(cmd))
+		}, ErrParse},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("test", ErrorHandlingOption(ContinueOnError))
+			test.setup(cmd)
+			err := cmd.CheckExamples()
+			if test.wantErr == nil {
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+			} else if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted %v got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckExamplesFlags(t *testing.T) {
+	cmd := New("test", ErrorHandlingOption(ContinueOnError))
+	cmd.SubCommand("foo", RunnableExampleOption("", "-v"))
+	var userErr *UserError
+	if errs, ok := cmd.CheckExamples().(Errors); !ok || len(errs) != 1 {
+		t.Errorf("Wanted one error got %v", errs)
+	} else if !errors.As(errs[0], &userErr) {
+		t.Errorf("Wanted a UserError got %v", errs[0])
+	}
+}
+
+func TestExamplesUsage(t *testing.T) {
+	cmd := New("test", ExampleOption("do foo", "foo", "a b"), ExampleOption("", "bar"))
+	builder := &strings.Builder{}
+	cmd.RenderUsage(builder)
+	want := "Usage: test\nExamples:\n  # do foo\n  test foo \"a b\"\n  test bar\n\n"
+	if got := builder.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
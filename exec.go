@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// maxStderrTail is the maximum number of bytes of stderr kept in an
+// ExitError
+const maxStderrTail = 4096
+
+// ExitError is returned by Exec when a process could not be started or
+// did not exit successfully
+type ExitError struct {
+	Name string
+	Args []string
+
+	// ExitCode is the exit code of the process or -1 if the process
+	// did not exit normally (it couldn't be started or was killed)
+	ExitCode int
+
+	// Stderr holds the tail end of the process' standard error
+	Stderr []byte
+
+	Err error
+}
+
+func (e *ExitError) Error() string { return fmt.Sprintf("%s: %v", e.Name, e.Err) }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// Execer runs external processes, streaming their output through a
+// Command's writers
+type Execer struct {
+	// Prefix is written at the start of every line of output
+	Prefix string
+
+	// Color is an ANSI SGR parameter (such as "32" for green) used to
+	// color the prefix. No color is used when it is empty
+	Color string
+}
+
+// Exec runs the named program using the zero value Execer
+func Exec(ctx context.Context, cmd *Command, name string, args ...string) error {
+	return Execer{}.Exec(ctx, cmd, name, args...)
+}
+
+// Exec runs the named program with the given arguments. The process'
+// standard output is streamed to cmd's Stdout and its standard error is
+// streamed to cmd's Output. The process is killed if ctx is done before
+// it exits. A non-nil error is always of type *ExitError
+func (e Execer) Exec(ctx context.Context, cmd *Command, name string, args ...string) error {
+	prefix := e.Prefix
+	if prefix != "" && e.Color != "" {
+		prefix = colorize(e.Color, prefix)
+	}
+
+	lock := &sync.Mutex{}
+	stdout := &prefixWriter{writer: cmd.Stdout(), prefix: prefix, lock: lock}
+	stderr := &prefixWriter{writer: cmd.Output(), prefix: prefix, lock: lock}
+	tail := &tailWriter{max: maxStderrTail}
+
+	proc := exec.CommandContext(ctx, name, args...)
+	proc.Stdout = stdout
+	proc.Stderr = io.MultiWriter(stderr, tail)
+	err := proc.Run()
+	stdout.Flush()
+	stderr.Flush()
+
+	if err != nil {
+		exitErr := &ExitError{Name: name, Args: args, ExitCode: -1, Stderr: tail.Bytes(), Err: err}
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			exitErr.ExitCode = ee.ExitCode()
+		}
+
+		if ctx.Err() != nil {
+			exitErr.Err = ctx.Err()
+		}
+		return exitErr
+	}
+	return nil
+}
+
+// prefixWriter writes prefix at the start of every line. Partial
+// lines are buffered until they are complete or the writer is flushed
+type prefixWriter struct {
+	writer io.Writer
+	prefix string
+	lock   *sync.Mutex
+	buf    []byte
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	pw.buf = append(pw.buf, p...)
+	for {
+		i := bytes.IndexByte(pw.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		if err := pw.writeLine(pw.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		pw.buf = pw.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (pw *prefixWriter) writeLine(line []byte) error {
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+	_, err := fmt.Fprintf(pw.writer, "%s%s", pw.prefix, line)
+	return err
+}
+
+// Flush writes any buffered partial line
+func (pw *prefixWriter) Flush() error {
+	if len(pw.buf) == 0 {
+		return nil
+	}
+	err := pw.writeLine(pw.buf)
+	pw.buf = nil
+	return err
+}
+
+// tailWriter keeps the last max bytes written to it
+type tailWriter struct {
+	max int
+	buf []byte
+}
+
+func (tw *tailWriter) Write(p []byte) (int, error) {
+	tw.buf = append(tw.buf, p...)
+	if len(tw.buf) > tw.max {
+		tw.buf = tw.buf[len(tw.buf)-tw.max:]
+	}
+	return len(p), nil
+}
+
+func (tw *tailWriter) Bytes() []byte { return tw.buf }
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExec(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		return
+	}
+
+	tests := []struct {
+		desc       string
+		execer     Execer
+		mode       string
+		wantStdout string
+		wantStderr string
+		wantCode   int
+	}{
+		{"no prefix", Execer{}, "ok", "out 1\nout 2", "err 1\n", 0},
+		{"prefix", Execer{Prefix: "foo: "}, "ok", "foo: out 1\nfoo: out 2", "foo: err 1\n", 0},
+		{"color", Execer{Prefix: "foo: ", Color: "32"}, "ok", "\x1b[32mfoo: \x1b[0mout 1\n", "", 0},
+		{"exit code", Execer{}, "fail", "", "failed\n", 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout := &strings.Builder{}
+			stderr := &strings.Builder{}
+			cmd := New("", StdoutOption(stdout), OutputOption(stderr))
+			os.Setenv("GO_WANT_HELPER_PROCESS", "1")
+			defer os.Unsetenv("GO_WANT_HELPER_PROCESS")
+
+			err := test.execer.Exec(context.Background(), cmd, os.Args[0], "-test.run=TestExecHelperProcess", "--", test.mode)
+			if !strings.HasPrefix(stdout.String(), test.wantStdout) {
+				t.Errorf("Wanted stdout %q got %q", test.wantStdout, stdout.String())
+			}
+
+			if !strings.HasPrefix(stderr.String(), test.wantStderr) {
+				t.Errorf("Wanted stderr %q got %q", test.wantStderr, stderr.String())
+			}
+
+			if test.wantCode == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("Wanted *ExitError got %T", err)
+			}
+
+			if test.wantCode != exitErr.ExitCode {
+				t.Errorf("Wanted exit code %d got %d", test.wantCode, exitErr.ExitCode)
+			}
+
+			if string(exitErr.Stderr) != test.wantStderr {
+				t.Errorf("Wanted stderr tail %q got %q", test.wantStderr, string(exitErr.Stderr))
+			}
+		})
+	}
+}
+
+func TestExecContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	os.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	defer os.Unsetenv("GO_WANT_HELPER_PROCESS")
+	cmd := New("", StdoutOption(&strings.Builder{}), OutputOption(&strings.Builder{}))
+	err := Exec(ctx, cmd, os.Args[0], "-test.run=TestExecHelperProcess", "--", "sleep")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wanted %v got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestExecHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	switch os.Args[len(os.Args)-1] {
+	case "ok":
+		fmt.Fprintf(os.Stdout, "out 1\nout 2")
+		fmt.Fprintf(os.Stderr, "err 1\n")
+	case "fail":
+		fmt.Fprintf(os.Stderr, "failed\n")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+func TestTailWriter(t *testing.T) {
+	tw := &tailWriter{max: 4}
+	fmt.Fprintf(tw, "abc")
+	fmt.Fprintf(tw, "defg")
+	if got := string(tw.Bytes()); got != "defg" {
+		t.Errorf("Wanted %q got %q", "defg", got)
+	}
+}
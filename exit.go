@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+type exitHandler struct {
+	fn func()
+}
+
+var (
+	exitMu       sync.Mutex
+	exitHandlers []*exitHandler
+	exitSignals  chan os.Signal
+
+	// osExit is replaced in tests
+	osExit = os.Exit
+)
+
+// AtExit registers fn to restore state, such as the visibility of the
+// cursor or the terminal's echo, when the program exits before the
+// state has been restored. Registered functions are run by RunAtExit,
+// which is called when a command exits with ExitOnError, when Run
+// panics and when the program is interrupted or terminated while a
+// function is registered. The returned function removes fn, and should
+// be called once the state has been restored normally
+func AtExit(fn func()) (remove func()) {
+	handler := &exitHandler{fn}
+
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHandlers = append(exitHandlers, handler)
+	if exitSignals == nil {
+		exitSignals = make(chan os.Signal, 1)
+		signal.Notify(exitSignals, os.Interrupt, syscall.SIGTERM)
+		go handleExitSignals(exitSignals)
+	}
+
+	return func() {
+		exitMu.Lock()
+		defer exitMu.Unlock()
+		for i, h := range exitHandlers {
+			if h == handler {
+				exitHandlers = append(exitHandlers[:i], exitHandlers[i+1:]...)
+				break
+			}
+		}
+
+		if len(exitHandlers) == 0 {
+			stopExitSignals()
+		}
+	}
+}
+
+// RunAtExit runs the functions registered with AtExit, most recently
+// registered first, and removes them
+func RunAtExit() {
+	exitMu.Lock()
+	handlers := exitHandlers
+	exitHandlers = nil
+	stopExitSignals()
+	exitMu.Unlock()
+
+	for i := len(handlers) - 1; i >= 0; i-- {
+		handlers[i].fn()
+	}
+}
+
+// stopExitSignals stops handling signals. exitMu must be held
+func stopExitSignals() {
+	if exitSignals != nil {
+		signal.Stop(exitSignals)
+		close(exitSignals)
+		exitSignals = nil
+	}
+}
+
+// handleExitSignals runs the registered functions and exits when a
+// signal is received. The exit code is 128 plus the signal number, as
+// it is for shells
+func handleExitSignals(signals <-chan os.Signal) {
+	for sig := range signals {
+		RunAtExit()
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		osExit(code)
+	}
+}
+
+// recoverAtExit runs the registered functions if the program is
+// panicking, and then continues to panic
+func recoverAtExit() {
+	if r := recover(); r != nil {
+		RunAtExit()
+		panic(r)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestAtExit(t *testing.T) {
+	got := []string{}
+	AtExit(func() { got = append(got, "first") })
+	remove := AtExit(func() { got = append(got, "removed") })
+	AtExit(func() { got = append(got, "last") })
+	remove()
+
+	RunAtExit()
+	want := []string{"last", "first"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %v got %v", want, got)
+	}
+
+	RunAtExit()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected handlers to only run once, got %v", got)
+	}
+
+	if exitSignals != nil {
+		t.Errorf("Expected signals to no longer be handled")
+	}
+}
+
+func TestAtExitSignal(t *testing.T) {
+	exited := make(chan int, 1)
+	osExit = func(code int) { exited <- code }
+	defer func() { osExit = os.Exit }()
+
+	ran := make(chan bool, 1)
+	AtExit(func() { ran <- true })
+
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+	close(signals)
+	handleExitSignals(signals)
+
+	if !<-ran {
+		t.Errorf("Expected handler to run")
+	}
+
+	if want, got := 128+int(syscall.SIGTERM), <-exited; want != got {
+		t.Errorf("Wanted exit code %d got %d", want, got)
+	}
+}
+
+func TestRunAtExit(t *testing.T) {
+	tests := []struct {
+		desc     string
+		handling ErrorHandling
+		wantExit int
+	}{
+		{"exit on error", ExitOnError, 1},
+		{"panic on error", PanicOnError, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gotExit := -1
+			osExit = func(code int) { gotExit = code }
+			defer func() { osExit = os.Exit }()
+
+			restored := false
+			cmd := New("app", ErrorHandlingOption(test.handling), OutputOption(&strings.Builder{}))
+			cmd.SubCommand("raw", CallbackOption(func(string, ...string) ([]string, error) {
+				AtExit(func() { restored = true })
+				return nil, errors.New("failed")
+			}))
+
+			func() {
+				defer func() { recover() }()
+				cmd.Run([]string{"raw"})
+			}()
+
+			if !restored {
+				t.Errorf("Expected handler to run")
+			}
+
+			if test.wantExit != gotExit {
+				t.Errorf("Wanted exit code %d got %d", test.wantExit, gotExit)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Expand returns s with file and environment references expanded.
+// If s starts with "@" then the remainder of s is taken as a file name
+// and the contents of the file are returned. If s starts with "env:"
+// then the remainder of s is taken as an environment variable name and
+// the value of the variable is returned. Any other string is returned
+// unchanged
+func Expand(s string) (string, error) {
+	if strings.HasPrefix(s, "@") {
+		buf, err := ioutil.ReadFile(s[1:])
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	if strings.HasPrefix(s, "env:") {
+		name := s[len("env:"):]
+		value, found := os.LookupEnv(name)
+		if !found {
+			return "", fmt.Errorf("%w %q", ErrEnvNotSet, name)
+		}
+		return value, nil
+	}
+	return s, nil
+}
+
+type expandValue struct {
+	Value
+}
+
+func (ev *expandValue) Unwrap() Value { return ev.Value }
+
+func (ev *expandValue) Set(s string) error {
+	s, err := Expand(s)
+	if err == nil {
+		err = ev.Value.Set(s)
+	}
+	return err
+}
+
+// ExpandValue wraps value so that its input is passed through Expand
+// before being set. The returned Value can be used for both positional
+// arguments and flags:
+//
+//	cmd.Flags.Var(cli.ExpandValue(&myValue), "token", "API token")
+func ExpandValue(value Value) Value {
+	return &expandValue{value}
+}
+
+// ExpandString returns a Value that sets p to the expanded input
+func ExpandString(p *string) Value {
+	return ExpandValue((*stringValue)(p))
+}
+
+type envValue struct {
+	Value
+	env string
+}
+
+func (ev *envValue) Env() string   { return ev.env }
+func (ev *envValue) Unwrap() Value { return ev.Value }
+
+// EnvDefault sets value from the environment variable env, when it is
+// set, so that the variable takes the place of the value's default. The
+// returned Value displays env in usage output, for instance:
+//
+//	cmd.Flags.Var(cli.EnvDefault(&myValue, "APP_TOKEN"), "token", "API token")
+//
+// A variable that can not be parsed by value is ignored
+func EnvDefault(value Value, env string) Value {
+	if s, found := os.LookupEnv(env); found {
+		value.Set(s)
+	}
+	return &envValue{value, env}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.WriteString("file contents")
+	tmpfile.Close()
+
+	os.Setenv("CLI_TEST_EXPAND", "env contents")
+	defer os.Unsetenv("CLI_TEST_EXPAND")
+	os.Unsetenv("CLI_TEST_NOT_SET")
+
+	tests := []struct {
+		desc    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"literal", "foo", "foo", nil},
+		{"file", "@" + tmpfile.Name(), "file contents", nil},
+		{"missing file", "@" + tmpfile.Name() + ".missing", "", os.ErrNotExist},
+		{"env", "env:CLI_TEST_EXPAND", "env contents", nil},
+		{"missing env", "env:CLI_TEST_NOT_SET", "", ErrEnvNotSet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got string
+			err := ExpandString(&got).Set(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestEnvDefault(t *testing.T) {
+	os.Setenv("CLI_TEST_ENV_DEFAULT", "from env")
+	defer os.Unsetenv("CLI_TEST_ENV_DEFAULT")
+
+	var set, unset string
+	cmd := New("app")
+	cmd.Flags.Var(EnvDefault((*stringValue)(&set), "CLI_TEST_ENV_DEFAULT"), "set", "set from env")
+	cmd.Flags.Var(EnvDefault((*stringValue)(&unset), "CLI_TEST_ENV_UNSET"), "unset", "not set")
+
+	if set != "from env" {
+		t.Errorf("Wanted %q got %q", "from env", set)
+	}
+
+	if unset != "" {
+		t.Errorf("Wanted empty string got %q", unset)
+	}
+
+	want := "  -set    value  set from env (default from env) [$CLI_TEST_ENV_DEFAULT]\n  -unset  value  not set [$CLI_TEST_ENV_UNSET]\n"
+	if got := flagDefaults(&cmd.Flags); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import "os"
+
+// Experimental enables commands marked with ExperimentalOption. It can
+// also be enabled by setting the CLI_EXPERIMENTAL environment variable
+// to 1
+var Experimental = false
+
+// ExperimentalOption marks the command as experimental. Experimental
+// commands are hidden from usage, documentation and completion, and
+// can not be run, unless Experimental is set. When an experimental
+// command is run, a warning is printed to its Output
+func ExperimentalOption() Option {
+	return func(cmd *Command) { cmd.experimental = true }
+}
+
+// EnableExperimentalOption adds an -enable-experimental flag to the
+// command that sets Experimental
+func EnableExperimentalOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.BoolVar(&Experimental, "enable-experimental", Experimental, "enable experimental commands")
+	}
+}
+
+func experimentalEnabled() bool {
+	return Experimental || os.Getenv("CLI_EXPERIMENTAL") == "1"
+}
+
+// hidden reports whether the command is experimental while
+// experimental commands are disabled
+func (cmd *Command) hidden() bool {
+	return cmd.experimental && !experimentalEnabled()
+}
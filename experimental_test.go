@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExperimentalOption(t *testing.T) {
+	tests := []struct {
+		desc       string
+		args       []string
+		env        string
+		wantErr    error
+		wantRun    bool
+		wantOutput string
+	}{
+		{"disabled", []string{"preview"}, "", ErrUnknownCommand, false, ""},
+		{"flag", []string{"-enable-experimental", "preview"}, "", nil, true, "warning: preview is experimental and may change or be removed\n"},
+		{"env", []string{"preview"}, "1", nil, true, "warning: preview is experimental and may change or be removed\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			os.Setenv("CLI_EXPERIMENTAL", test.env)
+			defer os.Unsetenv("CLI_EXPERIMENTAL")
+			defer func() { Experimental = false }()
+
+			ran := false
+			output := &strings.Builder{}
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), EnableExperimentalOption())
+			cmd.SubCommand("preview", ExperimentalOption(), FuncOption(func() { ran = true }))
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if ran != test.wantRun {
+				t.Errorf("Wanted run %v got %v", test.wantRun, ran)
+			}
+
+			if got := output.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+		})
+	}
+}
+
+func TestExperimentalHidden(t *testing.T) {
+	cmd := New("app")
+	cmd.SubCommand("stable", DescOption("a stable command"), FuncOption(func() {}))
+	cmd.SubCommand("preview", DescOption("a preview command"), ExperimentalOption(), FuncOption(func() {}))
+
+	usage := &strings.Builder{}
+	cmd.RenderUsage(usage)
+	docs := &strings.Builder{}
+	cmd.WriteDocs(docs)
+	completion := &strings.Builder{}
+	cmd.WriteBashCompletion(completion)
+
+	for _, output := range []string{usage.String(), docs.String(), completion.String()} {
+		if !strings.Contains(output, "stable") || strings.Contains(output, "preview") {
+			t.Errorf("Expected only the stable command in %q", output)
+		}
+	}
+
+	Experimental = true
+	defer func() { Experimental = false }()
+	usage.Reset()
+	cmd.RenderUsage(usage)
+	if !strings.Contains(usage.String(), "preview") {
+		t.Errorf("Expected the preview command in %q", usage.String())
+	}
+}
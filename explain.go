@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Source identifies where the value of a flag came from
+type Source int
+
+const (
+	SourceDefault     Source = iota // The flag was not set
+	SourceCommandLine               // The flag was set on the command line
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceCommandLine:
+		return "command line"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// flagSources returns the source of every flag in flags
+func flagSources(flags *flag.FlagSet) map[string]Source {
+	sources := make(map[string]Source)
+	flags.VisitAll(func(f *flag.Flag) { sources[f.Name] = SourceDefault })
+	flags.Visit(func(f *flag.Flag) { sources[f.Name] = SourceCommandLine })
+	return sources
+}
+
+// Changed reports whether the flag name was set on the command line,
+// rather than being left at its default value
+func (cmd *Command) Changed(name string) bool {
+	changed := false
+	cmd.Flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			changed = true
+		}
+	})
+	return changed
+}
+
+// ExplainOption adds an -explain flag to the command. When the flag is
+// given, Run will call Explain, writing to the command's Stdout,
+// instead of running any callbacks
+func ExplainOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.BoolVar(&cmd.explain, "explain", false, "print the resolved flags and arguments instead of running the command")
+	}
+}
+
+// Explain resolves args, the same way ParseOnly does, and then writes
+// the value and source of every flag of every command in the resolved
+// path to w, followed by the positional arguments. No callbacks are
+// run and the values of flags and arguments are left unchanged, apart
+// from the flags being set while the command's ArgumentsFunc, if it
+// has one, is called
+func (cmd *Command) Explain(w io.Writer, args []string) error {
+	pi, err := cmd.ParseOnly(args)
+	if err != nil {
+		return err
+	}
+
+	builder := &strings.Builder{}
+	for i := range pi.Commands {
+		fmt.Fprintf(builder, "%s\n", strings.Join(pi.Path()[:i+1], " "))
+		sources := flagSources(pi.flags[i])
+		pi.flags[i].VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(builder, "  -%s = %q (%v)\n", f.Name, f.Value.String(), sources[f.Name])
+		})
+	}
+
+	remaining := pi.Args
+	arguments, err := pi.arguments()
+	if err != nil {
+		return err
+	} else if arguments != nil {
+		if err := arguments.Parse(remaining); err != nil {
+			return err
+		}
+
+		for _, arg := range arguments.args {
+			fmt.Fprintf(builder, "  %s = %q\n", arg.desc, arg.value.(fmt.Stringer).String())
+		}
+		remaining = arguments.Args()
+	}
+
+	if len(remaining) > 0 {
+		fmt.Fprintf(builder, "  remaining arguments: %q\n", remaining)
+	}
+
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
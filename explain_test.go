@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		desc    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"defaults", []string{"sub", "localhost", "80"}, "app\n  -explain = \"false\" (default)\n  -v = \"false\" (default)\napp sub\n  -n = \"0\" (default)\n  <host> = \"localhost\"\n  <port> = \"80\"\n", false},
+		{"command line", []string{"-v", "sub", "-n", "2", "localhost", "80", "extra"}, "app\n  -explain = \"false\" (default)\n  -v = \"true\" (command line)\napp sub\n  -n = \"2\" (command line)\n  <host> = \"localhost\"\n  <port> = \"80\"\n  remaining arguments: [\"extra\"]\n", false},
+		{"bad arguments", []string{"sub", "localhost", "eighty"}, "", true},
+		{"unknown command", []string{"foo"}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), ExplainOption())
+			cmd.Flags.Bool("v", false, "")
+			sub := cmd.SubCommand("sub", FuncOption(func(string, int) { t.Errorf("Callback should not run") }, "<host>", "<port>"))
+			sub.Flags.Int("n", 0, "")
+
+			builder := &strings.Builder{}
+			err := cmd.Explain(builder, test.args)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExplainOption(t *testing.T) {
+	builder := &strings.Builder{}
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), ExplainOption(), StdoutOption(builder))
+	cmd.SubCommand("sub", FuncOption(func() { t.Errorf("Callback should not run") }))
+
+	if _, err := cmd.Run([]string{"-explain", "sub"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "app\n  -explain = \"true\" (command line)\napp sub\n"
+	if got := builder.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError))
+	cmd.Flags.String("output", "text", "")
+	cmd.Flags.Bool("v", false, "")
+	cmd.parseFlags([]string{"-output", "text"})
+
+	if !cmd.Changed("output") {
+		t.Errorf("Expected output to be changed")
+	}
+
+	if cmd.Changed("v") {
+		t.Errorf("Expected v not to be changed")
+	}
+
+	if cmd.Changed("missing") {
+		t.Errorf("Expected an undefined flag not to be changed")
+	}
+}
+
+func TestExplainArguments(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError))
+	pairs := cmd.SubCommand("pairs")
+	n := pairs.Flags.Int("n", 1, "number of pairs")
+	pairs.SetArgumentsFunc(func() *Arguments {
+		args := &Arguments{}
+		for i := 0; i < *n; i++ {
+			args.String("<key>")
+			args.String("<value>")
+		}
+		return args
+	})
+
+	args := &Arguments{}
+	host := args.String("<host>")
+	cmd.SubCommand("connect", ArgumentsOption(args))
+	cmd.SubCommand("func").Flags.Var(funcValue(func(string) error { return nil }), "f", "")
+
+	tests := []struct {
+		desc    string
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{"arguments func", []string{"pairs", "-n", "2", "a", "b", "c", "d"}, "app\napp pairs\n  -n = \"2\" (command line)\n  <key> = \"a\"\n  <value> = \"b\"\n  <key> = \"c\"\n  <value> = \"d\"\n", nil},
+		{"arguments", []string{"connect", "localhost"}, "app\napp connect\n  <host> = \"localhost\"\n", nil},
+		{"not cloneable", []string{"func"}, "", ErrNotCloneable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			builder := &strings.Builder{}
+			err := cmd.Explain(builder, test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+
+			if *n != 1 || *host != "" {
+				t.Errorf("Wanted flags and arguments to be unchanged got %d %q", *n, *host)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// MkdirAll is the same as os.MkdirAll except that every directory it
+// creates has exactly the permissions perm, regardless of the umask.
+// Directories that already exist are left unchanged
+func MkdirAll(path string, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		if !info.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: path, Err: syscall.ENOTDIR}
+		}
+		return nil
+	}
+
+	if parent := filepath.Dir(path); parent != path {
+		if err := MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Mkdir(path, perm); err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	return os.Chmod(path, perm)
+}
+
+// OpenFile is the same as os.OpenFile except that, when the file is
+// created, it has exactly the permissions perm, regardless of the umask
+func OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	created := false
+	if flag&os.O_CREATE != 0 {
+		_, err := os.Lstat(name)
+		created = os.IsNotExist(err)
+	}
+
+	file, err := os.OpenFile(name, flag, perm)
+	if err == nil && created {
+		if err = file.Chmod(perm); err != nil {
+			file.Close()
+			file = nil
+		}
+	}
+	return file, err
+}
+
+// WriteFile is the same as ioutil.WriteFile except that, when the file
+// is created, it has exactly the permissions perm, regardless of the
+// umask. Files holding secrets should be written with 0600
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	file, err := OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "files")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 0777 and 0666 are reduced by any umask that is set, so they show
+	// that the permissions are applied regardless of it
+	nested := filepath.Join(dir, "a", "b")
+	if err := MkdirAll(nested, 0777); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	file := filepath.Join(nested, "file")
+	if err := WriteFile(file, []byte("data"), 0666); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want os.FileMode
+	}{
+		{filepath.Join(dir, "a"), 0777},
+		{nested, 0777},
+		{file, 0666},
+	}
+
+	for _, test := range tests {
+		info, err := os.Stat(test.path)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		if got := info.Mode().Perm(); test.want != got {
+			t.Errorf("%s: Wanted permissions %v got %v", test.path, test.want, got)
+		}
+	}
+
+	// existing files and directories keep their permissions
+	os.Chmod(file, 0600)
+	if err := WriteFile(file, []byte("more"), 0666); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0600 {
+		t.Errorf("Wanted permissions %v got %v", os.FileMode(0600), info.Mode().Perm())
+	}
+
+	if err := MkdirAll(filepath.Join(file, "c"), 0700); err == nil {
+		t.Errorf("Wanted an error creating a directory under a file")
+	}
+}
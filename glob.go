@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// expandGlobs is true on platforms whose shell does not expand
+// wildcards in arguments
+var expandGlobs = runtime.GOOS == "windows"
+
+// ExpandGlob returns an ArgumentOption that expands wildcard patterns,
+// such as "*.txt", in the input of a positional argument into the
+// matching file names. Unix shells expand wildcards before a program
+// is run, so expansion only happens on Windows where cmd.exe passes
+// patterns through unchanged. Matches are sorted by name and a pattern
+// that matches nothing is left as it is. A slice argument receives
+// every match, while any other argument requires exactly one. If
+// limit is greater than zero, an argument expanding to more than limit
+// names fails with ErrTooManyMatches
+func ExpandGlob(limit int) ArgumentOption {
+	return func(arg *argument) {
+		arg.glob = true
+		arg.globLimit = limit
+	}
+}
+
+// expand returns the file names matching s, or s itself if globbing is
+// disabled for the argument, s is not a pattern or s matches nothing
+func (arg *argument) expand(s string) []string {
+	if !arg.glob || !expandGlobs || !strings.ContainsAny(s, "*?[") {
+		return []string{s}
+	}
+
+	matches, err := filepath.Glob(s)
+	if err != nil || len(matches) == 0 {
+		return []string{s}
+	}
+	return matches
+}
+
+// expandAll expands every input for the argument, enforcing the limit
+func (arg *argument) expandAll(input []string) ([]string, error) {
+	expanded := []string{}
+	for _, s := range input {
+		expanded = append(expanded, arg.expand(s)...)
+		if arg.globLimit > 0 && len(expanded) > arg.globLimit {
+			return nil, fmt.Errorf("%w: %s matches more than %d files", ErrTooManyMatches, arg.desc, arg.globLimit)
+		}
+	}
+	return expanded, nil
+}
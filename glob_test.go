@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type stringSlice []string
+
+func (ss *stringSlice) Set(strs []string) error {
+	*ss = append(*ss, strs...)
+	return nil
+}
+
+func (ss *stringSlice) String() string { return strings.Join(*ss, ",") }
+
+func TestExpandGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "glob")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"c.txt", "a.txt", "b.txt", "d.log"} {
+		ioutil.WriteFile(filepath.Join(dir, name), nil, 0600)
+	}
+
+	prev := expandGlobs
+	expandGlobs = true
+	defer func() { expandGlobs = prev }()
+
+	path := func(names ...string) []string {
+		paths := []string{}
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		desc    string
+		input   []string
+		limit   int
+		single  bool
+		want    []string
+		wantErr error
+	}{
+		{"sorted matches", path("*.txt"), 0, false, path("a.txt", "b.txt", "c.txt"), nil},
+		{"several patterns", path("*.log", "a*"), 0, false, path("d.log", "a.txt"), nil},
+		{"no match", path("*.md"), 0, false, path("*.md"), nil},
+		{"not a pattern", []string{"plain"}, 0, false, []string{"plain"}, nil},
+		{"limit", path("*.txt"), 2, false, nil, ErrTooManyMatches},
+		{"single match", path("d*"), 0, true, path("d.log"), nil},
+		{"single too many", path("*.txt"), 0, true, nil, ErrTooManyMatches},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			args := &Arguments{}
+			got := stringSlice{}
+			var single string
+			if test.single {
+				args.StringVar(&single, "<file>", ExpandGlob(test.limit))
+			} else {
+				args.VarSlice(&got, "<files>...", ExpandGlob(test.limit))
+			}
+
+			err := args.Parse(test.input)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			if test.single {
+				got = stringSlice{single}
+			}
+
+			if !reflect.DeepEqual(stringSlice(test.want), got) {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandGlobDisabled(t *testing.T) {
+	prev := expandGlobs
+	expandGlobs = false
+	defer func() { expandGlobs = prev }()
+
+	args := &Arguments{}
+	got := args.String("<file>", ExpandGlob(0))
+	if err := args.Parse([]string{"*"}); err != nil || *got != "*" {
+		t.Errorf("Wanted * got %q (%v)", *got, err)
+	}
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// HelpFunc returns text to display in the usage of cmd
+type HelpFunc func(cmd *Command) string
+
+// SetHelpHeader sets a function returning text that is displayed before
+// the usage of the command. Subcommands created after the header has
+// been set inherit it
+func (cmd *Command) SetHelpHeader(header HelpFunc) {
+	cmd.helpHeader = header
+}
+
+// SetHelpFooter sets a function returning text that is displayed after
+// the usage of the command, such as a support URL or a hint like
+// "run 'app help <cmd>' for details". Subcommands created after the
+// footer has been set inherit it
+func (cmd *Command) SetHelpFooter(footer HelpFunc) {
+	cmd.helpFooter = footer
+}
+
+// HelpTemplate returns a HelpFunc that executes the text/template text
+// with the command as its data, for instance:
+//
+//	cmd.SetHelpFooter(cli.HelpTemplate("Run '{{.Name}} help' for details"))
+//
+// HelpTemplate panics if text can not be parsed
+func HelpTemplate(text string) HelpFunc {
+	tmpl := template.Must(template.New("help").Parse(text))
+	return func(cmd *Command) string {
+		builder := &strings.Builder{}
+		if err := tmpl.Execute(builder, cmd); err != nil {
+			return err.Error()
+		}
+		return builder.String()
+	}
+}
+
+// HelpHeaderOption sets the help header of the command
+func HelpHeaderOption(header HelpFunc) Option {
+	return func(cmd *Command) { cmd.SetHelpHeader(header) }
+}
+
+// HelpFooterOption sets the help footer of the command
+func HelpFooterOption(footer HelpFunc) Option {
+	return func(cmd *Command) { cmd.SetHelpFooter(footer) }
+}
+
+// printHelpText prints a header or footer separated from the usage by
+// a blank line. Nothing is printed for empty text
+func printHelpText(ind *indenter, text string, header bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	if header {
+		ind.Printf("%s\n\n", text)
+	} else {
+		ind.Printf("%s\n", text)
+	}
+}
+
+// HelpArgsOption adds a -help-args flag to the command and to every
+// subcommand created after the option is applied. When the flag is
+// given, Run will call HelpArgs, writing to the command's Stdout,
+// instead of running any callbacks. Applying the option to a command
+// that already has the flag has no effect
+func HelpArgsOption() Option {
+	return func(cmd *Command) {
+		if cmd.helpArgsFlag || cmd.Flags.Lookup("help-args") != nil {
+			return
+		}
+		cmd.helpArgsFlag = true
+		cmd.Flags.BoolVar(&cmd.helpArgs, "help-args", false, "print the positional arguments of the command")
+	}
+}
+
+// HelpArgs writes only the positional arguments of the command to w,
+// in the order they are expected, along with their type and any
+// choices or constraints. This is useful when the list of flags is
+// long and only the argument order is needed. The arguments are those
+// known to Schema, so when -help-args is given the arguments of an
+// ArgumentsFunc are those for the flags given with it
+func (cmd *Command) HelpArgs(w io.Writer) error {
+	builder := &strings.Builder{}
+	params := []ParamSchema{}
+	for _, param := range cmd.Schema().Params {
+		if !param.Flag {
+			params = append(params, param)
+		}
+	}
+
+	if len(params) == 0 {
+		if usageStr := cmd.usageStr(); usageStr != "" {
+			fmt.Fprintf(builder, "Usage: %s %s\n", cmd.Name, usageStr)
+		} else {
+			fmt.Fprintf(builder, "%s takes no arguments\n", cmd.Name)
+		}
+	} else {
+		fmt.Fprintf(builder, "Arguments of %s:\n", cmd.Name)
+		tw := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+		for i, param := range params {
+			fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\n", i+1, param.Description, param.Kind, argDetails(param))
+		}
+		tw.Flush()
+	}
+
+	_, err := io.WriteString(w, trimLines(builder.String()))
+	return err
+}
+
+// argDetails describes whether an argument is required along with its
+// default, choices and constraints
+func argDetails(param ParamSchema) string {
+	details := []string{"required"}
+	if param.Array {
+		details = []string{"zero or more"}
+	}
+
+	if param.Default != "" {
+		details = append(details, fmt.Sprintf("default %q", param.Default))
+	}
+
+	if len(param.Choices) > 0 {
+		details = append(details, "one of "+strings.Join(param.Choices, ", "))
+	}
+
+	keys := []string{}
+	for key := range param.Constraints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("%s %s", key, param.Constraints[key]))
+	}
+	return strings.Join(details, ", ")
+}
+
+// trimLines removes trailing whitespace, left by tabwriter padding,
+// from every line of s
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// helpArgsCommand returns the command that args refers to, starting
+// from cmd, so that "app -help-args sub" describes sub
+func (cmd *Command) helpArgsCommand(args []string) *Command {
+	for _, arg := range args {
+		subCmd, found := cmd.Lookup(arg)
+		if !found {
+			break
+		}
+		cmd = subCmd
+	}
+	return cmd
+}
+
+// UsageVerbosity selects how much usage is printed with a usage error
+type UsageVerbosity int
+
+const (
+	UsageFull     UsageVerbosity = iota // the full usage of the command
+	UsageSynopsis                       // only the one line synopsis
+	UsageHint                           // only a hint to run the command with -help
+)
+
+// UsageOnErrorOption sets how much usage is printed along with usage
+// errors, including flag parse errors, by the command and its
+// subcommands. Running a command with -help always prints its full
+// usage
+func UsageOnErrorOption(verbosity UsageVerbosity) Option {
+	return func(cmd *Command) { cmd.usageOnError = verbosity }
+}
+
+// commandPath returns the names of the command and its parents
+func (cmd *Command) commandPath() string {
+	if cmd.path == "" {
+		return cmd.Name
+	}
+	return cmd.path
+}
+
+// renderUsageOnError writes the usage selected by UsageOnErrorOption to w
+func (cmd *Command) renderUsageOnError(w io.Writer) {
+	switch cmd.usageOnError {
+	case UsageSynopsis:
+		numFlags := 0
+		cmd.Flags.VisitAll(func(*flag.Flag) { numFlags++ })
+		fmt.Fprintln(w, cmd.synopsis(numFlags))
+	case UsageHint:
+		fmt.Fprintf(w, "See '%s -help'.\n", cmd.commandPath())
+	default:
+		cmd.RenderUsage(w)
+	}
+}
+
+// printFlagError prints err, which was returned from parsing the
+// command's flags and possibly transformed by a FlagErrorFunc, followed
+// by the usage selected by UsageOnErrorOption. With UsageFull the usage
+// is printed the same way as the flag package prints it
+func (cmd *Command) printFlagError(err error) {
+	output := cmd.Flags.Output()
+	help := errors.Is(err, flag.ErrHelp)
+	if !help {
+		fmt.Fprintln(output, err)
+	}
+
+	switch {
+	case cmd.usageOnError == UsageFull && cmd.Flags.Usage != nil:
+		cmd.Flags.Usage()
+	case cmd.usageOnError == UsageFull:
+		if name := cmd.Flags.Name(); name == "" {
+			fmt.Fprintf(output, "Usage:\n")
+		} else {
+			fmt.Fprintf(output, "Usage of %s:\n", name)
+		}
+		cmd.Flags.PrintDefaults()
+	case help:
+		cmd.RenderUsage(output)
+	default:
+		cmd.renderUsageOnError(output)
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHelpHeaderFooter(t *testing.T) {
+	tests := []struct {
+		desc   string
+		header HelpFunc
+		footer HelpFunc
+		want   string
+	}{
+		{"none", nil, nil, "Usage: sub\n"},
+		{"header", HelpTemplate("{{.Name}} v1.0"), nil, "sub v1.0\n\nUsage: sub\n"},
+		{"footer", nil, HelpTemplate("Run 'app help {{.Name}}' for details\n"), "Usage: sub\nRun 'app help sub' for details\n"},
+		{"empty", func(*Command) string { return "" }, func(*Command) string { return " " }, "Usage: sub\n"},
+		{"template error", HelpTemplate("{{.Missing}}"), nil, "template: help:1:2: executing \"help\" at <.Missing>: can't evaluate field Missing in type *cli.Command\n\nUsage: sub\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			root := New("app", HelpHeaderOption(test.header), HelpFooterOption(test.footer))
+			sub := root.SubCommand("sub")
+			builder := &strings.Builder{}
+			sub.RenderUsage(builder)
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestHelpFooterUsage(t *testing.T) {
+	output := &strings.Builder{}
+	cmd := New("app", OutputOption(output), HelpFooterOption(HelpTemplate("see https://example.com")))
+	cmd.Usage()
+	if !strings.HasSuffix(output.String(), "\nsee https://example.com\n") {
+		t.Errorf("Expected footer at the end of %q", output.String())
+	}
+}
+
+func TestHelpArgs(t *testing.T) {
+	tests := []struct {
+		desc    string
+		options []Option
+		want    string
+	}{
+		{"no arguments", nil, "sub takes no arguments\n"},
+		{"usage string", []Option{UsageOption("<file>")}, "Usage: sub <file>\n"},
+		{"arguments", []Option{FuncOption(func(string, int) {}, "<host>", "<port>")}, "Arguments of sub:\n  1  <host>  string  required\n  2  <port>  int     required\n"},
+		{"constraints", []Option{FuncOption(func(*rangeValue) {}, "<count>")}, "Arguments of sub:\n  1  <count>  range  required, max 10, min 1\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sub := New("app").SubCommand("sub", test.options...)
+			builder := &strings.Builder{}
+			if err := sub.HelpArgs(builder); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestHelpArgsOption(t *testing.T) {
+	tests := []struct {
+		desc    string
+		args    []string
+		options []Option
+	}{
+		{"after command", []string{"sub", "-help-args"}, nil},
+		{"before command", []string{"-help-args", "sub"}, nil},
+		{"option repeated", []string{"sub", "-help-args"}, []Option{HelpArgsOption()}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			builder := &strings.Builder{}
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), HelpArgsOption(), StdoutOption(builder))
+			cmd.SubCommand("sub", append(test.options, FuncOption(func(string) { t.Errorf("Callback should not run") }, "<name>"))...)
+
+			if _, err := cmd.Run(test.args); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			want := "Arguments of sub:\n  1  <name>  string  required\n"
+			if got := builder.String(); want != got {
+				t.Errorf("Wanted %q got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestUsageOnErrorOption(t *testing.T) {
+	tests := []struct {
+		desc      string
+		verbosity UsageVerbosity
+		args      []string
+		want      string
+	}{
+		{"full flag error", UsageFull, []string{"sub", "-y"}, "flag provided but not defined: -y\nUsage:\n  -x string\n    \tx value\n"},
+		{"synopsis flag error", UsageSynopsis, []string{"sub", "-y"}, "flag provided but not defined: -y\nUsage: sub <name>\n"},
+		{"hint flag error", UsageHint, []string{"sub", "-y"}, "flag provided but not defined: -y\nSee 'app sub -help'.\n"},
+		{"hint help", UsageHint, []string{"sub", "-help"}, "Usage: sub <name>\n  -x  string  x value\n\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			output := &strings.Builder{}
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), UsageOnErrorOption(test.verbosity))
+			sub := cmd.SubCommand("sub", UsageOption("<name>"))
+			sub.Flags.String("x", "", "x value")
+
+			cmd.Run(test.args)
+			if got := output.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderUsageOnError(t *testing.T) {
+	tests := []struct {
+		desc      string
+		verbosity UsageVerbosity
+		want      string
+	}{
+		{"full", UsageFull, "Usage: app [global options] <command> [command options]\n  -v    verbose\n\nCommands:\nsub\n\n"},
+		{"synopsis", UsageSynopsis, "Usage: app [global options] <command> [command options]\n"},
+		{"hint", UsageHint, "See 'app -help'.\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", UsageOnErrorOption(test.verbosity))
+			cmd.Flags.Bool("v", false, "verbose")
+			cmd.SubCommand("sub")
+
+			builder := &strings.Builder{}
+			cmd.renderUsageOnError(builder)
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
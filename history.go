@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is a successful invocation recorded by HistoryOption
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	Args []string  `json:"args"`
+}
+
+type history struct {
+	file string
+	skip map[string]bool
+}
+
+// HistoryOption records every successful run of the command in file,
+// one JSON object per line. Flags created with Secret, and their
+// values, are left out of the record. HistoryOption should be set on
+// the root command only, since subcommands are recorded as part of the
+// command line of their parent. Failing to record a run prints a
+// warning but does not fail the command
+func HistoryOption(file string) Option {
+	return func(cmd *Command) {
+		cmd.history = &history{file: file, skip: make(map[string]bool)}
+	}
+}
+
+// History returns the invocations recorded by HistoryOption, oldest
+// first
+func (cmd *Command) History() ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+	if cmd.history == nil {
+		return entries, nil
+	}
+
+	file, err := os.Open(cmd.history.file)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := HistoryEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("history: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func (h *history) record(cmd *Command, args []string) error {
+	args = excludeSecrets(cmd, args)
+	for _, token := range cmd.Classify(args) {
+		if token.Kind == TokenCommand {
+			if h.skip[token.Arg] {
+				return nil
+			}
+			break
+		}
+	}
+
+	line, err := json.Marshal(HistoryEntry{Time: now().UTC(), Args: args})
+	if err != nil {
+		return err
+	}
+
+	if err := MkdirAll(filepath.Dir(h.file), 0700); err != nil {
+		return err
+	}
+
+	file, err := OpenFile(h.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// excludeSecrets returns a copy of args without secret flags and their
+// values. Flags are looked up in the command that parses them
+func excludeSecrets(cmd *Command, args []string) []string {
+	out := []string{}
+	skipValue := false
+	current := cmd
+	for _, token := range cmd.Classify(args) {
+		switch token.Kind {
+		case TokenCommand:
+			current, _ = current.Lookup(token.Arg)
+		case TokenFlag:
+			name := strings.TrimLeft(token.Arg, "-")
+			if i := strings.Index(name, "="); i >= 0 {
+				name = name[:i]
+			}
+
+			f := current.Flags.Lookup(name)
+			skipValue = f != nil && isSecret(f.Value)
+			if skipValue {
+				continue
+			}
+		case TokenFlagValue:
+			if skipValue {
+				continue
+			}
+		}
+		out = append(out, token.Arg)
+	}
+	return out
+}
+
+// addHistoryCommands adds the "history" and "redo" commands to root.
+// Neither command is itself recorded
+func addHistoryCommands(root *Command) {
+	root.history.skip["history"] = true
+	root.history.skip["redo"] = true
+
+	root.SubCommand("history",
+		DescOption("List previous commands"),
+		FuncOption(func() error {
+			entries, err := root.History()
+			for i, entry := range entries {
+				fmt.Fprintf(root.Stdout(), "%4d  %s  %s\n", i+1, entry.Time.Local().Format(time.RFC3339), quoteArgs(entry.Args))
+			}
+			return err
+		}),
+	)
+
+	root.SubCommand("redo",
+		DescOption("Run a previous command again, by default the last one"),
+		UsageOption("[n]"),
+		CallbackOption(func(name string, args ...string) ([]string, error) {
+			entries, err := root.History()
+			if err != nil {
+				return args, err
+			}
+
+			n := len(entries)
+			if len(args) > 0 {
+				if n, err = strconv.Atoi(args[0]); err != nil {
+					return args, &UserError{fmt.Errorf("%w: %q is not a history number", ErrUsage, args[0])}
+				}
+				args = args[1:]
+			}
+
+			if n < 1 || n > len(entries) {
+				return args, &UserError{fmt.Errorf("%w: no history entry %d", ErrUsage, n)}
+			}
+			_, err = root.replay(entries[n-1].Args)
+			return args, err
+		}),
+	)
+}
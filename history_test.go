@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	var password, token string
+	got := []string{}
+	stdout := &strings.Builder{}
+	root := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout))
+	root.Flags.Var(SecretString(&password), "password", "")
+	root.Flags.Var(EnvDefault(SecretString(&token), "CLI_TEST_HISTORY_TOKEN"), "token", "")
+	root.SubCommand("greet", FuncOption(func(name string) { got = append(got, name) }))
+	root.SubCommand("fail", FuncOption(func() error { return ErrUsage }))
+	AddBuiltins(root, Builtins{History: filepath.Join(dir, "state", "history")})
+
+	root.Run([]string{"-password", "hunter2", "-token", "hunter2", "greet", "alice"})
+	root.Run([]string{"fail"})
+	root.Run([]string{"greet", "bob"})
+	root.Run([]string{"history"})
+
+	entries, err := root.History()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := []HistoryEntry{
+		{now(), []string{"greet", "alice"}},
+		{now(), []string{"greet", "bob"}},
+	}
+	if !reflect.DeepEqual(want, entries) {
+		t.Errorf("Wanted %v got %v", want, entries)
+	}
+
+	wantOutput := "   1  " + now().Local().Format(time.RFC3339) + "  greet alice\n   2  " + now().Local().Format(time.RFC3339) + "  greet bob\n"
+	if stdout.String() != wantOutput {
+		t.Errorf("Wanted %q got %q", wantOutput, stdout.String())
+	}
+
+	if _, err := root.Run([]string{"redo", "1"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := root.Run([]string{"redo"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := root.Run([]string{"redo", "5"}); err == nil {
+		t.Errorf("Expected an error for a missing entry")
+	}
+
+	if want := []string{"alice", "bob", "alice", "bob"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %v got %v", want, got)
+	}
+
+	if entries, _ = root.History(); len(entries) != 2 {
+		t.Errorf("Wanted redo not to be recorded got %v", entries)
+	}
+}
+
+func TestHistoryNotRecorded(t *testing.T) {
+	cmd := New("app")
+	entries, err := cmd.History()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Wanted no entries got %v %v", entries, err)
+	}
+}
+
+func TestExcludeSecrets(t *testing.T) {
+	var key string
+	root := New("app")
+	root.SubCommand("a", FuncOption(func() {})).Flags.String("key", "", "plain key")
+	root.SubCommand("b", FuncOption(func() {})).Flags.Var(SecretString(&key), "key", "secret key")
+
+	tests := []struct {
+		desc  string
+		input []string
+		want  []string
+	}{
+		{"plain", []string{"a", "-key", "x"}, []string{"a", "-key", "x"}},
+		{"secret", []string{"b", "-key", "hunter2"}, []string{"b"}},
+		{"secret with equals", []string{"b", "-key=hunter2"}, []string{"b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := excludeSecrets(root, test.input); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+}
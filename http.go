@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type httpHandler struct {
+	methods map[string]RPCMethod
+	mu      sync.Mutex
+}
+
+// HTTPHandler returns an http.Handler that runs the leaf commands of
+// root. The URL path selects the command, so "/remote/add" runs the
+// "add" subcommand of "remote". Query and form parameters are used
+// as flags and positional arguments, named in the same way as the
+// parameters of RPCMethods. Output from the command is streamed as a
+// plain text response and the exit code of the command is sent in the
+// X-Exit-Code trailer. Requests are run one at a time and the flags of
+// the command are reset around each request. The methods are generated
+// when the handler is created, so commands added to root afterwards are
+// not served
+func HTTPHandler(root *Command) http.Handler {
+	hh := &httpHandler{methods: make(map[string]RPCMethod)}
+	for _, method := range RPCMethods(root) {
+		hh.methods[method.Name] = method
+	}
+	return hh
+}
+
+func (hh *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Replace(strings.Trim(r.URL.Path, "/"), "/", ".", -1)
+	method, found := hh.methods[name]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+
+	args, err := method.commandLine(r.Form)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Trailer", "X-Exit-Code")
+	output := &flushWriter{writer: w}
+	if flusher, ok := w.(http.Flusher); ok {
+		output.flusher = flusher
+	}
+
+	code := 0
+	if _, err := method.run(args, output, output); err != nil {
+		fmt.Fprintf(output, "%v\n", err)
+		code = exitCode(err)
+	}
+	w.Header().Set("X-Exit-Code", strconv.Itoa(code))
+}
+
+// flushWriter flushes the response after every write so that output
+// is streamed to the client as it is written
+type flushWriter struct {
+	writer  io.Writer
+	flusher http.Flusher
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.writer.Write(p)
+	if fw.flusher != nil {
+		fw.flusher.Flush()
+	}
+	return n, err
+}
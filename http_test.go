@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	server := httptest.NewServer(HTTPHandler(testRPCCommand()))
+	defer server.Close()
+
+	tests := []struct {
+		desc       string
+		path       string
+		form       url.Values
+		wantStatus int
+		wantBody   string
+		wantCode   string
+	}{
+		{"query", "/remote/add?name=origin&timeout=2s&retries=3", nil, http.StatusOK, "origin 2s", "0"},
+		{"form", "/raw", url.Values{"args": {"a", "b"}}, http.StatusOK, "a,b", "0"},
+		{"command error", "/remote/add?name=origin&timeout=never", nil, http.StatusOK, "Invalid Usage parse error\n", "2"},
+		{"missing parameter", "/remote/add?name=origin", nil, http.StatusBadRequest, "missing parameter \"timeout\"\n", ""},
+		{"repeated parameter", "/remote/add?name=a&name=b&timeout=1s", nil, http.StatusBadRequest, "parameter \"name\": expected a single value\n", ""},
+		{"not found", "/remote", nil, http.StatusNotFound, "404 page not found\n", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var resp *http.Response
+			var err error
+			if test.form == nil {
+				resp, err = http.Get(server.URL + test.path)
+			} else {
+				resp, err = http.PostForm(server.URL+test.path, test.form)
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			if test.wantStatus != resp.StatusCode {
+				t.Errorf("Wanted status %d got %d", test.wantStatus, resp.StatusCode)
+			}
+
+			if !strings.HasPrefix(string(body), test.wantBody) {
+				t.Errorf("Wanted body %q got %q", test.wantBody, string(body))
+			}
+
+			if got := resp.Trailer.Get("X-Exit-Code"); test.wantCode != got {
+				t.Errorf("Wanted exit code %q got %q", test.wantCode, got)
+			}
+		})
+	}
+}
+
+func TestHTTPHandlerFlagsReset(t *testing.T) {
+	root := New("app")
+	verbose := false
+	say := root.SubCommand("say", FuncOption(func(s string) {
+		fmt.Fprintf(root.SubCommands[0].Stdout(), "%v %s", verbose, s)
+	}, "<s>"))
+	say.Flags.BoolVar(&verbose, "v", false, "verbose")
+
+	server := httptest.NewServer(HTTPHandler(root))
+	defer server.Close()
+
+	for _, test := range []struct{ query, want string }{{"v=true&s=x", "true x"}, {"s=y", "false y"}} {
+		resp, err := http.Get(server.URL + "/say?" + test.query)
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if test.want != string(body) {
+			t.Errorf("Wanted body %q got %q", test.want, string(body))
+		}
+	}
+}
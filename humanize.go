@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HumanDuration formats d using its two most significant units, such as
+// "2h 5m" or "3d 4h". Durations under a second are formatted the same
+// way as time.Duration, rounded to the millisecond
+func HumanDuration(d time.Duration) string {
+	if d == math.MinInt64 {
+		// -d overflows, and the nanosecond lost is below the precision
+		// of the result
+		d++
+	}
+	if d < 0 {
+		return "-" + HumanDuration(-d)
+	}
+
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	d = d.Round(time.Second)
+	for i, unit := range units {
+		if d < unit.size {
+			continue
+		}
+
+		s := fmt.Sprintf("%d%s", d/unit.size, unit.suffix)
+		if rem := d % unit.size; i+1 < len(units) && rem >= units[i+1].size {
+			s += fmt.Sprintf(" %d%s", rem/units[i+1].size, units[i+1].suffix)
+		}
+		return s
+	}
+	return "0s"
+}
+
+// HumanSize formats a number of bytes using binary (IEC) units, such as
+// "512 B" or "1.4 GiB"
+func HumanSize(bytes int64) string {
+	if bytes == math.MinInt64 {
+		// -bytes overflows, and the byte lost is below the precision of
+		// the result
+		bytes++
+	}
+	if bytes < 0 {
+		return "-" + HumanSize(-bytes)
+	}
+
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	size := float64(bytes)
+	unit := -1
+	for size >= 1024 && unit < 5 {
+		size /= 1024
+		unit++
+	}
+
+	s := strconv.FormatFloat(size, 'f', 1, 64)
+	return fmt.Sprintf("%s %ciB", strings.TrimSuffix(s, ".0"), "KMGTPE"[unit])
+}
+
+// RelativeTime describes t relative to the current time, such as
+// "just now", "5 minutes ago" or "in 2 days"
+func RelativeTime(t time.Time) string {
+	d := now().Sub(t)
+	format := "%s ago"
+	if d < 0 {
+		d, format = -d, "in %s"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+
+	for _, unit := range units {
+		if n := int64(d / unit.size); n > 0 {
+			if n > 1 {
+				return fmt.Sprintf(format, fmt.Sprintf("%d %ss", n, unit.name))
+			}
+			return fmt.Sprintf(format, "1 "+unit.name)
+		}
+	}
+	return "just now"
+}
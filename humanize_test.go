@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		input time.Duration
+		want  string
+	}{
+		{0, "0s"},
+		{1500 * time.Microsecond, "2ms"},
+		{45 * time.Second, "45s"},
+		{90 * time.Second, "1m 30s"},
+		{2*time.Hour + 5*time.Minute + 10*time.Second, "2h 5m"},
+		{2*time.Hour + 10*time.Second, "2h"},
+		{76 * time.Hour, "3d 4h"},
+		{-90 * time.Second, "-1m 30s"},
+		{math.MinInt64, "-106751d 23h"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := HumanDuration(test.input); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		input int64
+		want  string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1 KiB"},
+		{1536, "1.5 KiB"},
+		{1503238553, "1.4 GiB"},
+		{-2048, "-2 KiB"},
+		{1 << 62, "4 EiB"},
+		{math.MinInt64, "-8 EiB"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := HumanSize(test.input); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	current := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		input time.Time
+		want  string
+	}{
+		{current.Add(-30 * time.Second), "just now"},
+		{current.Add(-time.Minute), "1 minute ago"},
+		{current.Add(-5 * time.Minute), "5 minutes ago"},
+		{current.Add(-2 * time.Hour), "2 hours ago"},
+		{current.Add(-50 * time.Hour), "2 days ago"},
+		{current.Add(-400 * 24 * time.Hour), "1 year ago"},
+		{current.Add(72 * time.Hour), "in 3 days"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := RelativeTime(test.input); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is one step of a JSONPath expression. It selects the
+// named field of an object, the element of an array at index or, if
+// all is set, every element of an array or every value of an object
+type jsonPathStep struct {
+	field string
+	index int
+	all   bool
+}
+
+// jsonPathPart is either literal text or an expression of a JSONPath
+// template
+type jsonPathPart struct {
+	text  string
+	steps []jsonPathStep
+	expr  bool
+}
+
+// jsonPath is a parsed JSONPath template, such as
+// "{.items[*].name}". Text outside of braces is written as it is.
+// Expressions support fields (.name), array indexes ([0] and [-1])
+// and wildcards ([*] and .*)
+type jsonPath struct {
+	parts []jsonPathPart
+}
+
+// parseJSONPath parses a JSONPath template
+func parseJSONPath(text string) (*jsonPath, error) {
+	jp := &jsonPath{}
+	for text != "" {
+		start := strings.Index(text, "{")
+		if start < 0 {
+			jp.parts = append(jp.parts, jsonPathPart{text: text})
+			break
+		}
+
+		if start > 0 {
+			jp.parts = append(jp.parts, jsonPathPart{text: text[:start]})
+		}
+
+		end := strings.Index(text[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed expression in %q", ErrParse, text)
+		}
+
+		steps, err := parseJSONPathExpr(text[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		jp.parts = append(jp.parts, jsonPathPart{steps: steps, expr: true})
+		text = text[start+end+1:]
+	}
+	return jp, nil
+}
+
+func parseJSONPathExpr(expr string) (steps []jsonPathStep, err error) {
+	s := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("%w: empty field in %q", ErrParse, expr)
+			}
+
+			if s[:end] == "*" {
+				steps = append(steps, jsonPathStep{all: true})
+			} else {
+				steps = append(steps, jsonPathStep{field: s[:end]})
+			}
+			s = s[end:]
+		case '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed index in %q", ErrParse, expr)
+			}
+
+			if index := s[1:end]; index == "*" {
+				steps = append(steps, jsonPathStep{all: true})
+			} else if i, err := strconv.Atoi(index); err == nil {
+				steps = append(steps, jsonPathStep{index: i})
+			} else {
+				return nil, fmt.Errorf("%w: invalid index %q in %q", ErrParse, index, expr)
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrParse, s, expr)
+		}
+	}
+	return steps, nil
+}
+
+// execute evaluates the template against v, which is first converted to
+// its JSON representation, and writes the output to w. When an
+// expression selects several values they are separated by spaces.
+// Strings are written without quotes and other values are written as
+// JSON
+func (jp *jsonPath) execute(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	for _, part := range jp.parts {
+		if !part.expr {
+			if _, err := io.WriteString(w, part.text); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := evalJSONPath(data, part.steps)
+		if err != nil {
+			return err
+		}
+
+		strs := make([]string, len(values))
+		for i, value := range values {
+			if s, ok := value.(string); ok {
+				strs[i] = s
+			} else {
+				b, _ := json.Marshal(value)
+				strs[i] = string(b)
+			}
+		}
+
+		if _, err := io.WriteString(w, strings.Join(strs, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evalJSONPath(data interface{}, steps []jsonPathStep) ([]interface{}, error) {
+	values := []interface{}{data}
+	for _, step := range steps {
+		next := []interface{}{}
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if step.all {
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				} else if step.field != "" {
+					field, found := v[step.field]
+					if !found {
+						return nil, fmt.Errorf("%s is not found", step.field)
+					}
+					next = append(next, field)
+				} else {
+					return nil, fmt.Errorf("can not index an object")
+				}
+			case []interface{}:
+				if step.all {
+					next = append(next, v...)
+				} else if step.field != "" {
+					return nil, fmt.Errorf("can not find field %s in an array", step.field)
+				} else {
+					i := step.index
+					if i < 0 {
+						i += len(v)
+					}
+
+					if i < 0 || i >= len(v) {
+						return nil, fmt.Errorf("index %d is out of range", step.index)
+					}
+					next = append(next, v[i])
+				}
+			default:
+				return nil, fmt.Errorf("can not select from %v", value)
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	}
+
+	data := map[string]interface{}{
+		"items": []item{{"a", 1}, {"b", 2}},
+		"kind":  "List",
+	}
+
+	tests := []struct {
+		desc    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"field", "{.kind}", "List", false},
+		{"dollar", "{$.kind}", "List", false},
+		{"wildcard", "{.items[*].name}", "a b", false},
+		{"index", "{.items[1].name}", "b", false},
+		{"negative index", "{.items[-1].size}", "2", false},
+		{"object", "{.items[0]}", `{"name":"a","size":1}`, false},
+		{"object wildcard", "{.items[0].*}", "a 1", false},
+		{"text", "kind={.kind} first={.items[0].name}\n", "kind=List first=a\n", false},
+		{"missing field", "{.missing}", "", true},
+		{"out of range", "{.items[5]}", "", true},
+		{"field of array", "{.items.name}", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			jp, err := parseJSONPath(test.input)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			builder := &strings.Builder{}
+			err = jp.execute(builder, data)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error %v", err)
+			} else if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input string
+	}{
+		{"unclosed expression", "{.name"},
+		{"unclosed index", "{.items[0}"},
+		{"invalid index", "{.items[a]}"},
+		{"empty field", "{.items..name}"},
+		{"unexpected", "{name}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := parseJSONPath(test.input)
+			if !errors.Is(err, ErrParse) {
+				t.Errorf("Wanted %v got %v", ErrParse, err)
+			}
+		})
+	}
+}
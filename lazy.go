@@ -0,0 +1,58 @@
+package cli
+
+// LazyCommand adds a subcommand whose construction is deferred until it
+// is needed, which keeps startup fast for large command hierarchies
+// with subtrees that are expensive to build. factory is called, at most
+// once, when the subcommand is looked up to be run, or when the whole
+// hierarchy is needed such as for usage, completion or documentation.
+// The command returned by factory replaces the placeholder that
+// LazyCommand returns, is given name as its Name and inherits the
+// settings of cmd in the same way as a command added with SubCommand
+func (cmd *Command) LazyCommand(name string, factory func() *Command) *Command {
+	placeholder := cmd.SubCommand(name)
+	placeholder.factory = factory
+	return placeholder
+}
+
+// load builds any lazy subcommands of cmd, replacing their placeholders.
+// Placeholders are replaced while holding cmd's loadMu, so each factory
+// is called only once even when usage is rendered concurrently
+func (cmd *Command) load() {
+	cmd.loadMu.Lock()
+	defer cmd.loadMu.Unlock()
+	for i, subCmd := range cmd.SubCommands {
+		if subCmd.factory != nil {
+			cmd.SubCommands[i] = cmd.build(subCmd)
+		}
+	}
+}
+
+// loadOne builds the lazy subcommand that placeholder stands for,
+// unless it has already been built, and returns it
+func (cmd *Command) loadOne(placeholder *Command) *Command {
+	cmd.loadMu.Lock()
+	defer cmd.loadMu.Unlock()
+	for i, subCmd := range cmd.SubCommands {
+		if subCmd == placeholder {
+			cmd.SubCommands[i] = cmd.build(subCmd)
+			return cmd.SubCommands[i]
+		} else if subCmd.Name == placeholder.Name && subCmd.factory == nil {
+			return subCmd
+		}
+	}
+	return cmd.build(placeholder)
+}
+
+// build calls the factory of placeholder. Subcommands that were added to
+// the placeholder, such as nested registered commands (see
+// AddRegisteredCommands), are added to the built command
+func (cmd *Command) build(placeholder *Command) *Command {
+	subCommand := placeholder.factory()
+	if subCommand == nil {
+		subCommand = New(placeholder.Name)
+	}
+	subCommand.Name = placeholder.Name
+	subCommand.SubCommands = append(subCommand.SubCommands, placeholder.SubCommands...)
+	cmd.graft(subCommand)
+	return subCommand
+}
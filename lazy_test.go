@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLazyCommand(t *testing.T) {
+	built := 0
+	got := ""
+	output := &strings.Builder{}
+	root := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output))
+	root.SubCommand("eager", FuncOption(func() {}))
+	root.LazyCommand("cloud", func() *Command {
+		built++
+		cmd := New("ignored", DescOption("cloud things"))
+		cmd.SubCommand("list", FuncOption(func(region string) { got = region }))
+		return cmd
+	})
+
+	if _, err := root.Run([]string{"eager"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if built != 0 {
+		t.Errorf("Expected the lazy command not to be built")
+	}
+
+	if _, err := root.Run([]string{"cloud", "list", "us-east"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if built != 1 || got != "us-east" {
+		t.Errorf("Wanted the command built once and region us-east got %d and %q", built, got)
+	}
+
+	cloud, _ := root.Lookup("cloud")
+	if cloud.Name != "cloud" || cloud.Description != "cloud things" || cloud.output != output {
+		t.Errorf("Expected the built command to be named cloud and inherit settings got %+v", cloud)
+	}
+
+	root.Run([]string{"cloud", "list", "eu-west"})
+	if built != 1 {
+		t.Errorf("Wanted the command built once got %d", built)
+	}
+}
+
+func TestLazyCommandUsage(t *testing.T) {
+	built := false
+	root := New("app")
+	root.LazyCommand("cloud", func() *Command {
+		built = true
+		return New("cloud", DescOption("cloud things"), FuncOption(func() {}))
+	})
+
+	builder := &strings.Builder{}
+	root.RenderUsage(builder)
+	if !built || !strings.Contains(builder.String(), "cloud things") {
+		t.Errorf("Expected usage to build the lazy command got %q", builder.String())
+	}
+}
+
+func TestLazyCommandConcurrentUsage(t *testing.T) {
+	var mu sync.Mutex
+	built := 0
+	root := New("app")
+	root.LazyCommand("cloud", func() *Command {
+		mu.Lock()
+		built++
+		mu.Unlock()
+		return New("cloud", DescOption("cloud things"))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			root.RenderUsage(ioutil.Discard)
+		}()
+	}
+	wg.Wait()
+
+	if built != 1 {
+		t.Errorf("Wanted the factory to be called once got %d", built)
+	}
+}
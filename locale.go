@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var extendedUnitRe = regexp.MustCompile(`([0-9]*\.?[0-9]+)(d|w)`)
+
+// ParseDuration is the same as time.ParseDuration except that it also
+// accepts the units "d" (24 hours) and "w" (7 days). For instance "1w",
+// "2d12h" and "1.5d" are all valid durations. Duration arguments, such
+// as those of Arguments.Duration and time.Duration parameters of a
+// Callback, are parsed with ParseDuration
+func ParseDuration(s string) (time.Duration, error) {
+	var err error
+	s = extendedUnitRe.ReplaceAllStringFunc(s, func(match string) string {
+		parts := extendedUnitRe.FindStringSubmatch(match)
+		v, e := strconv.ParseFloat(parts[1], 64)
+		if e != nil {
+			err = e
+			return match
+		}
+
+		if parts[2] == "d" {
+			v *= 24
+		} else {
+			v *= 24 * 7
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64) + "h"
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(s)
+}
+
+// ExtendedDuration returns a Value that sets p using ParseDuration. Use
+// it with Flags.Var for flags that accept the extended units, since
+// durations defined with Flags.Duration are parsed by the flag package
+func ExtendedDuration(p *time.Duration) Value {
+	return (*durationValue)(p)
+}
+
+// NumberFormat describes the separators used when formatting numbers
+type NumberFormat struct {
+	// Decimal separates the integer part of a number from the fraction
+	Decimal rune
+
+	// Group separates groups of thousands
+	Group rune
+}
+
+var (
+	// PointNumbers are formatted like "1,234.56"
+	PointNumbers = NumberFormat{Decimal: '.', Group: ','}
+
+	// CommaNumbers are formatted like "1.234,56"
+	CommaNumbers = NumberFormat{Decimal: ',', Group: '.'}
+)
+
+// Normalize converts s from the number format to the format expected
+// by the strconv package. ErrParse is returned if s has group
+// separators anywhere other than between groups of three digits in the
+// integer part of the number
+func (nf NumberFormat) Normalize(s string) (string, error) {
+	integer := s
+	if i := strings.IndexRune(s, nf.Decimal); i >= 0 {
+		integer = s[:i]
+		if strings.ContainsRune(s[i:], nf.Group) {
+			return "", ErrParse
+		}
+	}
+
+	if groups := strings.Split(integer, string(nf.Group)); len(groups) > 1 {
+		first := strings.TrimLeft(groups[0], "+-")
+		if len(first) < 1 || len(first) > 3 {
+			return "", ErrParse
+		}
+
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", ErrParse
+			}
+		}
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case nf.Group:
+			return -1
+		case nf.Decimal:
+			return '.'
+		}
+		return r
+	}, s), nil
+}
+
+type localeValue struct {
+	Value
+	format NumberFormat
+}
+
+func (lv *localeValue) Unwrap() Value { return lv.Value }
+
+func (lv *localeValue) Set(s string) error {
+	s, err := lv.format.Normalize(s)
+	if err == nil {
+		err = lv.Value.Set(s)
+	}
+	return err
+}
+
+// Float64 returns a Value that sets p from numbers in the number format
+func (nf NumberFormat) Float64(p *float64) Value {
+	return &localeValue{Value: (*float64Value)(p), format: nf}
+}
+
+// Int returns a Value that sets p from numbers in the number format
+func (nf NumberFormat) Int(p *int) Value {
+	return &localeValue{Value: (*intValue)(p), format: nf}
+}
+
+// Int64 returns a Value that sets p from numbers in the number format
+func (nf NumberFormat) Int64(p *int64) Value {
+	return &localeValue{Value: (*int64Value)(p), format: nf}
+}
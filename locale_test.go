@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"1h", time.Hour, false},
+		{"2d", 48 * time.Hour, false},
+		{"1w", 7 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"1w2d3h4m", (9*24+3)*time.Hour + 4*time.Minute, false},
+		{"-1d", -24 * time.Hour, false},
+		{"100ms", 100 * time.Millisecond, false},
+		{"1x", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			got, err := ParseDuration(test.input)
+			if test.wantErr != (err != nil) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExtendedDuration(t *testing.T) {
+	var d time.Duration
+	value := ExtendedDuration(&d)
+	if err := value.Set("1d"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if value.String() != "24h0m0s" {
+		t.Errorf("Wanted %q got %q", "24h0m0s", value.String())
+	}
+
+	if err := value.Set("one day"); err != ErrParse {
+		t.Errorf("Wanted %v got %v", ErrParse, err)
+	}
+}
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		desc    string
+		format  NumberFormat
+		input   string
+		want    float64
+		wantErr error
+	}{
+		{"point", PointNumbers, "1,234.56", 1234.56, nil},
+		{"comma", CommaNumbers, "1.234,56", 1234.56, nil},
+		{"comma no group", CommaNumbers, "0,5", 0.5, nil},
+		{"invalid", CommaNumbers, "1,2,3", 0, ErrParse},
+		{"short group", PointNumbers, "1,5", 0, ErrParse},
+		{"long group", PointNumbers, "1,2345", 0, ErrParse},
+		{"long first group", PointNumbers, "1234,567", 0, ErrParse},
+		{"group in fraction", PointNumbers, "1.234,5", 0, ErrParse},
+		{"negative", PointNumbers, "-1,234.5", -1234.5, nil},
+		{"millions", PointNumbers, "12,345,678", 12345678, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got float64
+			err := test.format.Float64(&got).Set(test.input)
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+
+	var i int
+	var i64 int64
+	if err := CommaNumbers.Int(&i).Set("1.234"); err != nil || i != 1234 {
+		t.Errorf("Wanted 1234 got %d (%v)", i, err)
+	}
+
+	if err := CommaNumbers.Int64(&i64).Set("1.234.567"); err != nil || i64 != 1234567 {
+		t.Errorf("Wanted 1234567 got %d (%v)", i64, err)
+	}
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// lockDir returns the directory that lock files are created in
+var lockDir = os.TempDir
+
+// lockPath returns the per-user lock file for name
+func lockPath(name string) string {
+	return filepath.Join(lockDir(), fmt.Sprintf("%s-%d.lock", name, os.Getuid()))
+}
+
+// Lock acquires the per-user lock file for name, which holds the pid of
+// the process that owns it. If another running process holds the lock,
+// an error wrapping ErrAlreadyRunning is returned. Locks left behind by
+// processes that are no longer running are removed. The returned
+// function releases the lock, unless the lock file no longer holds the
+// pid of the current process
+func Lock(name string) (unlock func(), err error) {
+	path := lockPath(name)
+
+	// the pid is written to a temporary file that is then linked into
+	// place, so the lock file never exists without its pid
+	tmp, err := writeLockFile(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err = os.Link(tmp, path)
+		if err == nil {
+			return func() { unlockFile(path) }, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+
+		data, rerr := ioutil.ReadFile(path)
+		if rerr != nil && !os.IsNotExist(rerr) {
+			return nil, rerr
+		}
+
+		if pid, perr := strconv.Atoi(strings.TrimSpace(string(data))); rerr == nil && perr == nil && processExists(pid) {
+			return nil, fmt.Errorf("%w (pid %d)", ErrAlreadyRunning, pid)
+		}
+
+		if rerr == nil {
+			if err = removeStaleLock(path, tmp+".stale", data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, err
+}
+
+// removeStaleLock removes the lock file at path, which held stale when
+// it was found to be stale. The file is first renamed to aside, so that
+// a lock created by another process after the stale one was removed is
+// never removed in its place. Such a lock is put back and an error
+// wrapping ErrAlreadyRunning is returned
+func removeStaleLock(path, aside string, stale []byte) error {
+	if err := os.Rename(path, aside); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(aside)
+
+	data, err := ioutil.ReadFile(aside)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(stale, data) {
+		os.Link(aside, path)
+		return fmt.Errorf("%w (pid %s)", ErrAlreadyRunning, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// unlockFile removes the lock file at path if it still holds the pid of
+// the current process
+func unlockFile(path string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && pid == os.Getpid() {
+		os.Remove(path)
+	}
+}
+
+// writeLockFile writes the pid of the current process to a new
+// temporary file in dir and returns its name
+func writeLockFile(dir string) (name string, err error) {
+	file, err := ioutil.TempFile(dir, ".lock")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Chmod(file.Name(), 0600)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// processExists reports whether a process with the given pid is running
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// FindProcess only succeeds on windows if the process exists
+	if runtime.GOOS == "windows" {
+		return true
+	}
+
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+// SingleInstance makes the command hold the lock for name, see Lock,
+// while its callback runs, so that only one instance of the callback
+// can run at a time for each user
+func SingleInstance(name string) Option {
+	return func(cmd *Command) { cmd.lockName = name }
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func testLockDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	lockDir = func() string { return dir }
+	return func() {
+		lockDir = os.TempDir
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLock(t *testing.T) {
+	defer testLockDir(t)()
+
+	unlock, err := Lock("app")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	_, err = Lock("app")
+	want := fmt.Sprintf("Another instance is running (pid %d)", os.Getpid())
+	if !errors.Is(err, ErrAlreadyRunning) || err.Error() != want {
+		t.Errorf("Wanted %q got %v", want, err)
+	}
+
+	unlock()
+	unlock, err = Lock("app")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	unlock()
+}
+
+func TestLockStale(t *testing.T) {
+	defer testLockDir(t)()
+
+	tests := []struct {
+		desc     string
+		contents string
+	}{
+		{"dead process", "2147483646\n"},
+		{"garbage", "foo"},
+		{"empty", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if err := ioutil.WriteFile(lockPath("app"), []byte(test.contents), 0600); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			unlock, err := Lock("app")
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			unlock()
+
+			if _, err := os.Stat(lockPath("app")); !os.IsNotExist(err) {
+				t.Errorf("Expected lock file to be removed")
+			}
+		})
+	}
+}
+
+func TestLockReplaced(t *testing.T) {
+	defer testLockDir(t)()
+
+	unlock, err := Lock("app")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// another process found the lock stale and replaced it
+	if err := ioutil.WriteFile(lockPath("app"), []byte("2147483646\n"), 0600); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	unlock()
+	if _, err := os.Stat(lockPath("app")); err != nil {
+		t.Errorf("Expected lock file of another process to be kept got %v", err)
+	}
+}
+
+func TestRemoveStaleLock(t *testing.T) {
+	defer testLockDir(t)()
+
+	path := lockPath("app")
+	if err := ioutil.WriteFile(path, []byte("42\n"), 0600); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// the stale lock was already replaced by the lock of pid 42
+	err := removeStaleLock(path, path+".stale", []byte("2147483646\n"))
+	if !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Wanted error %v got %v", ErrAlreadyRunning, err)
+	}
+
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != "42\n" {
+		t.Errorf("Expected lock file to be put back got %q %v", data, err)
+	}
+
+	if err := removeStaleLock(path, path+".stale", []byte("42\n")); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected lock file to be removed")
+	}
+}
+
+func TestSingleInstance(t *testing.T) {
+	defer testLockDir(t)()
+
+	var innerErr error
+	callback := CallbackOption(func(string, ...string) ([]string, error) {
+		_, innerErr = Lock("app")
+		return nil, nil
+	})
+
+	tests := []struct {
+		desc    string
+		options []Option
+	}{
+		{"after callback", []Option{callback, SingleInstance("app")}},
+		{"before callback", []Option{SingleInstance("app"), callback}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			innerErr = nil
+			cmd := New("app", append([]Option{ErrorHandlingOption(ContinueOnError)}, test.options...)...)
+			if _, err := cmd.Run(nil); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if !errors.Is(innerErr, ErrAlreadyRunning) {
+				t.Errorf("Wanted %v got %v", ErrAlreadyRunning, innerErr)
+			}
+
+			if _, err := os.Stat(lockPath("app")); !os.IsNotExist(err) {
+				t.Errorf("Expected lock to be released")
+			}
+		})
+	}
+}
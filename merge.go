@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Merge grafts other, along with all of its subcommands, under cmd.
+// This allows a command line application to be assembled from command
+// trees that are developed independently.
+//
+// If cmd already has a subcommand with the same name as other, and
+// neither of them has a callback, then the two are merged recursively
+// (other's flags and subcommands are added to the existing command).
+// Otherwise ErrDuplicateCommand is returned. ErrFlagConflict is
+// returned when a flag in other's tree has the same name as a flag of
+// cmd, or of any command between cmd and the flag's command, since
+// parent flags are parsed first and the result would be confusing.
+//
+// All conflicts are reported together, as Errors, and cmd is left
+// unchanged if any are found
+func (cmd *Command) Merge(other *Command) error {
+	var errs Errors
+	cmd.checkMerge(cmd.Name, other, flagNames(cmd, cmd.Name, nil), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	cmd.merge(other)
+	return nil
+}
+
+// mergeFlags calls fn for each flag of cmd that may conflict with the
+// flags of a merged command. The -help-args flag is not included since
+// HelpArgsOption adds it to every command
+func (cmd *Command) mergeFlags(fn func(*flag.Flag)) {
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if !cmd.helpArgsFlag || f.Name != "help-args" {
+			fn(f)
+		}
+	})
+}
+
+// flagNames adds the names of the flags of cmd to a copy of names,
+// recording path as the command that defines them
+func flagNames(cmd *Command, path string, names map[string]string) map[string]string {
+	n := make(map[string]string, len(names))
+	for name, p := range names {
+		n[name] = p
+	}
+	cmd.mergeFlags(func(f *flag.Flag) { n[f.Name] = path })
+	return n
+}
+
+func checkFlags(cmd *Command, path string, names map[string]string, errs *Errors) {
+	cmd.mergeFlags(func(f *flag.Flag) {
+		if p, found := names[f.Name]; found {
+			*errs = append(*errs, fmt.Errorf("%s: %w -%s is already defined by %s", path, ErrFlagConflict, f.Name, p))
+		}
+	})
+}
+
+func (cmd *Command) checkMerge(path string, other *Command, names map[string]string, errs *Errors) {
+	otherPath := path + " " + other.Name
+	existing, found := cmd.Lookup(other.Name)
+	if !found {
+		other.checkGraft(otherPath, names, errs)
+		return
+	}
+
+	if existing.Callback != nil || other.Callback != nil {
+		*errs = append(*errs, fmt.Errorf("%s: %w %q", path, ErrDuplicateCommand, other.Name))
+		return
+	}
+
+	checkFlags(other, otherPath, names, errs)
+	checkFlags(other, otherPath, flagNames(existing, otherPath, nil), errs)
+	names = flagNames(existing, otherPath, flagNames(other, otherPath, names))
+	for _, subCmd := range other.SubCommands {
+		existing.checkMerge(otherPath, subCmd, names, errs)
+	}
+}
+
+// checkGraft checks that none of the flags in the tree rooted at cmd
+// conflict with names
+func (cmd *Command) checkGraft(path string, names map[string]string, errs *Errors) {
+	checkFlags(cmd, path, names, errs)
+	names = flagNames(cmd, path, names)
+	for _, subCmd := range cmd.SubCommands {
+		subCmd.checkGraft(path+" "+subCmd.Name, names, errs)
+	}
+}
+
+func (cmd *Command) merge(other *Command) {
+	existing, found := cmd.Lookup(other.Name)
+	if !found {
+		cmd.graft(other)
+		cmd.SubCommands = append(cmd.SubCommands, other)
+		return
+	}
+
+	other.mergeFlags(func(f *flag.Flag) { existing.Flags.Var(f.Value, f.Name, f.Usage) })
+	if other.helpArgsFlag {
+		HelpArgsOption()(existing)
+	}
+	for _, subCmd := range other.SubCommands {
+		existing.merge(subCmd)
+	}
+}
+
+// graft applies the inherited settings of cmd to the tree rooted at
+// subCommand
+func (cmd *Command) graft(subCommand *Command) {
+	cmd.inherit(subCommand)
+	for _, subCmd := range subCommand.SubCommands {
+		subCommand.graft(subCmd)
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	cb := func(string, ...string) ([]string, error) { return nil, nil }
+
+	tests := []struct {
+		desc     string
+		setup    func(cmd *Command) *Command
+		wantErrs []error
+		wantCmds []string
+	}{
+		{"graft", func(cmd *Command) *Command {
+			return New("foo", CallbackOption(cb))
+		}, nil, []string{"app foo"}},
+		{"duplicate", func(cmd *Command) *Command {
+			cmd.SubCommand("foo", CallbackOption(cb))
+			return New("foo", CallbackOption(cb))
+		}, []error{ErrDuplicateCommand}, []string{"app foo"}},
+		{"merge groups", func(cmd *Command) *Command {
+			cmd.SubCommand("foo").SubCommand("bar", CallbackOption(cb))
+			other := New("foo")
+			other.Flags.Bool("v", false, "")
+			other.SubCommand("baz", CallbackOption(cb))
+			return other
+		}, nil, []string{"app foo", "app foo bar", "app foo baz"}},
+		{"flag conflict", func(cmd *Command) *Command {
+			cmd.Flags.Bool("v", false, "")
+			other := New("foo")
+			other.SubCommand("bar", CallbackOption(cb)).Flags.Bool("v", false, "")
+			return other
+		}, []error{ErrFlagConflict}, nil},
+		{"merge flag conflict", func(cmd *Command) *Command {
+			cmd.SubCommand("foo").Flags.Bool("v", false, "")
+			other := New("foo")
+			other.Flags.Bool("v", false, "")
+			other.SubCommand("bar", CallbackOption(cb)).Flags.Bool("v", false, "")
+			return other
+		}, []error{ErrFlagConflict, ErrFlagConflict}, []string{"app foo"}},
+		{"help args", func(cmd *Command) *Command {
+			HelpArgsOption()(cmd)
+			cmd.SubCommand("foo")
+			other := New("foo", HelpArgsOption())
+			other.SubCommand("bar", CallbackOption(cb))
+			return other
+		}, nil, []string{"app foo", "app foo bar"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", ErrorHandlingOption(ContinueOnError))
+			other := test.setup(cmd)
+			err := cmd.Merge(other)
+			if len(test.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+			} else {
+				var errs Errors
+				if !errors.As(err, &errs) {
+					t.Fatalf("Wanted Errors got %v", err)
+				}
+
+				if len(test.wantErrs) != len(errs) {
+					t.Errorf("Wanted %d errors got %v", len(test.wantErrs), errs)
+				}
+
+				for i, want := range test.wantErrs {
+					if i < len(errs) && !errors.Is(errs[i], want) {
+						t.Errorf("Wanted error %v got %v", want, errs[i])
+					}
+				}
+			}
+
+			gotCmds := commandPaths(cmd.Name, cmd)
+			if !reflect.DeepEqual(test.wantCmds, gotCmds) {
+				t.Errorf("Wanted commands %v got %v", test.wantCmds, gotCmds)
+			}
+		})
+	}
+}
+
+func TestMergeInherits(t *testing.T) {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError))
+	other := New("foo")
+	sub := other.SubCommand("bar")
+	if err := cmd.Merge(other); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if sub.errorHandling != ContinueOnError {
+		t.Errorf("Wanted grafted commands to inherit error handling")
+	}
+}
+
+func commandPaths(path string, cmd *Command) []string {
+	var paths []string
+	for _, subCmd := range cmd.SubCommands {
+		subPath := path + " " + subCmd.Name
+		paths = append(paths, subPath)
+		paths = append(paths, commandPaths(subPath, subCmd)...)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// Quiet suppresses the output of Infof and Warnf for all commands
+	Quiet = false
+
+	// Colors enables colored level prefixes for Warnf and Errorf
+	Colors = false
+
+	// Strict makes warnings returned by callbacks (see WarnError) fail
+	// their command. It also makes a command with declared positional
+	// arguments and no subcommands fail when given more values than its
+	// arguments accept
+	Strict = false
+)
+
+// StrictOption adds a -strict flag to the command that sets Strict
+func StrictOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.BoolVar(&Strict, "strict", Strict, "treat warnings and extra arguments as errors")
+	}
+}
+
+// Warnings returns the warnings returned by callbacks during the most
+// recent Run of the command and its subcommands
+func (cmd *Command) Warnings() []error {
+	return cmd.warnings
+}
+
+// warn prints and records err if it is a WarnError. Unless Strict is
+// set, nil is returned in place of the warning
+func (cmd *Command) warn(err error) error {
+	var we *WarnError
+	if !errors.As(err, &we) {
+		return err
+	}
+
+	cmd.warnings = append(cmd.warnings, we.Err)
+	cmd.Warnf("%v", we.Err)
+	if Strict {
+		return err
+	}
+	return nil
+}
+
+// colorize wraps s in the ANSI escape codes for the SGR parameter code
+func colorize(code, s string) string {
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, s)
+}
+
+func printLevel(writer io.Writer, prefix, color, format string, a ...interface{}) {
+	if prefix != "" && Colors {
+		prefix = colorize(color, prefix)
+	}
+
+	msg := fmt.Sprintf(format, a...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	fmt.Fprintf(writer, "%s%s", prefix, msg)
+}
+
+// Infof prints an informational message to the command's Stdout. Nothing
+// is printed when Quiet is set
+func (cmd *Command) Infof(format string, a ...interface{}) {
+	if !Quiet {
+		printLevel(cmd.stdoutWriter(true), "", "", format, a...)
+	}
+}
+
+// Warnf prints a message, prefixed with "warning: ", to the command's
+// Output. Nothing is printed when Quiet is set
+func (cmd *Command) Warnf(format string, a ...interface{}) {
+	if !Quiet {
+		printLevel(cmd.Output(), "warning: ", "33", format, a...)
+	}
+}
+
+// Errorf prints a message, prefixed with "error: ", to the command's
+// Output. Errors are printed even when Quiet is set
+func (cmd *Command) Errorf(format string, a ...interface{}) {
+	printLevel(cmd.Output(), "error: ", "31", format, a...)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMessages(t *testing.T) {
+	tests := []struct {
+		desc       string
+		quiet      bool
+		colors     bool
+		wantStdout string
+		wantOutput string
+	}{
+		{"default", false, false, "info 1\n", "warning: warn 2\nerror: error 3\n"},
+		{"quiet", true, false, "", "error: error 3\n"},
+		{"colors", false, true, "info 1\n", "\x1b[33mwarning: \x1b[0mwarn 2\n\x1b[31merror: \x1b[0merror 3\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			Quiet, Colors = test.quiet, test.colors
+			defer func() { Quiet, Colors = false, false }()
+
+			stdout := &strings.Builder{}
+			output := &strings.Builder{}
+			cmd := New("app", StdoutOption(stdout), OutputOption(output))
+			cmd.Infof("info %d", 1)
+			cmd.Warnf("warn %d\n", 2)
+			cmd.Errorf("error %d", 3)
+
+			if got := stdout.String(); test.wantStdout != got {
+				t.Errorf("Wanted stdout %q got %q", test.wantStdout, got)
+			}
+
+			if got := output.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		desc         string
+		strict       bool
+		wantErr      bool
+		wantRan      bool
+		wantWarnings int
+		wantOutput   string
+	}{
+		{"warnings", false, false, true, 2, "warning: stale cache\nwarning: slow response\n"},
+		{"strict", true, true, false, 1, "warning: stale cache\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			Strict = test.strict
+			defer func() { Strict = false }()
+
+			output := &strings.Builder{}
+			ran := false
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), CallbackOption(func(name string, args ...string) ([]string, error) {
+				return args, Warn(errors.New("stale cache"))
+			}))
+			cmd.SubCommand("sub", CallbackOption(func(name string, args ...string) ([]string, error) {
+				ran = true
+				return args, Warn(errors.New("slow response"))
+			}))
+
+			_, err := cmd.Run([]string{"sub"})
+			if gotErr := err != nil; test.wantErr != gotErr {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantRan != ran {
+				t.Errorf("Wanted subcommand ran to be %v got %v", test.wantRan, ran)
+			}
+
+			if got := len(cmd.Warnings()); test.wantWarnings != got {
+				t.Errorf("Wanted %d warnings got %d", test.wantWarnings, got)
+			}
+
+			if got := output.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+		})
+	}
+}
+
+func TestWarn(t *testing.T) {
+	if Warn(nil) != nil {
+		t.Errorf("Expected Warn(nil) to be nil")
+	}
+
+	err := errors.New("warning")
+	if got := Warn(err); !errors.Is(got, err) {
+		t.Errorf("Wanted %v to wrap %v", got, err)
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+)
+
+// UpdateNotifier checks for new versions of a program in the background
+// while a command runs, and prints a notice once the command is done
+type UpdateNotifier struct {
+	// CurrentVersion is the version of the running program
+	CurrentVersion string
+
+	// Check returns the latest available version and whether it is
+	// newer than CurrentVersion
+	Check func(ctx context.Context) (latest string, newer bool, err error)
+
+	// CacheFile holds the result of the last check. UpdateNotifierOption
+	// defaults it to "update-check.json" in the Cache directory returned
+	// by Paths
+	CacheFile string
+
+	// Interval is the minimum time between checks, the default is 24
+	// hours
+	Interval time.Duration
+
+	// Wait is how long to wait, once the command is done, for a check
+	// that has not finished yet. The default is one second
+	Wait time.Duration
+}
+
+type updateCheck struct {
+	Checked        time.Time `json:"checked"`
+	CurrentVersion string    `json:"currentVersion"`
+	Latest         string    `json:"latest,omitempty"`
+	Newer          bool      `json:"newer"`
+}
+
+// UpdateNotifierOption enables update notifications for the command.
+// The command should be the root command of the program
+func UpdateNotifierOption(notifier *UpdateNotifier) Option {
+	return func(cmd *Command) {
+		if notifier.CacheFile == "" {
+			if paths, err := Paths(cmd.Name); err == nil {
+				notifier.CacheFile = filepath.Join(paths.Cache, "update-check.json")
+			}
+		}
+		cmd.notifier = notifier
+	}
+}
+
+// start returns a channel that receives the result of the latest check.
+// The cached result is used if it is recent enough, otherwise a new
+// check is started in the background
+func (un *UpdateNotifier) start() <-chan updateCheck {
+	ch := make(chan updateCheck, 1)
+	interval := un.Interval
+	if interval == 0 {
+		interval = 24 * time.Hour
+	}
+
+	if cached, err := un.load(); err == nil && cached.CurrentVersion == un.CurrentVersion && now().Sub(cached.Checked) < interval {
+		ch <- cached
+		return ch
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		check := updateCheck{Checked: now(), CurrentVersion: un.CurrentVersion}
+		// failed checks are saved as well, so that they are not retried
+		// until the interval has passed
+		if latest, newer, err := un.Check(ctx); err == nil {
+			check.Latest, check.Newer = latest, newer
+		}
+		un.save(check)
+		ch <- check
+	}()
+	return ch
+}
+
+// notice waits for the result of a check and returns the message to
+// print, if any
+func (un *UpdateNotifier) notice(ch <-chan updateCheck) string {
+	wait := un.Wait
+	if wait == 0 {
+		wait = time.Second
+	}
+
+	select {
+	case check := <-ch:
+		if check.Newer {
+			return fmt.Sprintf("A new version is available: %s (current version %s)", check.Latest, un.CurrentVersion)
+		}
+	case <-time.After(wait):
+	}
+	return ""
+}
+
+func (un *UpdateNotifier) load() (check updateCheck, err error) {
+	buf, err := ioutil.ReadFile(un.CacheFile)
+	if err == nil {
+		err = json.Unmarshal(buf, &check)
+	}
+	return check, err
+}
+
+func (un *UpdateNotifier) save(check updateCheck) error {
+	if un.CacheFile == "" {
+		return nil
+	}
+
+	buf, err := json.Marshal(check)
+	if err == nil {
+		err = MkdirAll(filepath.Dir(un.CacheFile), 0700)
+	}
+
+	if err == nil {
+		err = WriteFile(un.CacheFile, buf, 0600)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	checks := 0
+	latest, checkErr := "v1.1.0", error(nil)
+	notifier := &UpdateNotifier{
+		CurrentVersion: "v1.0.0",
+		CacheFile:      filepath.Join(dir, "cache", "update-check.json"),
+	}
+	notifier.Check = func(context.Context) (string, bool, error) {
+		checks++
+		return latest, latest > notifier.CurrentVersion, checkErr
+	}
+
+	output := &strings.Builder{}
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), UpdateNotifierOption(notifier), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+
+	run := func(wantChecks int, want string) {
+		t.Helper()
+		output.Reset()
+		if _, err := cmd.Run(nil); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		if wantChecks != checks {
+			t.Errorf("Wanted %d checks got %d", wantChecks, checks)
+		}
+
+		if want != output.String() {
+			t.Errorf("Wanted %q got %q", want, output.String())
+		}
+	}
+
+	notice := "A new version is available: v1.1.0 (current version v1.0.0)\n"
+	run(1, notice)
+
+	// the cached result is used within the interval
+	run(1, notice)
+
+	// the cache is ignored when the current version changes
+	notifier.CurrentVersion, latest = "v1.1.0", "v1.1.0"
+	run(2, "")
+
+	// failed checks are cached
+	notifier.CurrentVersion, checkErr = "v1.2.0", errors.New("offline")
+	run(3, "")
+	run(3, "")
+
+	// the check is repeated once the interval has passed
+	now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	defer func() { now = time.Now }()
+	run(4, "")
+}
+
+func TestUpdateNotifierQuiet(t *testing.T) {
+	Quiet = true
+	defer func() { Quiet = false }()
+
+	notifier := &UpdateNotifier{
+		CurrentVersion: "v1.0.0",
+		Check: func(context.Context) (string, bool, error) {
+			t.Errorf("Expected no check when Quiet is set")
+			return "", false, nil
+		},
+	}
+
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), UpdateNotifierOption(notifier), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	cmd.Run(nil)
+}
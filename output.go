@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/abates/cli/table"
+)
+
+// outputFormats are the formats accepted by the -output flag
+var outputFormats = []string{"table", "csv", "tsv", "go-template=...", "jsonpath=..."}
+
+// TemplateFuncs are the functions available to templates given with
+// -output=go-template=...
+var TemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join": func(sep string, v interface{}) string {
+		strs := []string{}
+		value := reflect.ValueOf(v)
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			for i := 0; i < value.Len(); i++ {
+				strs = append(strs, fmt.Sprint(value.Index(i).Interface()))
+			}
+		}
+		return strings.Join(strs, sep)
+	},
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+	"ago":  RelativeTime,
+}
+
+// formatter writes the result of a command in the format selected with
+// the -output flag
+type formatter struct {
+	format    string
+	template  *template.Template
+	jsonPath  *jsonPath
+	noHeaders bool
+}
+
+func (f *formatter) String() string { return f.format }
+
+func (f *formatter) Set(s string) error {
+	if text := strings.TrimPrefix(s, "go-template="); text != s {
+		tmpl, err := template.New("output").Funcs(TemplateFuncs).Parse(text)
+		if err != nil {
+			return err
+		}
+		f.format, f.template, f.jsonPath = s, tmpl, nil
+		return nil
+	}
+
+	if text := strings.TrimPrefix(s, "jsonpath="); text != s {
+		jp, err := parseJSONPath(text)
+		if err != nil {
+			return err
+		}
+		f.format, f.template, f.jsonPath = s, nil, jp
+		return nil
+	}
+
+	for _, format := range outputFormats {
+		if s == format {
+			f.format, f.template, f.jsonPath = s, nil, nil
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q is not one of %s", ErrParse, s, strings.Join(outputFormats, ", "))
+}
+
+// OutputFormatOption adds -output and -no-headers flags to the command.
+// After the command, or one of its subcommands, runs successfully its
+// result (see SetResult) is written to Stdout in the selected format.
+// Results that are structs, or slices of structs, are written as a
+// table (see table.FromSlice), as comma separated values or as tab
+// separated values. Other results are written with fmt.Println.
+//
+// The format go-template=TEMPLATE executes a text/template, with the
+// functions in TemplateFuncs, for the result or, if the result is a
+// slice, for each of its elements. For instance:
+//
+//	app -output='go-template={{.Name}} {{join "," .Tags}}' list
+//
+// The format jsonpath=TEMPLATE evaluates a JSONPath template against the
+// JSON representation of the result, for instance:
+//
+//	app -output='jsonpath={.items[*].name}' list
+func OutputFormatOption() Option {
+	return func(cmd *Command) {
+		cmd.formatter = &formatter{format: outputFormats[0]}
+		cmd.Flags.Var(cmd.formatter, "output", "output format: "+strings.Join(outputFormats, ", "))
+		cmd.Flags.BoolVar(&cmd.formatter.noHeaders, "no-headers", false, "do not print column headers")
+	}
+}
+
+// resultFormatter returns the formatter that writes the result of the
+// command, which is either the command's own or that of the closest
+// parent that the command was run from
+func (cmd *Command) resultFormatter() *formatter {
+	if cmd.formatter != nil {
+		return cmd.formatter
+	}
+	return cmd.inheritedFmt
+}
+
+// write writes result to w in the selected format
+func (f *formatter) write(w io.Writer, result interface{}) error {
+	if f.template != nil {
+		return f.executeTemplate(w, result)
+	} else if f.jsonPath != nil {
+		if err := f.jsonPath.execute(w, result); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+
+	t, err := resultTable(result)
+	if err != nil {
+		_, err = fmt.Fprintln(w, result)
+		return err
+	}
+
+	t.NoHeaders = f.noHeaders
+	switch f.format {
+	case "csv":
+		return t.WriteCSV(w)
+	case "tsv":
+		return t.WriteTSV(w)
+	}
+	return t.Write(w)
+}
+
+// resultTable builds a table from a result that is a struct or a slice
+// of structs
+func resultTable(result interface{}) (*table.Table, error) {
+	v := reflect.ValueOf(result)
+	if reflect.Indirect(v).Kind() == reflect.Struct {
+		slice := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+		slice.Index(0).Set(v)
+		result = slice.Interface()
+	}
+	return table.FromSlice(result)
+}
+
+// executeTemplate executes the template for result, or for each element
+// of result if it is a slice, ending each output with a newline
+func (f *formatter) executeTemplate(w io.Writer, result interface{}) error {
+	items := []interface{}{result}
+	if v := reflect.ValueOf(result); v.Kind() == reflect.Slice {
+		items = items[:0]
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	}
+
+	for _, item := range items {
+		builder := &strings.Builder{}
+		if err := f.template.Execute(builder, item); err != nil {
+			return err
+		}
+
+		s := builder.String()
+		if !strings.HasSuffix(s, "\n") {
+			s += "\n"
+		}
+
+		if _, err := io.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
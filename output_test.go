@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type outputRow struct {
+	Name  string
+	Count int `table:"Total"`
+}
+
+func TestOutputFormatOption(t *testing.T) {
+	tests := []struct {
+		desc    string
+		args    []string
+		result  interface{}
+		want    string
+		wantErr bool
+	}{
+		{"table", []string{"list"}, []outputRow{{"a", 1}, {"b", 22}}, "Name  Total\na     1\nb     22\n", false},
+		{"csv", []string{"-output", "csv", "list"}, []outputRow{{"a", 1}}, "Name,Total\na,1\n", false},
+		{"tsv no headers", []string{"-output", "tsv", "-no-headers", "list"}, []outputRow{{"a", 1}}, "a\t1\n", false},
+		{"struct", []string{"-output", "csv", "list"}, &outputRow{"a", 1}, "Name,Total\na,1\n", false},
+		{"other", []string{"list"}, 42, "42\n", false},
+		{"unknown format", []string{"-output", "xml", "list"}, nil, "", true},
+		{"go-template slice", []string{"-output", "go-template={{.Name}}={{.Count}}", "list"}, []outputRow{{"a", 1}, {"b", 2}}, "a=1\nb=2\n", false},
+		{"go-template struct", []string{"-output", "go-template={{json .}}", "list"}, outputRow{"a", 1}, "{\"Name\":\"a\",\"Count\":1}\n", false},
+		{"go-template join", []string{"-output", `go-template={{join "," .}}`, "list"}, [][]string{{"a", "b"}}, "a,b\n", false},
+		{"go-template date", []string{"-output", `go-template={{date "2006-01-02" .}}`, "list"}, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "2020-01-02\n", false},
+		{"jsonpath", []string{"-output", "jsonpath={[*].Name}", "list"}, []outputRow{{"a", 1}, {"b", 2}}, "a b\n", false},
+		{"jsonpath parse error", []string{"-output", "jsonpath={.Name", "list"}, nil, "", true},
+		{"jsonpath exec error", []string{"-output", "jsonpath={.Missing}", "list"}, outputRow{"a", 1}, "", true},
+		{"go-template parse error", []string{"-output", "go-template={{.Name", "list"}, nil, "", true},
+		{"go-template exec error", []string{"-output", "go-template={{.Missing}}", "list"}, outputRow{"a", 1}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout := &strings.Builder{}
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout), OutputOption(&strings.Builder{}), OutputFormatOption())
+			cmd.SubCommand("list", FuncOption(func() interface{} { return test.result }))
+
+			_, err := cmd.Run(test.args)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := stdout.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
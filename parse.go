@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ParsedInvocation is the result of resolving a command line with
+// ParseOnly
+type ParsedInvocation struct {
+	// Commands is the resolved command path starting with the command
+	// that ParseOnly was called on
+	Commands []*Command
+
+	// Args are the positional arguments left over once the command path
+	// and flags have been resolved
+	Args []string
+
+	// flags holds the copy of the FlagSet of each command in Commands
+	// that was parsed
+	flags []*flag.FlagSet
+}
+
+// Command returns the last (leaf) command in the resolved path
+func (pi ParsedInvocation) Command() *Command {
+	if len(pi.Commands) == 0 {
+		return nil
+	}
+	return pi.Commands[len(pi.Commands)-1]
+}
+
+// Path returns the names of the commands in the resolved path
+func (pi ParsedInvocation) Path() []string {
+	path := make([]string, len(pi.Commands))
+	for i, cmd := range pi.Commands {
+		path[i] = cmd.Name
+	}
+	return path
+}
+
+// Flags returns the value of every flag that was explicitly set on
+// the command line. If more than one command in the path defines
+// the same flag then the value of the deepest command is returned
+func (pi ParsedInvocation) Flags() map[string]string {
+	flags := make(map[string]string)
+	for _, fs := range pi.flags {
+		fs.Visit(func(f *flag.Flag) { flags[f.Name] = f.Value.String() })
+	}
+	return flags
+}
+
+// withFlags calls fn while the flags of the commands in the path are
+// set to the values that were parsed, for instance so that an
+// ArgumentsFunc sees them. The previous values are restored when fn
+// returns
+func (pi ParsedInvocation) withFlags(fn func()) {
+	restores := []func(){}
+	for i, cmd := range pi.Commands {
+		pi.flags[i].Visit(func(parsed *flag.Flag) {
+			value := cmd.Flags.Lookup(parsed.Name).Value
+			saved, _ := cloneValue(value)
+			assignValue(value, parsed.Value)
+			restores = append(restores, func() { assignValue(value, saved) })
+		})
+	}
+
+	defer func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}()
+	fn()
+}
+
+// arguments returns a copy of the positional arguments of the leaf
+// command, or nil if it does not declare any. The arguments of an
+// ArgumentsFunc are those it returns for the parsed flag values. Since
+// the arguments are copied, parsing them leaves the variables they are
+// bound to unchanged
+func (pi ParsedInvocation) arguments() (*Arguments, error) {
+	leaf := pi.Command()
+	arguments := leaf.arguments
+	if leaf.argumentsFunc != nil {
+		pi.withFlags(func() { arguments = leaf.argumentsFunc() })
+	}
+
+	if arguments == nil {
+		return nil, nil
+	}
+	return arguments.clone()
+}
+
+// ParseOnly resolves the command path, flags and positional arguments
+// for args without running any callbacks. Errors are always returned,
+// regardless of the error handling mode, and nothing is printed which
+// makes ParseOnly suitable for fuzz tests and for validating input
+// before running it. Flags are parsed into copies of the commands' flag
+// values, so the values of the commands' flags are left unchanged. An
+// error matching ErrNotCloneable is returned if a flag's value can not
+// be copied.
+//
+// Since callbacks are not run, ParseOnly can not know how many
+// arguments a callback would have consumed. When a command has both a
+// callback and subcommands, the first positional argument is used as
+// the subcommand name if it matches one, otherwise resolution stops at
+// that command. Resolution also stops at a command with a NotFoundHandler
+// when the subcommand can not be found
+func (cmd *Command) ParseOnly(args []string) (pi ParsedInvocation, err error) {
+	if cmd.resolvePath {
+		args = cmd.resolveArgs(args)
+	}
+
+	for {
+		var flags *flag.FlagSet
+		if flags, err = cloneFlagSet(&cmd.Flags); err != nil {
+			return pi, err
+		}
+
+		pi.Commands = append(pi.Commands, cmd)
+		pi.flags = append(pi.flags, flags)
+		if err = parseFlagSet(flags, args); err != nil {
+			return pi, &UserError{err}
+		}
+
+		args = flags.Args()
+		if len(cmd.SubCommands) == 0 {
+			break
+		}
+
+		if len(args) == 0 {
+			if cmd.Callback == nil {
+				err = ErrRequiredCommand
+			}
+			break
+		}
+
+		subCmd, found := cmd.Lookup(args[0])
+		if !found {
+			if cmd.Callback == nil && cmd.NotFoundHandler == nil {
+				err = fmt.Errorf("%w %q", ErrUnknownCommand, args[0])
+			}
+			break
+		}
+		cmd, args = subCmd, args[1:]
+	}
+	pi.Args = args
+	return pi, classify(err)
+}
+
+// ResolvePathOption makes the command resolve the complete subcommand
+// path before any flags are parsed. Flags can then be given anywhere on
+// the command line and each flag is parsed by the deepest command in
+// the path that defines it, so the flags of a parent command act as
+// persistent flags of its subcommands. Flags that no command in the
+// path defines are reported by the last command in the path
+func ResolvePathOption() Option {
+	return func(cmd *Command) { cmd.resolvePath = true }
+}
+
+// resolveArgs reorders args so that every flag directly follows the
+// name of the command that defines it and all positional arguments
+// follow the last command in the path
+func (cmd *Command) resolveArgs(args []string) []string {
+	type flagArg struct {
+		name   string
+		tokens []string
+	}
+
+	path := []*Command{cmd}
+	names := []string{cmd.Name}
+	flags := []flagArg{}
+	positional := []string{}
+	terminated := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			// the terminator is kept since it may also be the delimiter
+			// of an argument (see Delimiter)
+			positional = append(positional, args[i:]...)
+			terminated = true
+			break
+		}
+
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			fa := flagArg{name: strings.TrimLeft(arg, "-"), tokens: []string{arg}}
+			if j := strings.Index(fa.name, "="); j >= 0 {
+				fa.name = fa.name[:j]
+			} else if f := resolveFlag(path, fa.name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				fa.tokens = append(fa.tokens, args[i])
+			}
+			flags = append(flags, fa)
+			continue
+		}
+
+		if len(positional) == 0 {
+			if subCmd, found := path[len(path)-1].Lookup(arg); found {
+				path = append(path, subCmd)
+				names = append(names, arg)
+				continue
+			}
+		}
+		positional = append(positional, arg)
+	}
+
+	resolved := []string{}
+	for i, c := range path {
+		if i > 0 {
+			resolved = append(resolved, names[i])
+		}
+
+		for _, fa := range flags {
+			if flagOwner(path, fa.name) == c {
+				resolved = append(resolved, fa.tokens...)
+			}
+		}
+	}
+
+	for _, arg := range positional {
+		if !terminated && strings.HasPrefix(arg, "-") {
+			resolved = append(resolved, "--")
+			break
+		}
+	}
+	return append(resolved, positional...)
+}
+
+// resolveFlag finds the named flag in the path, preferring the deepest
+// command, or in the subcommands of the last command in the path
+func resolveFlag(path []*Command, name string) *flag.Flag {
+	if owner := flagOwner(path, name); owner.Flags.Lookup(name) != nil {
+		return owner.Flags.Lookup(name)
+	}
+	return lookupFlag(path[len(path)-1], name)
+}
+
+// flagOwner returns the deepest command in the path that defines the
+// named flag, or the last command in the path if none of them do
+func flagOwner(path []*Command, name string) *Command {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Flags.Lookup(name) != nil {
+			return path[i]
+		}
+	}
+	return path[len(path)-1]
+}
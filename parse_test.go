@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseOnly(t *testing.T) {
+	cb := func(string, ...string) ([]string, error) { panic("callback should not run") }
+
+	tests := []struct {
+		desc      string
+		setup     func(*Command)
+		args      []string
+		wantPath  []string
+		wantFlags map[string]string
+		wantArgs  []string
+		wantErr   error
+	}{
+		{"root only", func(cmd *Command) { cmd.Callback = cb }, []string{"a", "b"}, []string{"root only"}, map[string]string{}, []string{"a", "b"}, nil},
+		{"root flag", func(cmd *Command) {
+			cmd.Callback = cb
+			cmd.Flags.String("foo", "", "")
+		}, []string{"-foo", "bar", "a"}, []string{"root flag"}, map[string]string{"foo": "bar"}, []string{"a"}, nil},
+		{"subcommand", func(cmd *Command) {
+			cmd.Flags.Bool("v", false, "")
+			sub := cmd.SubCommand("sub", CallbackOption(cb))
+			sub.Flags.Int("n", 0, "")
+		}, []string{"-v", "sub", "-n", "3", "a"}, []string{"subcommand", "sub"}, map[string]string{"v": "true", "n": "3"}, []string{"a"}, nil},
+		{"callback consumes", func(cmd *Command) {
+			cmd.Callback = cb
+			cmd.SubCommand("sub", CallbackOption(cb))
+		}, []string{"a", "sub"}, []string{"callback consumes"}, map[string]string{}, []string{"a", "sub"}, nil},
+		{"required command", func(cmd *Command) { cmd.SubCommand("sub", CallbackOption(cb)) }, nil, []string{"required command"}, map[string]string{}, []string{}, ErrRequiredCommand},
+		{"unknown command", func(cmd *Command) { cmd.SubCommand("sub", CallbackOption(cb)) }, []string{"foo"}, []string{"unknown command"}, map[string]string{}, []string{"foo"}, ErrUnknownCommand},
+		{"bad flag", func(cmd *Command) { cmd.Callback = cb }, []string{"-foo"}, []string{"bad flag"}, map[string]string{}, nil, errors.New("flag provided but not defined: -foo")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New(test.desc, test.setup, ErrorHandlingOption(ExitOnError))
+			pi, err := cmd.ParseOnly(test.args)
+			if test.wantErr == nil && err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			} else if test.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Wanted error %v", test.wantErr)
+				} else if !errors.Is(err, test.wantErr) && err.Error() != test.wantErr.Error() {
+					t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(test.wantPath, pi.Path()) {
+				t.Errorf("Wanted path %v got %v", test.wantPath, pi.Path())
+			}
+
+			if !reflect.DeepEqual(test.wantFlags, pi.Flags()) {
+				t.Errorf("Wanted flags %v got %v", test.wantFlags, pi.Flags())
+			}
+
+			if !reflect.DeepEqual(test.wantArgs, pi.Args) {
+				t.Errorf("Wanted args %v got %v", test.wantArgs, pi.Args)
+			}
+
+			if pi.Command() != pi.Commands[len(pi.Commands)-1] {
+				t.Errorf("Wanted leaf command %v got %v", pi.Commands[len(pi.Commands)-1], pi.Command())
+			}
+		})
+	}
+}
+
+func TestParseOnlyLeavesFlags(t *testing.T) {
+	var n int
+	var color string
+	cmd := New("app", CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	cmd.Flags.IntVar(&n, "n", 1, "")
+	cmd.Flags.Var(Choice(&color, "red", "red", "blue"), "color", "")
+
+	pi, err := cmd.ParseOnly([]string{"-n", "3", "-color", "blue"})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if want := map[string]string{"n": "3", "color": "blue"}; !reflect.DeepEqual(want, pi.Flags()) {
+		t.Errorf("Wanted flags %v got %v", want, pi.Flags())
+	}
+
+	if n != 1 || color != "red" {
+		t.Errorf("Wanted flags to be unchanged got n=%d color=%q", n, color)
+	}
+
+	if cmd.Changed("n") {
+		t.Errorf("Wanted -n to be unchanged")
+	}
+
+	pi, err = cmd.ParseOnly(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(pi.Flags()) != 0 {
+		t.Errorf("Wanted no flags got %v", pi.Flags())
+	}
+
+	if _, err := cmd.ParseOnly([]string{"-color", "green"}); !errors.Is(err, ErrParse) {
+		t.Errorf("Wanted %v got %v", ErrParse, err)
+	}
+}
+
+func TestResolvePathDelimiter(t *testing.T) {
+	first, second := intSlice{}, intSlice{}
+	args := &Arguments{}
+	args.VarSlice(&first, "<first>...", Delimiter("--"))
+	args.VarSlice(&second, "<second>...")
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), ResolvePathOption())
+	cmd.SubCommand("sub", ArgumentsOption(args), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+
+	if _, err := cmd.Run([]string{"sub", "1", "2", "--", "3"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !reflect.DeepEqual([]int{1, 2}, []int(first)) || !reflect.DeepEqual([]int{3}, []int(second)) {
+		t.Errorf("Wanted [1 2] [3] got %v %v", first, second)
+	}
+}
+
+func TestResolveArgs(t *testing.T) {
+	tests := []struct {
+		desc string
+		args []string
+		want []string
+	}{
+		{"canonical", []string{"-v", "sub", "-n", "3", "a"}, []string{"-v", "sub", "-n", "3", "a"}},
+		{"flags after arguments", []string{"sub", "a", "-n", "3", "-v"}, []string{"-v", "sub", "-n", "3", "a"}},
+		{"subcommand flag first", []string{"-n", "3", "sub", "a"}, []string{"sub", "-n", "3", "a"}},
+		{"persistent flag", []string{"sub", "-config=foo", "a"}, []string{"-config=foo", "sub", "a"}},
+		{"shadowed flag", []string{"-name", "x", "sub"}, []string{"sub", "-name", "x"}},
+		{"unknown flag", []string{"-foo", "sub", "a"}, []string{"sub", "-foo", "a"}},
+		{"terminator", []string{"sub", "--", "-n", "3"}, []string{"sub", "--", "-n", "3"}},
+		{"terminator after arguments", []string{"sub", "a", "--", "b"}, []string{"sub", "a", "--", "b"}},
+		{"flags before terminator", []string{"sub", "a", "-n", "3", "--", "b"}, []string{"sub", "-n", "3", "a", "--", "b"}},
+		{"argument named like a command", []string{"sub", "a", "sub"}, []string{"sub", "a", "sub"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app")
+			cmd.Flags.Bool("v", false, "")
+			cmd.Flags.String("config", "", "")
+			cmd.Flags.String("name", "", "")
+			sub := cmd.SubCommand("sub")
+			sub.Flags.Int("n", 0, "")
+			sub.Flags.String("name", "", "")
+
+			if got := cmd.resolveArgs(test.args); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestResolvePathOption(t *testing.T) {
+	var gotN int
+	var gotArgs []string
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), ResolvePathOption())
+	v := cmd.Flags.Bool("v", false, "")
+	sub := cmd.SubCommand("sub", CallbackOption(func(name string, args ...string) ([]string, error) {
+		gotArgs = args
+		return nil, nil
+	}))
+	sub.Flags.IntVar(&gotN, "n", 0, "")
+
+	if _, err := cmd.Run([]string{"sub", "a", "-n", "3", "-v", "b"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !*v || gotN != 3 || !reflect.DeepEqual([]string{"a", "b"}, gotArgs) {
+		t.Errorf("Wanted v=true n=3 args=[a b] got v=%v n=%d args=%v", *v, gotN, gotArgs)
+	}
+
+	pi, err := cmd.ParseOnly([]string{"sub", "a", "-n", "4"})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if want := []string{"app", "sub"}; !reflect.DeepEqual(want, pi.Path()) {
+		t.Errorf("Wanted path %v got %v", want, pi.Path())
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// AppPaths are the directories an application keeps its files in
+type AppPaths struct {
+	Config string // configuration files
+	Cache  string // files that can be recreated, such as downloads
+	Data   string // data that should be kept, such as credentials
+	State  string // state that should persist between runs, such as history
+}
+
+// Paths returns the directories for appName following the conventions
+// of the operating system. On Windows these are in %AppData% and
+// %LocalAppData%, on macOS they are in ~/Library. Elsewhere, and on
+// macOS when the variables are set, the XDG_CONFIG_HOME, XDG_CACHE_HOME,
+// XDG_DATA_HOME and XDG_STATE_HOME environment variables are used,
+// defaulting to ~/.config, ~/.cache, ~/.local/share and ~/.local/state.
+// The directories are not created
+func Paths(appName string) (AppPaths, error) {
+	return paths(runtime.GOOS, appName, os.Getenv, os.UserHomeDir)
+}
+
+func paths(goos, appName string, getenv func(string) string, home func() (string, error)) (AppPaths, error) {
+	if goos == "windows" {
+		return windowsPaths(appName, getenv, home)
+	}
+
+	dir, err := home()
+	if err != nil {
+		return AppPaths{}, err
+	}
+
+	xdg := func(name string, def ...string) string {
+		if value := getenv(name); value != "" && filepath.IsAbs(value) {
+			return filepath.Join(value, appName)
+		}
+		return filepath.Join(append(append([]string{dir}, def...), appName)...)
+	}
+
+	if goos == "darwin" {
+		return AppPaths{
+			Config: xdg("XDG_CONFIG_HOME", "Library", "Application Support"),
+			Cache:  xdg("XDG_CACHE_HOME", "Library", "Caches"),
+			Data:   xdg("XDG_DATA_HOME", "Library", "Application Support"),
+			State:  xdg("XDG_STATE_HOME", "Library", "Application Support"),
+		}, nil
+	}
+
+	return AppPaths{
+		Config: xdg("XDG_CONFIG_HOME", ".config"),
+		Cache:  xdg("XDG_CACHE_HOME", ".cache"),
+		Data:   xdg("XDG_DATA_HOME", ".local", "share"),
+		State:  xdg("XDG_STATE_HOME", ".local", "state"),
+	}, nil
+}
+
+func windowsPaths(appName string, getenv func(string) string, home func() (string, error)) (AppPaths, error) {
+	roaming, local := getenv("AppData"), getenv("LocalAppData")
+	if roaming == "" || local == "" {
+		dir, err := home()
+		if err != nil {
+			return AppPaths{}, err
+		}
+
+		if roaming == "" {
+			roaming = filepath.Join(dir, "AppData", "Roaming")
+		}
+
+		if local == "" {
+			local = filepath.Join(dir, "AppData", "Local")
+		}
+	}
+
+	return AppPaths{
+		Config: filepath.Join(roaming, appName),
+		Cache:  filepath.Join(local, appName, "cache"),
+		Data:   filepath.Join(roaming, appName),
+		State:  filepath.Join(local, appName),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	home := func() (string, error) { return "/home/bob", nil }
+	tests := []struct {
+		desc string
+		goos string
+		env  map[string]string
+		want AppPaths
+	}{
+		{"linux", "linux", nil, AppPaths{
+			Config: "/home/bob/.config/app",
+			Cache:  "/home/bob/.cache/app",
+			Data:   "/home/bob/.local/share/app",
+			State:  "/home/bob/.local/state/app",
+		}},
+		{"linux xdg", "linux", map[string]string{"XDG_CONFIG_HOME": "/xdg/config", "XDG_CACHE_HOME": "relative", "XDG_DATA_HOME": "/xdg/data", "XDG_STATE_HOME": "/xdg/state"}, AppPaths{
+			Config: "/xdg/config/app",
+			Cache:  "/home/bob/.cache/app",
+			Data:   "/xdg/data/app",
+			State:  "/xdg/state/app",
+		}},
+		{"darwin", "darwin", nil, AppPaths{
+			Config: "/home/bob/Library/Application Support/app",
+			Cache:  "/home/bob/Library/Caches/app",
+			Data:   "/home/bob/Library/Application Support/app",
+			State:  "/home/bob/Library/Application Support/app",
+		}},
+		{"windows", "windows", map[string]string{"AppData": "/roaming", "LocalAppData": "/local"}, AppPaths{
+			Config: "/roaming/app",
+			Cache:  "/local/app/cache",
+			Data:   "/roaming/app",
+			State:  "/local/app",
+		}},
+		{"windows defaults", "windows", nil, AppPaths{
+			Config: "/home/bob/AppData/Roaming/app",
+			Cache:  "/home/bob/AppData/Local/app/cache",
+			Data:   "/home/bob/AppData/Roaming/app",
+			State:  "/home/bob/AppData/Local/app",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			getenv := func(name string) string { return test.env[name] }
+			got, err := paths(test.goos, "app", getenv, home)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			want := AppPaths{filepath.FromSlash(test.want.Config), filepath.FromSlash(test.want.Cache), filepath.FromSlash(test.want.Data), filepath.FromSlash(test.want.State)}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("Wanted %+v got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestPathsNoHome(t *testing.T) {
+	wantErr := errors.New("no home")
+	home := func() (string, error) { return "", wantErr }
+	getenv := func(string) string { return "" }
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		if _, err := paths(goos, "app", getenv, home); err != wantErr {
+			t.Errorf("%s: Wanted %v got %v", goos, wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+// Authorizer decides whether cmd may run given the capabilities it
+// requires
+type Authorizer func(cmd *Command, capabilities []string) bool
+
+// RequiresOption declares the capabilities that a command requires in
+// order to run
+func RequiresOption(capabilities ...string) Option {
+	return func(cmd *Command) {
+		cmd.Capabilities = append(cmd.Capabilities, capabilities...)
+	}
+}
+
+// AuthorizerOption sets the Authorizer that decides whether commands
+// requiring capabilities may run. It is usually set on the root
+// command, and subcommands created after it has been set inherit it
+func AuthorizerOption(authorizer Authorizer) Option {
+	return func(cmd *Command) { cmd.authorizer = authorizer }
+}
+
+// authorize returns a ForbiddenError if cmd requires capabilities that
+// it has not been granted. Commands that require capabilities are
+// never allowed to run when there is no Authorizer
+func (cmd *Command) authorize() error {
+	if len(cmd.Capabilities) == 0 {
+		return nil
+	}
+
+	if cmd.authorizer == nil || !cmd.authorizer(cmd, cmd.Capabilities) {
+		return &ForbiddenError{Command: cmd.Name, Capabilities: cmd.Capabilities}
+	}
+	return nil
+}
+
+// checkRun is called before the callback of the command is run, by Run
+// and by Wizard. It fails if the command is not authorized and warns
+// if the command is experimental
+func (cmd *Command) checkRun() error {
+	if err := cmd.authorize(); err != nil {
+		return err
+	}
+
+	if cmd.experimental {
+		cmd.Warnf("%s is experimental and may change or be removed", cmd.Name)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPermissions(t *testing.T) {
+	granted := map[string]bool{"read": true}
+	authorizer := func(cmd *Command, capabilities []string) bool {
+		for _, capability := range capabilities {
+			if !granted[capability] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		desc       string
+		authorizer Authorizer
+		args       []string
+		wantRan    string
+		wantErr    error
+	}{
+		{"no capabilities", authorizer, []string{"open"}, "open", nil},
+		{"granted", authorizer, []string{"show"}, "show", nil},
+		{"denied", authorizer, []string{"delete"}, "", &ForbiddenError{Command: "delete", Capabilities: []string{"read", "write"}}},
+		{"no authorizer", nil, []string{"show"}, "", &ForbiddenError{Command: "show", Capabilities: []string{"read"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ran := ""
+			callback := func(name string, args ...string) ([]string, error) {
+				ran = name
+				return nil, nil
+			}
+
+			root := New("app", ErrorHandlingOption(ContinueOnError), AuthorizerOption(test.authorizer))
+			root.SubCommand("open", CallbackOption(callback))
+			root.SubCommand("show", CallbackOption(callback), RequiresOption("read"))
+			root.SubCommand("delete", CallbackOption(callback), RequiresOption("read", "write"))
+
+			_, err := root.Run(test.args)
+			if test.wantRan != ran {
+				t.Errorf("Wanted %q to run got %q", test.wantRan, ran)
+			}
+
+			if test.wantErr == nil {
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+				return
+			}
+
+			var fe *ForbiddenError
+			if !errors.Is(err, ErrForbidden) || !errors.As(err, &fe) {
+				t.Fatalf("Wanted %v got %v", ErrForbidden, err)
+			}
+
+			if !reflect.DeepEqual(test.wantErr, fe) {
+				t.Errorf("Wanted %v got %v", test.wantErr, fe)
+			}
+		})
+	}
+}
+
+func TestForbiddenErrorString(t *testing.T) {
+	err := &ForbiddenError{Command: "delete", Capabilities: []string{"read", "write"}}
+	want := "Permission denied: delete requires read, write"
+	if got := err.Error(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
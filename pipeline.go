@@ -0,0 +1,95 @@
+package cli
+
+import "fmt"
+
+// Item is a structured value passed from one command of a pipeline to
+// the next
+type Item interface{}
+
+// pipeStage holds the items flowing into and out of the command that is
+// running as one stage of a pipeline
+type pipeStage struct {
+	input  []Item
+	output []Item
+	last   bool
+}
+
+// Emit passes item to the next command of the pipeline. When the
+// command is not running in a pipeline, or is the last command of the
+// pipeline, the item is printed to the command's Stdout instead
+func (cmd *Command) Emit(item Item) {
+	if cmd.pipe != nil && !cmd.pipe.last {
+		cmd.pipe.output = append(cmd.pipe.output, item)
+		return
+	}
+	fmt.Fprintln(cmd.Stdout(), item)
+}
+
+// Input returns the items emitted by the previous command of the
+// pipeline. It returns nil for the first command of a pipeline and
+// when the command is not running in a pipeline
+func (cmd *Command) Input() []Item {
+	if cmd.pipe == nil {
+		return nil
+	}
+	return cmd.pipe.input
+}
+
+// PipelineOption makes Run treat separator as a pipe between
+// invocations of the command, so "app list | app filter | app delete"
+// can be given as the single invocation "app list | filter | delete"
+// (with the separator quoted so the shell does not interpret it). See
+// RunPipeline
+func PipelineOption(separator string) Option {
+	return func(cmd *Command) { cmd.pipeSeparator = separator }
+}
+
+// RunPipeline runs the command once for each of stages, in order. The
+// items that each stage emits, see Emit, are buffered until the stage
+// has finished and are then available to the next stage from Input.
+// Items emitted by the last stage are printed. The flags of the command
+// and its subcommands are reset to their defaults before each stage
+// after the first, so flags given to one stage do not carry over to the
+// next. The pipeline stops at the first stage that fails and returns its
+// error, which is handled according to the error handling of the
+// command. The arguments left over by the last stage are returned
+func (cmd *Command) RunPipeline(stages ...[]string) (args []string, err error) {
+	defer cmd.setPipe(nil)
+
+	var input []Item
+	for i, stage := range stages {
+		if i > 0 {
+			cmd.resetFlags()
+		}
+
+		pipe := &pipeStage{input: input, last: i == len(stages)-1}
+		cmd.setPipe(pipe)
+		if args, err = cmd.Run(stage); err != nil {
+			break
+		}
+		input = pipe.output
+	}
+	return args, err
+}
+
+// setPipe sets the pipeline stage of cmd and all of its subcommands
+func (cmd *Command) setPipe(pipe *pipeStage) {
+	cmd.pipe = pipe
+	cmd.load()
+	for _, subCmd := range cmd.SubCommands {
+		subCmd.setPipe(pipe)
+	}
+}
+
+// splitPipeline splits args into stages at each separator
+func splitPipeline(args []string, separator string) [][]string {
+	stages := [][]string{{}}
+	for _, arg := range args {
+		if arg == separator {
+			stages = append(stages, []string{})
+			continue
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], arg)
+	}
+	return stages
+}
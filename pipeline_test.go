@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newPipelineCommand(stdout *strings.Builder, deleted *[]Item) *Command {
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout), PipelineOption("|"))
+	list := cmd.SubCommand("list")
+	all := list.Flags.Bool("all", false, "include hidden items")
+	list.Callback = Callback(func() {
+		if list.Input() != nil {
+			list.Emit("input")
+		}
+
+		for _, name := range []string{"alpha", "beta", "gamma"} {
+			list.Emit(name)
+		}
+
+		if *all {
+			list.Emit("hidden")
+		}
+	})
+
+	filter := cmd.SubCommand("filter")
+	filter.Callback = Callback(func(prefix string) {
+		for _, item := range filter.Input() {
+			if strings.HasPrefix(item.(string), prefix) {
+				filter.Emit(item)
+			}
+		}
+	})
+
+	del := cmd.SubCommand("delete")
+	del.Callback = Callback(func() {
+		for _, item := range del.Input() {
+			*deleted = append(*deleted, item)
+			del.Emit("deleted " + item.(string))
+		}
+	})
+
+	cmd.SubCommand("fail", FuncOption(func() error { return errors.New("failed") }))
+	return cmd
+}
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		desc        string
+		args        []string
+		wantDeleted []Item
+		wantOutput  string
+		wantErr     bool
+	}{
+		{"single command", []string{"list"}, nil, "alpha\nbeta\ngamma\n", false},
+		{"pipeline", []string{"list", "|", "filter", "g", "|", "delete"}, []Item{"gamma"}, "deleted gamma\n", false},
+		{"first stage fails", []string{"fail", "|", "delete"}, nil, "", true},
+		{"no input", []string{"delete"}, nil, "", false},
+		{"flags reset", []string{"list", "-all", "|", "filter", "", "|", "list"}, nil, "input\nalpha\nbeta\ngamma\n", false},
+		{"first stage input", []string{"list", "|", "delete"}, []Item{"alpha", "beta", "gamma"}, "deleted alpha\ndeleted beta\ndeleted gamma\n", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout := &strings.Builder{}
+			var deleted []Item
+			cmd := newPipelineCommand(stdout, &deleted)
+
+			_, err := cmd.Run(test.args)
+			if test.wantErr && err == nil {
+				t.Errorf("Expected an error")
+			} else if !test.wantErr && err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(test.wantDeleted, deleted) {
+				t.Errorf("Wanted deleted %v got %v", test.wantDeleted, deleted)
+			}
+
+			if got := stdout.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+
+			if cmd.pipe != nil {
+				t.Errorf("Expected the pipeline to be cleared")
+			}
+		})
+	}
+}
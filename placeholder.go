@@ -0,0 +1,52 @@
+package cli
+
+// TypeNames replaces the type names displayed for flags and arguments
+// in usage output, such as "int", "duration" or "value", with friendlier
+// placeholders. For instance:
+//
+//	cli.TypeNames["int"] = "<n>"
+//	cli.TypeNames["duration"] = "<duration>"
+//
+// Flags whose usage names a placeholder in back quotes are unaffected
+var TypeNames = map[string]string{}
+
+// placeholderHinter is implemented by values that choose how they are
+// displayed in usage output
+type placeholderHinter interface {
+	Placeholder() string
+}
+
+type placeholderValue struct {
+	Value
+	name string
+}
+
+func (pv *placeholderValue) Placeholder() string { return pv.name }
+func (pv *placeholderValue) Unwrap() Value       { return pv.Value }
+
+// WithPlaceholder returns a Value that is displayed as name in usage
+// output, for instance:
+//
+//	cmd.Flags.Var(cli.WithPlaceholder(&myValue, "<file>"), "config", "config file")
+//
+// Values can also choose their own placeholder by implementing a
+// Placeholder() string method
+func WithPlaceholder(value Value, name string) Value {
+	return &placeholderValue{value, name}
+}
+
+// typeName returns the placeholder displayed in usage output for value,
+// given the name derived from its type. The second return value is
+// false when there is no replacement for name
+func typeName(value interface{}, name string) (string, bool) {
+	if ph, ok := value.(placeholderHinter); ok {
+		return ph.Placeholder(), true
+	}
+
+	if name != "" {
+		if placeholder, found := TypeNames[name]; found {
+			return placeholder, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fileValue struct{ stringValue }
+
+func (fileValue) Placeholder() string { return "<file>" }
+
+func TestTypeNames(t *testing.T) {
+	TypeNames["int"] = "<n>"
+	TypeNames["duration"] = "<duration>"
+	defer func() { TypeNames = map[string]string{} }()
+
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	flags.Int("count", 0, "number of `times`")
+	flags.Int("n", 0, "number of items")
+	flags.Duration("timeout", 0, "how long to wait")
+	flags.Var(&fileValue{}, "config", "config file")
+	flags.Var(WithPlaceholder(new(stringValue), "<host>"), "server", "server to use")
+
+	want := "  -config   <file>      config file\n  -count    times       number of times\n  -n        <n>         number of items\n  -server   <host>      server to use\n  -timeout  <duration>  how long to wait\n"
+	if got := flagDefaults(flags); want != got {
+		t.Errorf("Wanted\n%q\ngot\n%q", want, got)
+	}
+
+	tests := []struct {
+		desc  string
+		input interface{}
+		want  string
+	}{
+		{"mapped", int(0), "<n>"},
+		{"mapped duration", time.Duration(0), "<duration>"},
+		{"not mapped", "", "<string>"},
+		{"placeholder method", fileValue{}, "<file>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := placeholder(reflect.TypeOf(test.input)); test.want != got {
+				t.Errorf("Wanted placeholder %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// PluginHandshakeEnv is set, to the host's PluginProtocolVersion, in the
+// environment of an exec plugin that is started by LoadPlugin to
+// describe itself
+const PluginHandshakeEnv = "CLI_PLUGIN_HANDSHAKE"
+
+// PluginProtocolVersion is the version of the handshake between a host
+// program and its exec plugins. It changes whenever PluginInfo changes
+// in a way that older hosts or plugins can not understand
+const PluginProtocolVersion = 1
+
+// PluginInfo is written, as JSON, by an exec plugin in response to a
+// handshake
+type PluginInfo struct {
+	Version int           `json:"version"`
+	Command CommandSchema `json:"command"`
+}
+
+// PluginHandshake answers the handshake of a host program that loads
+// the running program as an exec plugin. When the program was started
+// for a handshake, PluginHandshake writes a PluginInfo describing root
+// to root's Stdout and returns true, in which case the program should
+// exit without running root:
+//
+//	if cli.PluginHandshake(root) {
+//		return
+//	}
+//
+// Otherwise it does nothing and returns false. A program is started for
+// a handshake when PluginHandshakeEnv holds a protocol version. If the
+// host's version is not PluginProtocolVersion, an error is printed to
+// root's Output instead of the PluginInfo
+func PluginHandshake(root *Command) bool {
+	version, err := strconv.Atoi(os.Getenv(PluginHandshakeEnv))
+	if err != nil {
+		return false
+	} else if version != PluginProtocolVersion {
+		root.Errorf("plugin handshake: %v: host uses version %d, wanted %d", ErrPluginVersion, version, PluginProtocolVersion)
+		return true
+	}
+
+	info := PluginInfo{Version: PluginProtocolVersion, Command: root.Schema()}
+	if err := json.NewEncoder(root.Stdout()).Encode(info); err != nil {
+		root.Errorf("plugin handshake: %v", err)
+	}
+	return true
+}
+
+// LoadPlugin runs the program name with args as an exec plugin, which
+// must answer the handshake with PluginHandshake, and returns a command
+// built from the PluginInfo it responds with. The command has the
+// description, usage, flags and subcommands of the plugin's command
+// hierarchy, so they are included in usage output, completion and
+// documentation once the command is added to a hierarchy with Merge.
+// Running the command, or one of its subcommands, runs the plugin with
+// the same command line using Exec. The flags are reset on each run, so
+// they are not forwarded again by the next one. ErrPluginVersion is
+// returned if the plugin's protocol version is not
+// PluginProtocolVersion. Anything the plugin writes to stderr during the
+// handshake is included in the returned error
+func LoadPlugin(ctx context.Context, name string, args ...string) (*Command, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	proc := exec.CommandContext(ctx, name, args...)
+	proc.Env = append(os.Environ(), fmt.Sprintf("%s=%d", PluginHandshakeEnv, PluginProtocolVersion))
+	proc.Stdout = stdout
+	proc.Stderr = stderr
+	if err := proc.Run(); err != nil {
+		return nil, pluginError(name, err, stderr)
+	}
+
+	info := PluginInfo{}
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		return nil, pluginError(name, fmt.Errorf("invalid handshake: %w", err), stderr)
+	}
+
+	if info.Version != PluginProtocolVersion {
+		return nil, fmt.Errorf("%w: %s uses version %d, wanted %d", ErrPluginVersion, name, info.Version, PluginProtocolVersion)
+	}
+
+	cmd := New(info.Command.Name)
+	buildPlugin(cmd, info.Command, []*Command{cmd}, name, args)
+	return cmd, nil
+}
+
+// buildPlugin adds the description, flags and subcommands of schema to
+// cmd, which is the last command in chain
+func buildPlugin(cmd *Command, schema CommandSchema, chain []*Command, name string, prefix []string) {
+	cmd.Description = schema.Description
+	cmd.UsageStr = schema.Usage
+	for _, param := range schema.Params {
+		if !param.Flag {
+			continue
+		}
+
+		pf := &pluginFlag{value: param.Default, kind: param.Kind}
+		if pf.IsBoolFlag() && pf.value == "false" {
+			pf.value = ""
+		}
+		cmd.Flags.Var(pf, param.Name, param.Description)
+	}
+
+	for _, sub := range schema.SubCommands {
+		subCmd := cmd.SubCommand(sub.Name)
+		buildPlugin(subCmd, sub, append(chain[:len(chain):len(chain)], subCmd), name, prefix)
+	}
+
+	if len(schema.SubCommands) == 0 {
+		cmd.Callback = func(_ string, args ...string) ([]string, error) {
+			line := pluginCommandLine(chain, prefix, args)
+			for _, c := range chain {
+				c.resetFlagSet()
+			}
+			return nil, Exec(context.Background(), cmd, name, line...)
+		}
+	}
+}
+
+// pluginCommandLine rebuilds the command line of a plugin from the
+// names and the given flags of the commands in chain. The arguments are
+// given after "--" so that the plugin does not parse them as flags
+func pluginCommandLine(chain []*Command, prefix, args []string) []string {
+	line := append([]string{}, prefix...)
+	for i, cmd := range chain {
+		if i > 0 {
+			line = append(line, cmd.Name)
+		}
+
+		cmd.Flags.Visit(func(f *flag.Flag) {
+			line = append(line, fmt.Sprintf("-%s=%s", f.Name, f.Value.String()))
+		})
+	}
+
+	if len(args) == 0 {
+		return line
+	}
+	return append(append(line, "--"), args...)
+}
+
+// pluginError prefixes err with the name of the plugin and appends the
+// plugin's error output, if there was any
+func pluginError(name string, err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%s: %w: %s", name, err, msg)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// pluginFlag is the value of a flag of an exec plugin. The value is
+// passed to the plugin unchanged, so that the plugin parses it
+type pluginFlag struct {
+	value string
+	kind  string
+}
+
+func (pf *pluginFlag) String() string     { return pf.value }
+func (pf *pluginFlag) Set(s string) error { pf.value = s; return nil }
+func (pf *pluginFlag) IsBoolFlag() bool   { return pf.kind == "bool" }
+
+func (pf *pluginFlag) Placeholder() string {
+	if pf.IsBoolFlag() {
+		return ""
+	}
+	return pf.kind
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadPlugin(t *testing.T) {
+	os.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	defer os.Unsetenv("GO_WANT_HELPER_PROCESS")
+
+	plugin, err := LoadPlugin(context.Background(), os.Args[0], "-test.run=TestPluginHelperProcess", "--", "plugin")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	stdout := &strings.Builder{}
+	root := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout), OutputOption(&strings.Builder{}))
+	plugin.Name = "deploy"
+	if err := root.Merge(plugin); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if plugin.Description != "Deploy things" {
+		t.Errorf("Wanted description %q got %q", "Deploy things", plugin.Description)
+	}
+
+	wantUsage := "  -region  string  region to deploy to (default local)\n  -v               verbose output\n"
+	if got := flagDefaults(&plugin.Flags); wantUsage != got {
+		t.Errorf("Wanted usage %q got %q", wantUsage, got)
+	}
+
+	if _, err := root.Run([]string{"deploy", "-v", "-region", "eu", "service", "-replicas", "3", "web"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "region=eu v=true replicas=3 args=[web]\n"
+	if stdout.String() != want {
+		t.Errorf("Wanted %q got %q", want, stdout.String())
+	}
+
+	stdout.Reset()
+	if _, err := root.Run([]string{"deploy", "service", "--", "-web"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want = "region=local v=false replicas=1 args=[-web]\n"
+	if stdout.String() != want {
+		t.Errorf("Wanted %q got %q", want, stdout.String())
+	}
+
+	_, err = LoadPlugin(context.Background(), os.Args[0], "-test.run=TestPluginHelperProcess", "--", "old")
+	if !errors.Is(err, ErrPluginVersion) {
+		t.Errorf("Wanted error %v got %v", ErrPluginVersion, err)
+	}
+
+	_, err = LoadPlugin(context.Background(), os.Args[0], "-test.run=TestPluginHelperProcess", "--", "fail")
+	if err == nil || !strings.HasSuffix(err.Error(), ": plugin is broken") {
+		t.Errorf("Wanted error to include the plugin's stderr got %v", err)
+	}
+}
+
+func TestPluginHandshake(t *testing.T) {
+	tests := []struct {
+		desc       string
+		env        string
+		want       bool
+		wantStdout string
+		wantOutput string
+	}{
+		{"no handshake", "", false, "", ""},
+		{"not a version", "true", false, "", ""},
+		{"handshake", "1", true, `{"version":1,`, ""},
+		{"wrong version", "2", true, "", "error: plugin handshake: Plugin protocol version mismatch: host uses version 2, wanted 1\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			os.Setenv(PluginHandshakeEnv, test.env)
+			defer os.Unsetenv(PluginHandshakeEnv)
+
+			stdout, output := &strings.Builder{}, &strings.Builder{}
+			if got := PluginHandshake(New("app", StdoutOption(stdout), OutputOption(output))); test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+
+			if !strings.HasPrefix(stdout.String(), test.wantStdout) || (test.wantStdout == "" && stdout.Len() > 0) {
+				t.Errorf("Wanted stdout %q got %q", test.wantStdout, stdout.String())
+			}
+
+			if test.wantOutput != output.String() {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, output.String())
+			}
+		})
+	}
+}
+
+func TestPluginHelperProcess(*testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	switch args[0] {
+	case "old":
+		fmt.Fprintf(os.Stdout, `{"version":0,"command":{"name":"old"}}`)
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "plugin is broken")
+		os.Exit(1)
+	}
+
+	region, verbose, replicas := "", false, 0
+	root := New("plugin", DescOption("Deploy things"))
+	root.Flags.StringVar(&region, "region", "local", "region to deploy to")
+	root.Flags.BoolVar(&verbose, "v", false, "verbose output")
+	service := root.SubCommand("service", DescOption("Deploy a service"), CallbackOption(func(name string, args ...string) ([]string, error) {
+		fmt.Fprintf(os.Stdout, "region=%s v=%v replicas=%d args=%v\n", region, verbose, replicas, args)
+		return nil, nil
+	}))
+	service.Flags.IntVar(&replicas, "replicas", 1, "number of replicas")
+
+	if !PluginHandshake(root) {
+		root.Run(args[1:])
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type registeredCommand struct {
+	path    string
+	factory func() *Command
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []registeredCommand
+)
+
+// RegisterCommandFactory registers factory to build the command at
+// path, which is the space separated names of the command and its
+// parents below the root command, such as "license" or "admin audit".
+// It is meant to be called from the init functions of optional
+// packages, so that the commands a program has depend only on which
+// packages it imports, for instance with build tags for each edition
+// of a product. Registered commands are added to a hierarchy with
+// AddRegisteredCommands
+func RegisterCommandFactory(path string, factory func() *Command) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registeredCommand{strings.Join(strings.Fields(path), " "), factory})
+}
+
+// AddRegisteredCommands adds the commands registered with
+// RegisterCommandFactory to the hierarchy rooted at cmd. They are added
+// as lazy commands (see LazyCommand) so factories are only called when
+// their command is needed. Commands are added in order of their paths,
+// so a registered command can be the parent of another, in which case
+// the command is added to its parent once the parent has been built. An
+// error is returned, and no more commands are added, if the parent of a
+// command does not exist or if a command with the same name already
+// does. Duplicates of the commands that a lazy parent's factory builds
+// are only reported by Validate
+func (cmd *Command) AddRegisteredCommands() error {
+	registryMu.Lock()
+	commands := append([]registeredCommand{}, registry...)
+	registryMu.Unlock()
+
+	sort.SliceStable(commands, func(i, j int) bool { return commands[i].path < commands[j].path })
+	for _, rc := range commands {
+		names := strings.Fields(rc.path)
+		if len(names) == 0 {
+			return fmt.Errorf("registered command has no name")
+		}
+
+		parent := cmd
+		for _, name := range names[:len(names)-1] {
+			next := subCommands(parent.SubCommands).get(name)
+			if next == nil {
+				return fmt.Errorf("%s: %w %q", rc.path, ErrUnknownCommand, name)
+			}
+			parent = next
+		}
+
+		name := names[len(names)-1]
+		if subCommands(parent.SubCommands).get(name) != nil {
+			return fmt.Errorf("%s: %w", rc.path, ErrDuplicateCommand)
+		}
+		parent.LazyCommand(name, rc.factory)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddRegisteredCommands(t *testing.T) {
+	defer func(prev []registeredCommand) { registry = prev }(registry)
+
+	tests := []struct {
+		desc     string
+		paths    []string
+		run      []string
+		wantRan  string
+		wantErr  error
+		wantLazy int
+		wantCall int
+	}{
+		{"top level", []string{"license"}, []string{"license"}, "license", nil, 0, 1},
+		{"nested", []string{"admin audit", "admin"}, []string{"admin", "audit"}, "audit", nil, 0, 2},
+		{"nested twice", []string{"admin audit log", "admin audit", "admin"}, []string{"admin", "audit", "log"}, "log", nil, 0, 3},
+		{"existing parent", []string{"remote prune"}, []string{"remote", "prune"}, "prune", nil, 0, 1},
+		{"missing parent", []string{"missing prune"}, nil, "", ErrUnknownCommand, 0, 0},
+		{"duplicate", []string{"remote"}, nil, "", ErrDuplicateCommand, 0, 0},
+		{"registered twice", []string{"license", "license"}, nil, "", ErrDuplicateCommand, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			registry = nil
+			ran, calls := "", 0
+			for _, path := range test.paths {
+				fields := strings.Fields(path)
+				name := fields[len(fields)-1]
+				RegisterCommandFactory(path, func() *Command {
+					calls++
+					return New(name, CallbackOption(func(_ string, args ...string) ([]string, error) {
+						ran = name
+						return args, nil
+					}))
+				})
+			}
+
+			root := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}))
+			root.SubCommand("remote")
+			err := root.AddRegisteredCommands()
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			// no factories are called before the commands are run, not
+			// even those of the parents of registered commands
+			if test.wantLazy != calls {
+				t.Errorf("Wanted %d factory calls before running got %d", test.wantLazy, calls)
+			}
+
+			if _, err := root.Run(test.run); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if test.wantRan != ran {
+				t.Errorf("Wanted %q to run got %q", test.wantRan, ran)
+			}
+
+			if test.wantCall != calls {
+				t.Errorf("Wanted %d factory calls got %d", test.wantCall, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import "flag"
+
+// replay runs args with the command hierarchy rooted at root from within
+// a callback of the hierarchy, such as the "redo" and "bench" commands.
+// The flags of every command are reset to their defaults before and
+// after the run, and the run is not recorded by AuditOption,
+// HistoryOption, StatsOption or StickyFlagsOption, since the command
+// that called replay is recorded instead
+func (root *Command) replay(args []string) ([]string, error) {
+	root.resetFlags()
+	root.replaying = true
+	defer func() {
+		root.replaying = false
+		root.result = nil
+		root.resetFlags()
+	}()
+	return root.Run(args)
+}
+
+// resetFlags sets the flags of cmd, and of its subcommands, that were
+// given on the command line back to their defaults so that the flags
+// can be parsed again as though they were never set
+func (cmd *Command) resetFlags() {
+	cmd.resetFlagSet()
+	for _, subCmd := range cmd.SubCommands {
+		subCmd.resetFlags()
+	}
+}
+
+// resetFlagSet resets the flags of cmd, but not those of its
+// subcommands, see resetFlags
+func (cmd *Command) resetFlagSet() {
+	set := make(map[string]bool)
+	cmd.Flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	flags := []*flag.Flag{}
+	cmd.Flags.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+
+	name, handling := cmd.Flags.Name(), cmd.Flags.ErrorHandling()
+	usage, output := cmd.Flags.Usage, cmd.Flags.Output()
+	cmd.Flags = flag.FlagSet{Usage: usage}
+	cmd.Flags.Init(name, handling)
+	cmd.Flags.SetOutput(output)
+	for _, f := range flags {
+		if set[f.Name] && f.Value.String() != f.DefValue {
+			f.Value.Set(f.DefValue)
+		}
+		cmd.Flags.Var(f.Value, f.Name, f.Usage)
+		cmd.Flags.Lookup(f.Name).DefValue = f.DefValue
+	}
+}
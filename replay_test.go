@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	audit := &strings.Builder{}
+	got := []string{}
+	shout := false
+	root := New("app", ErrorHandlingOption(ContinueOnError), AuditOption(audit))
+	greet := root.SubCommand("greet", FuncOption(func(name string) {
+		if shout {
+			name = strings.ToUpper(name)
+		}
+		got = append(got, name)
+	}))
+	greet.Flags.BoolVar(&shout, "shout", false, "")
+
+	if _, err := root.Run([]string{"greet", "-shout", "alice"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := root.replay([]string{"greet", "bob"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if want := []string{"ALICE", "bob"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %v got %v", want, got)
+	}
+
+	if shout || greet.Changed("shout") {
+		t.Errorf("Wanted -shout to be reset")
+	}
+
+	if lines := strings.Count(audit.String(), "\n"); lines != 1 {
+		t.Errorf("Wanted the replay not to be audited got %q", audit.String())
+	}
+}
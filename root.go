@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultRootMarkers are used by FindRoot when no markers are given
+var DefaultRootMarkers = []string{".git", "go.mod"}
+
+// FindRoot walks up from the current working directory looking for a
+// directory that contains one of the given markers, such as ".git" or
+// "go.mod", and returns it. If no markers are given, DefaultRootMarkers
+// are used. ErrNoRoot is returned if no directory contains a marker
+func FindRoot(markers ...string) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return findRoot(dir, markers)
+}
+
+func findRoot(dir string, markers []string) (string, error) {
+	if len(markers) == 0 {
+		markers = DefaultRootMarkers
+	}
+
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNoRoot
+		}
+		dir = parent
+	}
+}
+
+// ChdirRootOption changes the working directory to the project root,
+// found with FindRoot(markers...), each time the command is run and
+// before its flags are parsed. If the root can not be found, the command
+// fails with ErrNoRoot
+func ChdirRootOption(markers ...string) Option {
+	return func(cmd *Command) {
+		if markers == nil {
+			markers = []string{}
+		}
+		cmd.rootMarkers = markers
+	}
+}
+
+// chdirRoot changes to the project root if the command has been
+// configured with ChdirRootOption
+func (cmd *Command) chdirRoot() error {
+	if cmd.rootMarkers == nil {
+		return nil
+	}
+
+	root, err := FindRoot(cmd.rootMarkers...)
+	if err == nil {
+		err = os.Chdir(root)
+	}
+	return err
+}
+
+// ChdirOption changes the working directory to dir after the command's
+// flags are parsed and before its callback and subcommands are run. The
+// previous working directory is restored once the command finishes.
+// The directory, as an absolute path, is available from Dir while the
+// command runs
+func ChdirOption(dir string) Option {
+	return func(cmd *Command) { cmd.chdir = dir }
+}
+
+// ChdirFlagOption adds a -C flag to the command that changes the
+// working directory in the same way as ChdirOption
+func ChdirFlagOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.StringVar(&cmd.chdir, "C", cmd.chdir, "change to `dir` before running")
+	}
+}
+
+// Dir returns the absolute path of the directory the command changed
+// to with ChdirOption or ChdirFlagOption, or an empty string if the
+// command did not change directory
+func (cmd *Command) Dir() string {
+	return cmd.dir
+}
+
+// enterDir changes to the command's directory, if one was given
+func (cmd *Command) enterDir() error {
+	cmd.dir = ""
+	if cmd.chdir == "" {
+		return nil
+	}
+
+	prev, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(cmd.chdir)
+	if err == nil {
+		err = os.Chdir(dir)
+	}
+
+	if err != nil {
+		return err
+	}
+	cmd.dir, cmd.prevDir = dir, prev
+	return nil
+}
+
+// leaveDir restores the working directory from before enterDir
+func (cmd *Command) leaveDir() error {
+	if cmd.prevDir == "" {
+		return nil
+	}
+
+	prev := cmd.prevDir
+	cmd.prevDir = ""
+	return os.Chdir(prev)
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "root")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	nested := filepath.Join(dir, "project", "a", "b")
+	os.MkdirAll(nested, 0755)
+	os.Mkdir(filepath.Join(dir, "project", ".git"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "project", "a", "marker"), nil, 0644)
+
+	tests := []struct {
+		desc    string
+		markers []string
+		want    string
+		wantErr error
+	}{
+		{"default markers", nil, filepath.Join(dir, "project"), nil},
+		{"custom marker", []string{"marker"}, filepath.Join(dir, "project", "a"), nil},
+		{"not found", []string{"missing-marker"}, "", ErrNoRoot},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := findRoot(nested, test.markers)
+			if test.wantErr != err {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestChdirRootOption(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	dir, err := ioutil.TempDir("", "root")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+
+	nested := filepath.Join(dir, "a", "b")
+	os.MkdirAll(nested, 0755)
+	ioutil.WriteFile(filepath.Join(dir, "a", "marker"), nil, 0644)
+	os.Chdir(nested)
+
+	got := ""
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), ChdirRootOption("marker"), CallbackOption(func(string, ...string) ([]string, error) {
+		got, _ = os.Getwd()
+		return nil, nil
+	}))
+
+	if _, err := cmd.Run(nil); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if want := filepath.Join(dir, "a"); want != got {
+		t.Errorf("Wanted working directory %q got %q", want, got)
+	}
+
+	cmd = New("app", ErrorHandlingOption(ContinueOnError), ChdirRootOption("missing-marker"))
+	if _, err := cmd.Run(nil); !errors.Is(err, ErrNoRoot) {
+		t.Errorf("Wanted %v got %v", ErrNoRoot, err)
+	}
+}
+
+func TestChdirOption(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	dir, err := ioutil.TempDir("", "chdir")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.Chdir(dir)
+
+	tests := []struct {
+		desc    string
+		option  Option
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"no change", func(*Command) {}, nil, "", false},
+		{"option", ChdirOption("sub"), nil, filepath.Join(dir, "sub"), false},
+		{"flag", ChdirFlagOption(), []string{"-C", "sub"}, filepath.Join(dir, "sub"), false},
+		{"flag not given", ChdirFlagOption(), nil, "", false},
+		{"missing", ChdirOption("missing"), nil, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			gotWd, gotDir := "", ""
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), test.option)
+			cmd.Callback = func(string, ...string) ([]string, error) {
+				gotWd, _ = os.Getwd()
+				gotDir = cmd.Dir()
+				return nil, nil
+			}
+
+			_, err := cmd.Run(test.args)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.want != gotDir {
+				t.Errorf("Wanted Dir %q got %q", test.want, gotDir)
+			}
+
+			if test.want != "" && test.want != gotWd {
+				t.Errorf("Wanted working directory %q got %q", test.want, gotWd)
+			}
+
+			if after, _ := os.Getwd(); after != dir {
+				t.Errorf("Wanted working directory to be restored to %q got %q", dir, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// RPCParam describes a parameter of an RPC method
+type RPCParam struct {
+	Name string `json:"name"`
+
+	// Type is derived from the flag or argument's Value type, such as
+	// "int", "string" or "duration"
+	Type string `json:"type"`
+
+	// Flag is true if the parameter is a flag rather than a positional
+	// argument
+	Flag bool `json:"flag"`
+
+	// Array is true if the parameter accepts a list of values
+	Array bool `json:"array"`
+}
+
+// RPCMethod describes a leaf command as an RPC method
+type RPCMethod struct {
+	// Name is the command path below the root, joined with "."
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Params      []RPCParam `json:"params"`
+
+	path []*Command
+}
+
+// RPCResult is the result of calling an RPC method
+type RPCResult struct {
+	ExitCode int         `json:"exitCode"`
+	Error    string      `json:"error,omitempty"`
+	Stdout   string      `json:"stdout"`
+	Stderr   string      `json:"stderr"`
+	Result   interface{} `json:"result,omitempty"`
+}
+
+// RPCMethods generates an RPC method for each leaf command in the
+// hierarchy rooted at root. The flags of each command in the path and
+// the positional arguments of the leaf become the method's parameters.
+// Positional arguments are named after their descriptions, for instance
+// "<host>" becomes "host". When the positional arguments of a command
+// are not known (the callback was not set with FuncOption) the method
+// accepts an "args" array instead
+func RPCMethods(root *Command) []RPCMethod {
+	methods := []RPCMethod{}
+	root.rpcMethods(nil, &methods)
+	return methods
+}
+
+func (cmd *Command) rpcMethods(path []*Command, methods *[]RPCMethod) {
+	path = append(path[:len(path):len(path)], cmd)
+	if len(cmd.SubCommands) > 0 {
+		cmd.load()
+		for _, subCmd := range subCommands(cmd.SubCommands).sorted() {
+			subCmd.rpcMethods(path, methods)
+		}
+		return
+	}
+
+	if cmd.Callback == nil || len(path) < 2 {
+		return
+	}
+
+	method := RPCMethod{Description: cmd.Description, path: path}
+	names := []string{}
+	for _, c := range path {
+		c.Flags.VisitAll(func(f *flag.Flag) {
+			method.Params = append(method.Params, RPCParam{Name: f.Name, Type: valueType(f.Value), Flag: true})
+		})
+		names = append(names, c.Name)
+	}
+	method.Name = strings.Join(names[1:], ".")
+
+	if cmd.arguments == nil {
+		method.Params = append(method.Params, RPCParam{Name: "args", Type: "string", Array: true})
+	} else {
+		for i, arg := range cmd.arguments.args {
+			_, array := arg.value.(SliceValue)
+			method.Params = append(method.Params, RPCParam{Name: argumentName(arg.desc, i), Type: valueType(arg.value), Array: array})
+		}
+	}
+	*methods = append(*methods, method)
+}
+
+// valueType returns the name of the type of a flag or argument value,
+// looking through wrappers such as those returned by EnvDefault or
+// Secret
+func valueType(value interface{}) string {
+	for {
+		wrapper, ok := value.(valueWrapper)
+		if !ok || wrapper.Unwrap() == nil {
+			break
+		}
+		value = wrapper.Unwrap()
+	}
+
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(t.Name(), "Value"))
+	if name == "" {
+		name = "value"
+	}
+	return name
+}
+
+// argumentName derives a parameter name from an argument description
+func argumentName(desc string, i int) string {
+	name := strings.Trim(desc, "<>[]. ")
+	name = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if name == "" || strings.ContainsAny(name, "<>[]") {
+		name = fmt.Sprintf("arg%d", i)
+	}
+	return name
+}
+
+// param returns the named parameter of the method
+func (method RPCMethod) param(name string) (RPCParam, bool) {
+	for _, param := range method.Params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return RPCParam{}, false
+}
+
+// commandLine builds the command line, starting below the root command,
+// that runs the method with the given parameter values
+func (method RPCMethod) commandLine(params map[string][]string) ([]string, error) {
+	for name, values := range params {
+		if param, found := method.param(name); !found {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		} else if !param.Array && len(values) != 1 {
+			return nil, fmt.Errorf("parameter %q: expected a single value", name)
+		}
+	}
+
+	args := []string{}
+	for i, cmd := range method.path {
+		if i > 0 {
+			args = append(args, cmd.Name)
+		}
+
+		cmd.Flags.VisitAll(func(f *flag.Flag) {
+			if values, found := params[f.Name]; found {
+				args = append(args, fmt.Sprintf("-%s=%s", f.Name, values[0]))
+			}
+		})
+	}
+
+	args = append(args, "--")
+	for _, param := range method.Params {
+		if param.Flag {
+			continue
+		}
+
+		values, found := params[param.Name]
+		if !found && !param.Array {
+			return nil, fmt.Errorf("missing parameter %q", param.Name)
+		}
+		args = append(args, values...)
+	}
+	return args, nil
+}
+
+// run runs the command line built by commandLine writing the output
+// of the command to stdout and stderr. The flags of the commands in the
+// method's path are reset before and after the run, so flags given in
+// one call do not carry over to the next
+func (method RPCMethod) run(args []string, stdout, stderr io.Writer) (result interface{}, err error) {
+	root := method.path[0]
+	restore := root.redirect(stdout, stderr)
+	defer restore()
+
+	method.resetFlags()
+	defer method.resetFlags()
+	_, err = root.Run(args)
+	return root.Result(), err
+}
+
+// resetFlags resets the flags of the commands in the method's path
+func (method RPCMethod) resetFlags() {
+	for _, cmd := range method.path {
+		cmd.resetFlagSet()
+	}
+}
+
+// call runs the method with the given JSON parameters
+func (method RPCMethod) call(raw map[string]json.RawMessage) (*RPCResult, error) {
+	params := make(map[string][]string)
+	for name, value := range raw {
+		param, _ := method.param(name)
+		values, err := rpcStrings(value, param.Array)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", name, err)
+		}
+		params[name] = values
+	}
+
+	args, err := method.commandLine(params)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	value, err := method.run(args, stdout, stderr)
+	result := &RPCResult{Result: value, Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		result.Error = err.Error()
+		result.ExitCode = exitCode(err)
+	}
+	return result, nil
+}
+
+// rpcStrings converts a JSON value to command line strings. Arrays are
+// only accepted when array is true
+func rpcStrings(raw json.RawMessage, array bool) ([]string, error) {
+	if array {
+		var values []json.RawMessage
+		if err := json.Unmarshal(raw, &values); err == nil {
+			strs := []string{}
+			for _, value := range values {
+				s, err := rpcStrings(value, false)
+				if err != nil {
+					return nil, err
+				}
+				strs = append(strs, s...)
+			}
+			return strs, nil
+		}
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case json.Number:
+		return []string{v.String()}, nil
+	case bool:
+		return []string{fmt.Sprint(v)}, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string                     `json:"jsonrpc"`
+	ID      json.RawMessage            `json:"id,omitempty"`
+	Method  string                     `json:"method"`
+	Params  map[string]json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// ServeJSONRPC answers JSON-RPC 2.0 requests read from conn until conn
+// reaches EOF. Each leaf command of root is available as a method, see
+// RPCMethods, and calls return an RPCResult. Parameters must be given
+// by name. The "rpc.methods" method returns the list of available
+// methods. Commands are run with their output captured and with
+// ContinueOnError error handling, so root must not be run by anything
+// else at the same time
+func ServeJSONRPC(root *Command, conn io.ReadWriter) error {
+	methods := make(map[string]RPCMethod)
+	list := RPCMethods(root)
+	for _, method := range list {
+		methods[method.Name] = method
+	}
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		var req rpcRequest
+		err := decoder.Decode(&req)
+		if err == io.EOF {
+			return nil
+		}
+
+		resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+		if resp.ID == nil {
+			resp.ID = json.RawMessage("null")
+		}
+
+		if err != nil {
+			resp.Error = &rpcError{rpcParseError, err.Error()}
+			encoder.Encode(resp)
+			return err
+		}
+
+		if req.JSONRPC != "2.0" {
+			resp.Error = &rpcError{rpcInvalidRequest, "jsonrpc must be \"2.0\""}
+		} else if req.Method == "rpc.methods" {
+			resp.Result = list
+		} else if method, found := methods[req.Method]; !found {
+			resp.Error = &rpcError{rpcMethodNotFound, fmt.Sprintf("method %q not found", req.Method)}
+		} else if result, err := method.call(req.Params); err != nil {
+			resp.Error = &rpcError{rpcInvalidParams, err.Error()}
+		} else {
+			resp.Result = result
+		}
+
+		// requests without an id are notifications and get no response
+		if req.ID == nil {
+			continue
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
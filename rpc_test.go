@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type rpcConn struct {
+	io.Reader
+	output *bytes.Buffer
+}
+
+func (rc *rpcConn) Write(p []byte) (int, error) { return rc.output.Write(p) }
+
+func testRPCCommand() *Command {
+	root := New("app")
+	root.Flags.Bool("v", false, "verbose")
+	remote := root.SubCommand("remote")
+	var add *Command
+	add = remote.SubCommand("add", DescOption("add a remote"), FuncOption(func(name string, timeout time.Duration) string {
+		fmt.Fprintf(add.Stdout(), "%s %v", name, timeout)
+		return name
+	}, "<name>", "<timeout>"))
+	add.Flags.Int("retries", 1, "retries")
+	root.SubCommand("raw", CallbackOption(func(name string, args ...string) ([]string, error) {
+		fmt.Fprint(root.SubCommands[1].Stdout(), strings.Join(args, ","))
+		return nil, nil
+	}))
+	return root
+}
+
+func TestRPCMethods(t *testing.T) {
+	want := []RPCMethod{
+		{Name: "raw", Params: []RPCParam{{"v", "bool", true, false}, {"args", "string", false, true}}},
+		{Name: "remote.add", Description: "add a remote", Params: []RPCParam{{"v", "bool", true, false}, {"retries", "int", true, false}, {"name", "string", false, false}, {"timeout", "duration", false, false}}},
+	}
+
+	got := RPCMethods(testRPCCommand())
+	for i := range got {
+		got[i].path = nil
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %+v got %+v", want, got)
+	}
+}
+
+func TestValueType(t *testing.T) {
+	var b bool
+	var str string
+	tests := []struct {
+		desc  string
+		input interface{}
+		want  string
+	}{
+		{"bool", (*boolValue)(&b), "bool"},
+		{"secret", SecretString(&str), "string"},
+		{"env", EnvDefault((*boolValue)(&b), "VERBOSE"), "bool"},
+		{"env secret", EnvDefault(SecretString(&str), "TOKEN"), "string"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := valueType(test.input)
+			if test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestArgumentName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"<host>", "host"},
+		{"<file>...", "file"},
+		{"source file", "source_file"},
+		{"<a> <b>", "arg3"},
+		{"", "arg3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if got := argumentName(test.input, 3); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestServeJSONRPC(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input string
+		want  string
+	}{
+		{"call", `{"jsonrpc":"2.0","id":1,"method":"remote.add","params":{"v":true,"retries":3,"name":"origin","timeout":"1s"}}`, `{"jsonrpc":"2.0","id":1,"result":{"exitCode":0,"stdout":"origin 1s","stderr":"","result":"origin"}}`},
+		{"args", `{"jsonrpc":"2.0","id":"a","method":"raw","params":{"args":["-x",2]}}`, `{"jsonrpc":"2.0","id":"a","result":{"exitCode":0,"stdout":"-x,2","stderr":""}}`},
+		{"command error", `{"jsonrpc":"2.0","id":1,"method":"remote.add","params":{"name":"origin","timeout":"never"}}`, `{"jsonrpc":"2.0","id":1,"result":{"exitCode":2,"error":"Invalid Usage parse error","stdout":"","stderr":""}}`},
+		{"missing param", `{"jsonrpc":"2.0","id":1,"method":"remote.add","params":{"name":"origin"}}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"missing parameter \"timeout\""}}`},
+		{"unknown param", `{"jsonrpc":"2.0","id":1,"method":"raw","params":{"foo":1}}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"unknown parameter \"foo\""}}`},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"foo"}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method \"foo\" not found"}}`},
+		{"bad version", `{"id":1,"method":"raw"}`, `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"jsonrpc must be \"2.0\""}}`},
+		{"notification", `{"jsonrpc":"2.0","method":"raw"}`, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			conn := &rpcConn{strings.NewReader(test.input), &bytes.Buffer{}}
+
+			if err := ServeJSONRPC(testRPCCommand(), conn); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			got := strings.TrimSpace(conn.output.String())
+			if test.want != got {
+				t.Errorf("Wanted %s got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestServeJSONRPCMethods(t *testing.T) {
+	conn := &rpcConn{strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rpc.methods"}`), &bytes.Buffer{}}
+
+	if err := ServeJSONRPC(testRPCCommand(), conn); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	resp := struct{ Result []RPCMethod }{}
+	if err := json.Unmarshal(conn.output.Bytes(), &resp); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(resp.Result) != 2 || resp.Result[1].Name != "remote.add" {
+		t.Errorf("Unexpected methods %+v", resp.Result)
+	}
+}
+
+func TestServeJSONRPCFlagsReset(t *testing.T) {
+	root := New("app")
+	verbose := false
+	say := root.SubCommand("say", FuncOption(func(s string) {
+		fmt.Fprintf(root.SubCommands[0].Stdout(), "%v %s", verbose, s)
+	}, "<s>"))
+	say.Flags.BoolVar(&verbose, "v", false, "verbose")
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"say","params":{"v":true,"s":"x"}}
+{"jsonrpc":"2.0","id":2,"method":"say","params":{"s":"y"}}`
+	conn := &rpcConn{strings.NewReader(input), &bytes.Buffer{}}
+	if err := ServeJSONRPC(root, conn); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := `{"jsonrpc":"2.0","id":1,"result":{"exitCode":0,"stdout":"true x","stderr":""}}
+{"jsonrpc":"2.0","id":2,"result":{"exitCode":0,"stdout":"false y","stderr":""}}`
+	if got := strings.TrimSpace(conn.output.String()); want != got {
+		t.Errorf("Wanted %s got %s", want, got)
+	}
+
+	if verbose {
+		t.Errorf("Expected flags to be reset")
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeMode selects how control characters and invalid UTF-8 in
+// untrusted input are handled
+type SanitizeMode int
+
+const (
+	SanitizeOff    SanitizeMode = iota // Input is not checked
+	SanitizeReject                     // Input with unsafe characters is rejected
+	SanitizeStrip                      // Unsafe characters are removed from input
+)
+
+// Sanitize checks s for invalid UTF-8 and for control characters other
+// than tab and newline, which can garble terminal output or forge log
+// lines. With SanitizeReject an error wrapping ErrUnsafeInput is
+// returned if any are found, and with SanitizeStrip they are removed
+func Sanitize(s string, mode SanitizeMode) (string, error) {
+	if mode == SanitizeOff {
+		return s, nil
+	}
+
+	safe := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\t' && r != '\n') {
+			return -1
+		}
+		return r
+	}, s)
+
+	if safe != s && mode == SanitizeReject {
+		return s, fmt.Errorf("%w: %q", ErrUnsafeInput, s)
+	}
+	return safe, nil
+}
+
+// SanitizeOption sanitizes, see Sanitize, the command line arguments
+// of the command and the responses to prompts made with the prompt
+// methods of the command. Subcommands created after the option has been
+// applied inherit it. Rejected arguments fail the command with a
+// UserError while rejected prompt responses are asked for again
+func SanitizeOption(mode SanitizeMode) Option {
+	return func(cmd *Command) { cmd.sanitize = mode }
+}
+
+// sanitizeArgs sanitizes every argument
+func (cmd *Command) sanitizeArgs(args []string) ([]string, error) {
+	if cmd.sanitize == SanitizeOff {
+		return args, nil
+	}
+
+	safe := make([]string, len(args))
+	for i, arg := range args {
+		s, err := Sanitize(arg, cmd.sanitize)
+		if err != nil {
+			return args, &UserError{fmt.Errorf("argument %d: %w", i+1, err)}
+		}
+		safe[i] = s
+	}
+	return safe, nil
+}
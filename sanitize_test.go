@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		desc    string
+		input   string
+		mode    SanitizeMode
+		want    string
+		wantErr error
+	}{
+		{"off", "a\x1b[31mb", SanitizeOff, "a\x1b[31mb", nil},
+		{"safe", "tab\tnew\nline é", SanitizeReject, "tab\tnew\nline é", nil},
+		{"reject escape", "a\x1b[31mb", SanitizeReject, "a\x1b[31mb", ErrUnsafeInput},
+		{"reject invalid utf8", "a\xffb", SanitizeReject, "a\xffb", ErrUnsafeInput},
+		{"strip escape", "a\x1b[31mb", SanitizeStrip, "a[31mb", nil},
+		{"strip carriage return", "a\rb", SanitizeStrip, "ab", nil},
+		{"strip invalid utf8", "a\xffb", SanitizeStrip, "ab", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := Sanitize(test.input, test.mode)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got != test.want {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeOption(t *testing.T) {
+	tests := []struct {
+		desc    string
+		mode    SanitizeMode
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{"reject", SanitizeReject, []string{"sub", "a\x07b"}, "", ErrUnsafeInput},
+		{"strip", SanitizeStrip, []string{"sub", "a\x07b"}, "ab", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := ""
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), SanitizeOption(test.mode))
+			cmd.SubCommand("sub", FuncOption(func(s string) { got = s }))
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			var ue *UserError
+			if err != nil && !errors.As(err, &ue) {
+				t.Errorf("Expected a UserError got %T", err)
+			}
+
+			if got != test.want {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizePrompt(t *testing.T) {
+	stdout := &strings.Builder{}
+	cmd := New("app", SanitizeOption(SanitizeReject), StdinOption(strings.NewReader("bad\x1b\ngood\n")), StdoutOption(stdout))
+	sub := cmd.SubCommand("sub")
+
+	got, err := sub.Ask("name? ")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if got != "good" {
+		t.Errorf("Wanted %q got %q", "good", got)
+	}
+
+	if !strings.Contains(stdout.String(), ErrUnsafeInput.Error()) {
+		t.Errorf("Expected the rejection to be displayed got %q", stdout.String())
+	}
+}
@@ -0,0 +1,94 @@
+// Package scaffold generates the skeleton of a new command line
+// application built with the cli package
+package scaffold
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Config describes the application to generate
+type Config struct {
+	// Name is the name of the application
+	Name string
+
+	// Commands are the names of sample subcommands to generate
+	Commands []string
+
+	// Version is the initial version string
+	Version string
+}
+
+var mainTmpl = template.Must(template.New("main").Funcs(template.FuncMap{"ident": ident}).Parse(`package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/abates/cli"
+)
+
+var version = {{printf "%q" .Version}}
+
+func main() {
+	app := cli.New({{printf "%q" .Name}}, cli.DescOption("TODO: describe {{.Name}}"))
+{{- range .Commands}}
+	app.SubCommand({{printf "%q" .}}, cli.DescOption("TODO: describe {{.}}"), cli.FuncOption({{ident .}}, "<name>"))
+{{- end}}
+	cli.AddBuiltins(app, cli.Builtins{Completion: true, Docs: true, Version: version})
+	app.Run(os.Args[1:])
+}
+{{range .Commands}}
+func {{ident .}}(name string) error {
+	fmt.Printf("{{.}} %s\n", name)
+	return nil
+}
+{{end}}`))
+
+// Generate writes the source of a main.go for the application
+// described by config to w
+func Generate(w io.Writer, config Config) error {
+	if config.Version == "" {
+		config.Version = "0.0.0"
+	}
+
+	buf := &bytes.Buffer{}
+	err := mainTmpl.Execute(buf, config)
+	if err == nil {
+		var src []byte
+		src, err = format.Source(buf.Bytes())
+		if err == nil {
+			_, err = w.Write(src)
+		}
+	}
+	return err
+}
+
+// ident converts a command name, such as "list-items", into the name
+// of the function implementing it ("listItemsCmd")
+func ident(name string) string {
+	builder := &strings.Builder{}
+	upper := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = builder.Len() > 0
+			continue
+		}
+
+		if builder.Len() == 0 {
+			if unicode.IsDigit(r) {
+				builder.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		} else if upper {
+			r = unicode.ToUpper(r)
+		}
+		builder.WriteRune(r)
+		upper = false
+	}
+	return builder.String() + "Cmd"
+}
@@ -0,0 +1,54 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	builder := &strings.Builder{}
+	err := Generate(builder, Config{Name: "app", Commands: []string{"list-items", "go"}})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	got := builder.String()
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", got, 0); err != nil {
+		t.Fatalf("Generated source does not parse: %v\n%s", err, got)
+	}
+
+	for _, want := range []string{
+		`var version = "0.0.0"`,
+		`app := cli.New("app", cli.DescOption("TODO: describe app"))`,
+		`app.SubCommand("list-items", cli.DescOption("TODO: describe list-items"), cli.FuncOption(listItemsCmd, "<name>"))`,
+		`func goCmd(name string) error {`,
+		`cli.AddBuiltins(app, cli.Builtins{Completion: true, Docs: true, Version: version})`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Wanted generated source to contain %q got:\n%s", want, got)
+		}
+	}
+}
+
+func TestIdent(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"list", "listCmd"},
+		{"list-items", "listItemsCmd"},
+		{"List_Items", "listItemsCmd"},
+		{"2fa", "_2faCmd"},
+		{"-foo", "fooCmd"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if got := ident(test.input); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ParamSchema describes a flag or positional argument of a command in
+// enough detail to generate a form or GUI for it
+type ParamSchema struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Kind        string            `json:"kind"`
+	Default     string            `json:"default,omitempty"`
+	Choices     []string          `json:"choices,omitempty"`
+	Constraints map[string]string `json:"constraints,omitempty"`
+	Flag        bool              `json:"flag"`
+	Array       bool              `json:"array"`
+	Required    bool              `json:"required"`
+}
+
+// CommandSchema describes a command, its parameters and subcommands
+type CommandSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Usage       string          `json:"usage,omitempty"`
+	Params      []ParamSchema   `json:"params,omitempty"`
+	SubCommands []CommandSchema `json:"subcommands,omitempty"`
+}
+
+// SchemaValue can be implemented by flag and argument values to add
+// choices and constraints to their ParamSchema
+type SchemaValue interface {
+	Schema(*ParamSchema)
+}
+
+// Schema returns a description of the command hierarchy including the
+// type, default and, for values implementing SchemaValue, the choices
+// and constraints of every flag and positional argument. The Kind of a
+// parameter is derived from its value type, such as "int", "string" or
+// "duration". Positional arguments are known for callbacks set with
+// FuncOption and for arguments declared with SetArguments or
+// SetArgumentsFunc, the latter being those that usage displays for the
+// current flag values. Arguments are named in the same way as the
+// parameters of RPCMethods
+func (cmd *Command) Schema() CommandSchema {
+	schema := CommandSchema{Name: cmd.Name, Description: cmd.Description, Usage: cmd.usageStr()}
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		param := ParamSchema{Name: f.Name, Description: f.Usage, Kind: valueType(f.Value), Default: f.DefValue, Flag: true}
+		if sv, ok := f.Value.(SchemaValue); ok {
+			sv.Schema(&param)
+		}
+		schema.Params = append(schema.Params, param)
+	})
+
+	if arguments := cmd.declaredArguments(); arguments != nil {
+		for i, arg := range arguments.args {
+			_, array := arg.value.(SliceValue)
+			param := ParamSchema{Name: argumentName(arg.desc, i), Description: arg.desc, Kind: valueType(arg.value), Array: array, Required: !array && arg.def == nil}
+			if arg.def != nil {
+				param.Default = *arg.def
+			}
+			if sv, ok := arg.value.(SchemaValue); ok {
+				sv.Schema(&param)
+			}
+			schema.Params = append(schema.Params, param)
+		}
+	}
+
+	cmd.load()
+	for _, subCmd := range subCommands(cmd.SubCommands).sorted() {
+		schema.SubCommands = append(schema.SubCommands, subCmd.Schema())
+	}
+	return schema
+}
+
+type choiceValue struct {
+	p       *string
+	choices []string
+}
+
+// Choice returns a Value that only accepts one of the given choices.
+// The value is stored in p, which is set to value initially. The
+// choices are included in the command's Schema
+func Choice(p *string, value string, choices ...string) Value {
+	*p = value
+	return &choiceValue{p: p, choices: choices}
+}
+
+func (cv *choiceValue) String() string {
+	if cv.p == nil {
+		return ""
+	}
+	return *cv.p
+}
+
+func (cv *choiceValue) Set(s string) error {
+	for _, choice := range cv.choices {
+		if s == choice {
+			*cv.p = s
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q must be one of %s", ErrParse, s, strings.Join(cv.choices, ", "))
+}
+
+func (cv *choiceValue) Schema(param *ParamSchema) {
+	param.Kind = "string"
+	param.Choices = append([]string{}, cv.choices...)
+}
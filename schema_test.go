@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type rangeValue struct{ intValue }
+
+func (rv *rangeValue) Schema(param *ParamSchema) {
+	param.Constraints = map[string]string{"min": "1", "max": "10"}
+}
+
+func TestSchema(t *testing.T) {
+	var format string
+	root := New("app", DescOption("the app"))
+	root.Flags.Var(Choice(&format, "text", "text", "json"), "format", "output format")
+	sub := root.SubCommand("add", FuncOption(func(name string, count *rangeValue) {}, "<name>", "<count>"))
+	sub.Flags.Bool("force", false, "force it")
+
+	want := CommandSchema{
+		Name:        "app",
+		Description: "the app",
+		Params: []ParamSchema{
+			{Name: "format", Description: "output format", Kind: "string", Default: "text", Choices: []string{"text", "json"}, Flag: true},
+		},
+		SubCommands: []CommandSchema{{
+			Name:  "add",
+			Usage: "<name> <count>",
+			Params: []ParamSchema{
+				{Name: "force", Description: "force it", Kind: "bool", Default: "false", Flag: true},
+				{Name: "name", Description: "<name>", Kind: "string", Required: true},
+				{Name: "count", Description: "<count>", Kind: "range", Constraints: map[string]string{"min": "1", "max": "10"}, Required: true},
+			},
+		}},
+	}
+
+	got := root.Schema()
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %+v got %+v", want, got)
+	}
+}
+
+func TestSchemaArgumentsFunc(t *testing.T) {
+	want := []ParamSchema{
+		{Name: "n", Description: "number of pairs", Kind: "int", Default: "1", Flag: true},
+		{Name: "key", Description: "<key>", Kind: "string", Required: true},
+		{Name: "value", Description: "<value>", Kind: "int", Required: true},
+	}
+
+	if got := addPairsCommand(New("app")).Schema().Params; !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %+v got %+v", want, got)
+	}
+}
+
+func TestChoice(t *testing.T) {
+	var s string
+	v := Choice(&s, "a", "a", "b")
+	if v.String() != "a" {
+		t.Errorf("Wanted initial value %q got %q", "a", v.String())
+	}
+
+	if err := v.Set("b"); err != nil || s != "b" {
+		t.Errorf("Wanted %q got %q (%v)", "b", s, err)
+	}
+
+	if err := v.Set("c"); !errors.Is(err, ErrParse) {
+		t.Errorf("Wanted %v got %v", ErrParse, err)
+	}
+
+	if s != "b" {
+		t.Errorf("Wanted value to be unchanged got %q", s)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+)
+
+// redacted replaces secret values in output
+const redacted = "********"
+
+type secretValue struct {
+	Value
+	raw string
+}
+
+// Secret marks value as holding a secret. The returned Value displays
+// a mask in place of its real value, so the value does not appear in
+// usage defaults or Explain output, and input that fails to parse is
+// masked in error messages
+func Secret(value Value) Value {
+	return &secretValue{Value: value}
+}
+
+// SecretString returns a secret Value that sets p
+func SecretString(p *string) Value {
+	return Secret((*stringValue)(p))
+}
+
+func (sv *secretValue) String() string {
+	if sv == nil || sv.Value == nil || sv.Value.String() == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (sv *secretValue) Unwrap() Value { return sv.Value }
+
+func (sv *secretValue) Set(s string) error {
+	sv.raw = s
+	err := sv.Value.Set(s)
+	if err != nil && s != "" {
+		if msg := strings.Replace(err.Error(), s, redacted, -1); msg != err.Error() {
+			err = &redactedError{err: err, msg: msg}
+		}
+	}
+	return err
+}
+
+// valueWrapper is implemented by Values that wrap another Value, such
+// as those returned by EnvDefault or ExpandValue
+type valueWrapper interface {
+	Unwrap() Value
+}
+
+// unwrapValue follows the chain of wrapped values starting at value,
+// returning the first one for which match returns true, or nil
+func unwrapValue(value interface{}, match func(interface{}) bool) interface{} {
+	for value != nil {
+		if match(value) {
+			return value
+		}
+
+		wrapper, ok := value.(valueWrapper)
+		if !ok {
+			break
+		}
+		value = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// findSecret returns the secretValue that value is or wraps, or nil
+func findSecret(value interface{}) *secretValue {
+	sv, _ := unwrapValue(value, func(v interface{}) bool {
+		_, ok := v.(*secretValue)
+		return ok
+	}).(*secretValue)
+	return sv
+}
+
+func isSecret(value interface{}) bool {
+	return findSecret(value) != nil
+}
+
+// redactedError is an error whose message has been redacted
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (re *redactedError) Error() string { return re.msg }
+func (re *redactedError) Unwrap() error { return re.err }
+
+// secretReplacer returns a replacer that masks the input given to any
+// secret flag in flags
+func secretReplacer(flags *flag.FlagSet) *strings.Replacer {
+	oldnew := []string{}
+	flags.VisitAll(func(f *flag.Flag) {
+		if sv := findSecret(f.Value); sv != nil && sv.raw != "" {
+			oldnew = append(oldnew, sv.raw, redacted)
+		}
+	})
+	return strings.NewReplacer(oldnew...)
+}
+
+// parseFlags parses args with the command's FlagSet, see parseFlagSet
+func (cmd *Command) parseFlags(args []string) error {
+	return parseFlagSet(&cmd.Flags, args)
+}
+
+// parseFlagSet parses args with flags, making sure that the input of
+// secret flags is masked in any error messages. Errors match ErrUsage,
+// or ErrParse for invalid values, with errors.Is. The flag package
+// does not print anything, errors and usage are printed by the caller
+// (see printFlagError)
+func parseFlagSet(flags *flag.FlagSet, args []string) error {
+	output, usage := flags.Output(), flags.Usage
+	flags.SetOutput(ioutil.Discard)
+	flags.Usage = func() {}
+	err := flags.Parse(args)
+	flags.SetOutput(output)
+	flags.Usage = usage
+	if err != nil {
+		if msg := secretReplacer(flags).Replace(err.Error()); msg != err.Error() {
+			err = &redactedError{err: err, msg: msg}
+		}
+	}
+	return newFlagError(err)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	var s string
+	value := SecretString(&s)
+	if got := value.String(); got != "" {
+		t.Errorf("Wanted empty string got %q", got)
+	}
+
+	value.Set("hunter2")
+	if s != "hunter2" {
+		t.Errorf("Wanted value to be set got %q", s)
+	}
+
+	if got := value.String(); got != redacted {
+		t.Errorf("Wanted %q got %q", redacted, got)
+	}
+
+	var i int
+	err := Secret((*intValue)(&i)).Set("1234abcd")
+	if err == nil || err.Error() != ErrParse.Error() {
+		t.Errorf("Wanted %v got %v", ErrParse, err)
+	}
+}
+
+func TestSecretUsage(t *testing.T) {
+	token := "hunter2"
+	cmd := New("app")
+	cmd.Flags.Var(SecretString(&token), "token", "API token")
+	builder := &strings.Builder{}
+	cmd.usage(&indenter{writer: builder})
+	if got := builder.String(); strings.Contains(got, "hunter2") || !strings.Contains(got, redacted) {
+		t.Errorf("Wanted usage to mask the default got %q", got)
+	}
+}
+
+func TestSecretParseError(t *testing.T) {
+	var i int
+	output := &strings.Builder{}
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	cmd.Flags.SetOutput(output)
+	cmd.Flags.Var(Secret((*intValue)(&i)), "pin", "PIN")
+
+	_, err := cmd.Run([]string{"-pin", "1234abcd"})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	for _, got := range []string{err.Error(), output.String()} {
+		if strings.Contains(got, "1234abcd") || !strings.Contains(got, redacted) {
+			t.Errorf("Wanted secret to be masked got %q", got)
+		}
+	}
+}
+
+func TestSecretExplain(t *testing.T) {
+	var token string
+	cmd := New("app")
+	cmd.Flags.Var(SecretString(&token), "token", "API token")
+	builder := &strings.Builder{}
+	if err := cmd.Explain(builder, []string{"-token", "hunter2"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if got := builder.String(); strings.Contains(got, "hunter2") {
+		t.Errorf("Wanted explain to mask the secret got %q", got)
+	}
+}
+
+func TestIsSecretWrapped(t *testing.T) {
+	var s string
+	tests := []struct {
+		desc  string
+		value Value
+		want  bool
+	}{
+		{"plain", ExpandString(&s), false},
+		{"secret", SecretString(&s), true},
+		{"env", EnvDefault(SecretString(&s), "CLI_TEST_UNSET"), true},
+		{"placeholder", WithPlaceholder(SecretString(&s), "<token>"), true},
+		{"expand", ExpandValue(SecretString(&s)), true},
+		{"transform", TransformValue(SecretString(&s), strings.TrimSpace), true},
+		{"nested", EnvDefault(ExpandValue(SecretString(&s)), "CLI_TEST_UNSET"), true},
+		{"deprecated", &deprecatedValue{Value: SecretString(&s)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got := isSecret(test.value); test.want != got {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+}
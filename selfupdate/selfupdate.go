@@ -0,0 +1,289 @@
+// Package selfupdate adds a command to cli applications that replaces
+// the running binary with the latest released version
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abates/cli"
+)
+
+var (
+	ErrChecksum  = errors.New("Checksum mismatch")
+	ErrNoRelease = errors.New("No release found")
+)
+
+// Release describes a released binary
+type Release struct {
+	Version string `json:"version"`
+
+	// URL is where the binary can be downloaded from
+	URL string `json:"url"`
+
+	// SHA256 is the hex encoded SHA-256 checksum of the binary
+	SHA256 string `json:"sha256"`
+}
+
+// Source finds the latest release for a channel (such as "stable" or
+// "beta"). Sources should treat an empty channel as the default channel
+type Source interface {
+	Latest(ctx context.Context, channel string) (*Release, error)
+}
+
+// Updater checks for, and installs, new releases of the running program
+type Updater struct {
+	// CurrentVersion is the version of the running program
+	CurrentVersion string
+
+	// Channel is the release channel passed to Source
+	Channel string
+
+	Source Source
+
+	// Verify is called with the downloaded binary once its checksum has
+	// been verified. It can be used to verify a signature
+	Verify func(release *Release, binary []byte) error
+
+	// Executable is the path of the binary to replace. The path of the
+	// running program is used when it is empty
+	Executable string
+
+	// Client is used to download releases. http.DefaultClient is used
+	// when it is nil
+	Client *http.Client
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
+
+func get(ctx context.Context, c *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client(c).Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Check returns the latest release and whether it is newer than the
+// current version
+func (u *Updater) Check(ctx context.Context) (release *Release, newer bool, err error) {
+	release, err = u.Source.Latest(ctx, u.Channel)
+	if err == nil {
+		newer = CompareVersions(release.Version, u.CurrentVersion) > 0
+	}
+	return release, newer, err
+}
+
+// Notifier returns a cli.UpdateNotifier that uses Check to look for new
+// releases. Use it with cli.UpdateNotifierOption to tell users about new
+// releases while they run other commands
+func (u *Updater) Notifier() *cli.UpdateNotifier {
+	return &cli.UpdateNotifier{
+		CurrentVersion: u.CurrentVersion,
+		Check: func(ctx context.Context) (string, bool, error) {
+			release, newer, err := u.Check(ctx)
+			if err != nil {
+				return "", false, err
+			}
+			return release.Version, newer, nil
+		},
+	}
+}
+
+// Update installs the latest release if it is newer than the current
+// version. The installed release is returned, or nil if the current
+// version is already up to date
+func (u *Updater) Update(ctx context.Context) (*Release, error) {
+	release, newer, err := u.Check(ctx)
+	if err != nil || !newer {
+		return nil, err
+	}
+
+	binary, err := get(ctx, u.Client, release.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(binary)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimSpace(release.SHA256)) {
+		return nil, fmt.Errorf("%w for %s", ErrChecksum, release.URL)
+	}
+
+	if u.Verify != nil {
+		if err := u.Verify(release, binary); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.replace(binary); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// replace atomically replaces the executable with binary
+func (u *Updater) replace(binary []byte) error {
+	exe := u.Executable
+	if exe == "" {
+		var err error
+		if exe, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	tmpfile, err := ioutil.TempFile(filepath.Dir(exe), "."+filepath.Base(exe)+".new")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.Write(binary)
+	if cerr := tmpfile.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmpfile.Name(), fi.Mode())
+	}
+
+	if err == nil {
+		err = os.Rename(tmpfile.Name(), exe)
+	}
+	return err
+}
+
+// CompareVersions compares two version strings such as "v1.2.3". The
+// result is negative if a is older than b, zero if they are equal and
+// positive if a is newer than b. Versions are compared following the
+// semantic versioning precedence rules: numeric parts are compared
+// numerically, anything else is compared as a string, a prerelease
+// such as "v1.0.0-beta.1" is older than its release and build metadata
+// following a "+" is ignored
+func CompareVersions(a, b string) int {
+	aVersion, aPre := splitVersion(a)
+	bVersion, bPre := splitVersion(b)
+	if c := compareParts(aVersion, bVersion); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(aPre) == 0 && len(bPre) == 0:
+		return 0
+	case len(aPre) == 0:
+		return 1
+	case len(bPre) == 0:
+		return -1
+	}
+	return compareParts(aPre, bPre)
+}
+
+// splitVersion splits a version string into the parts of the version
+// and of its prerelease, dropping any build metadata
+func splitVersion(version string) (parts, prerelease []string) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+
+	if i := strings.Index(version, "-"); i >= 0 {
+		prerelease = strings.Split(version[i+1:], ".")
+		version = version[:i]
+	}
+	return strings.Split(version, "."), prerelease
+}
+
+// compareParts compares two lists of version parts. Numeric parts are
+// older than non-numeric ones and a shorter list is older than a longer
+// one that it is a prefix of
+func compareParts(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) {
+			return -1
+		} else if i >= len(b) {
+			return 1
+		}
+
+		aNum, aErr := strconv.Atoi(a[i])
+		bNum, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return aNum - bNum
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// AddCommand adds a "self-update" command to parent that installs the
+// latest release using u. The command has a -channel flag to override
+// the updater's channel and a -check flag that only reports whether an
+// update is available
+func AddCommand(parent *cli.Command, u *Updater) *cli.Command {
+	check := false
+	cmd := parent.SubCommand("self-update", cli.DescOption("Update to the latest release"))
+	cmd.Flags.StringVar(&u.Channel, "channel", u.Channel, "release channel")
+	cmd.Flags.BoolVar(&check, "check", false, "only check whether an update is available")
+	cmd.Callback = cli.Callback(func() error {
+		ctx := context.Background()
+		if check {
+			release, newer, err := u.Check(ctx)
+			if err == nil {
+				if newer {
+					fmt.Fprintf(cmd.Stdout(), "Version %s is available (current version %s)\n", release.Version, u.CurrentVersion)
+				} else {
+					fmt.Fprintf(cmd.Stdout(), "Version %s is up to date\n", u.CurrentVersion)
+				}
+			}
+			return err
+		}
+
+		release, err := u.Update(ctx)
+		if err == nil {
+			if release == nil {
+				fmt.Fprintf(cmd.Stdout(), "Version %s is up to date\n", u.CurrentVersion)
+			} else {
+				fmt.Fprintf(cmd.Stdout(), "Updated to version %s\n", release.Version)
+			}
+		}
+		return err
+	})
+	return cmd
+}
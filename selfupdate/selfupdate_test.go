@@ -0,0 +1,227 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abates/cli"
+)
+
+type staticSource struct {
+	release *Release
+}
+
+func (ss *staticSource) Latest(context.Context, string) (*Release, error) { return ss.release, nil }
+
+func checksum(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+func testServer(binary []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	}))
+}
+
+func testExecutable(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	exe := filepath.Join(dir, "app")
+	if err := ioutil.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to write executable: %v", err)
+	}
+	return exe
+}
+
+func TestUpdate(t *testing.T) {
+	binary := []byte("new binary")
+	server := testServer(binary)
+	defer server.Close()
+
+	tests := []struct {
+		desc       string
+		release    *Release
+		verify     func(*Release, []byte) error
+		wantErr    error
+		wantBinary string
+	}{
+		{"update", &Release{Version: "v1.1.0", URL: server.URL, SHA256: checksum(binary)}, nil, nil, "new binary"},
+		{"up to date", &Release{Version: "v1.0.0", URL: server.URL, SHA256: checksum(binary)}, nil, nil, "old binary"},
+		{"checksum mismatch", &Release{Version: "v1.1.0", URL: server.URL, SHA256: checksum([]byte("foo"))}, nil, ErrChecksum, "old binary"},
+		{"verify failed", &Release{Version: "v1.1.0", URL: server.URL, SHA256: checksum(binary)}, func(*Release, []byte) error { return ErrNoRelease }, ErrNoRelease, "old binary"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			exe := testExecutable(t)
+			defer os.RemoveAll(filepath.Dir(exe))
+
+			u := &Updater{CurrentVersion: "v1.0.0", Source: &staticSource{test.release}, Verify: test.verify, Executable: exe}
+			_, err := u.Update(context.Background())
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			got, _ := ioutil.ReadFile(exe)
+			if test.wantBinary != string(got) {
+				t.Errorf("Wanted binary %q got %q", test.wantBinary, string(got))
+			}
+
+			if fi, _ := os.Stat(exe); fi.Mode() != 0755 {
+				t.Errorf("Wanted mode %v got %v", os.FileMode(0755), fi.Mode())
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.0.0", "1.0.0", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.0.0", "v1.0.1", -1},
+		{"v1.0", "v1.0.1", -1},
+		{"v1.0.0-beta.2", "v1.0.0-beta.1", 1},
+		{"v1.0.0", "v1.0.0-beta.1", 1},
+		{"v1.0.0-beta.1", "v1.0.0", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-beta.11", "v1.0.0-beta.2", 1},
+		{"v1.0.0-rc.1", "v1.0.0-beta.11", 1},
+		{"v1.0.0+build.1", "v1.0.0+build.2", 0},
+		{"v1.0.1-beta.1", "v1.0.0", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s", test.a, test.b), func(t *testing.T) {
+			got := CompareVersions(test.a, test.b)
+			if (test.want < 0) != (got < 0) || (test.want > 0) != (got > 0) {
+				t.Errorf("Wanted %d got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version": %q, "url": "http://example.com/app", "sha256": "abcd"}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	source := &JSONSource{URL: server.URL + "/{channel}.json"}
+	release, err := source.Latest(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := &Release{Version: "/stable.json", URL: "http://example.com/app", SHA256: "abcd"}
+	if *want != *release {
+		t.Errorf("Wanted %+v got %+v", want, release)
+	}
+}
+
+func TestGitHubSource(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release := func(tag string) string {
+			return fmt.Sprintf(`{"tag_name": %q, "assets": [{"name": "app", "browser_download_url": "%s/%s/app"}, {"name": "app.sha256", "browser_download_url": "%s/%s/app.sha256"}]}`, tag, server.URL, tag, server.URL, tag)
+		}
+
+		switch r.URL.Path {
+		case "/repos/abates/app/releases/latest":
+			fmt.Fprint(w, release("v1.0.0"))
+		case "/repos/abates/app/releases":
+			fmt.Fprintf(w, "[%s, %s]", release("v1.1.0-beta.1"), release("v1.0.0"))
+		default:
+			if strings.HasSuffix(r.URL.Path, ".sha256") {
+				fmt.Fprintf(w, "%s  app\n", strings.Split(r.URL.Path, "/")[1])
+			} else {
+				http.NotFound(w, r)
+			}
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		channel string
+		want    Release
+		wantErr error
+	}{
+		{"", Release{Version: "v1.0.0", URL: server.URL + "/v1.0.0/app", SHA256: "v1.0.0"}, nil},
+		{"beta", Release{Version: "v1.1.0-beta.1", URL: server.URL + "/v1.1.0-beta.1/app", SHA256: "v1.1.0-beta.1"}, nil},
+		{"nightly", Release{}, ErrNoRelease},
+	}
+
+	for _, test := range tests {
+		t.Run(test.channel, func(t *testing.T) {
+			source := &GitHubSource{Owner: "abates", Repo: "app", Asset: "app", BaseURL: server.URL}
+			release, err := source.Latest(context.Background(), test.channel)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if err == nil && test.want != *release {
+				t.Errorf("Wanted %+v got %+v", test.want, release)
+			}
+		})
+	}
+}
+
+func TestAddCommand(t *testing.T) {
+	binary := []byte("new binary")
+	server := testServer(binary)
+	defer server.Close()
+
+	exe := testExecutable(t)
+	defer os.RemoveAll(filepath.Dir(exe))
+
+	stdout := &strings.Builder{}
+	root := cli.New("app", cli.StdoutOption(stdout), cli.ErrorHandlingOption(cli.ContinueOnError))
+	AddCommand(root, &Updater{
+		CurrentVersion: "v1.0.0",
+		Source:         &staticSource{&Release{Version: "v1.1.0", URL: server.URL, SHA256: checksum(binary)}},
+		Executable:     exe,
+	})
+
+	if _, err := root.Run([]string{"self-update", "-check"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := root.Run([]string{"self-update", "-check=false"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "Version v1.1.0 is available (current version v1.0.0)\nUpdated to version v1.1.0\n"
+	if got := stdout.String(); want != got {
+		t.Errorf("Wanted output %q got %q", want, got)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	u := &Updater{CurrentVersion: "v1.0.0", Source: &staticSource{&Release{Version: "v1.1.0"}}}
+	n := u.Notifier()
+	if n.CurrentVersion != "v1.0.0" {
+		t.Errorf("Wanted current version %q got %q", "v1.0.0", n.CurrentVersion)
+	}
+
+	latest, newer, err := n.Check(context.Background())
+	if err != nil || latest != "v1.1.0" || !newer {
+		t.Errorf("Wanted v1.1.0 (newer) got %q (%v) %v", latest, newer, err)
+	}
+}
@@ -0,0 +1,130 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// JSONSource reads the latest release from a JSON document such as:
+//
+//	{"version": "v1.2.3", "url": "https://...", "sha256": "..."}
+//
+// Any occurrence of "{channel}" in URL is replaced with the channel name
+// ("stable" when the channel is empty)
+type JSONSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (js *JSONSource) Latest(ctx context.Context, channel string) (*Release, error) {
+	if channel == "" {
+		channel = "stable"
+	}
+
+	buf, err := get(ctx, js.Client, strings.Replace(js.URL, "{channel}", channel, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	release := &Release{}
+	if err := json.Unmarshal(buf, release); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// GitHubSource finds releases of a GitHub repository. The binary is
+// downloaded from the release asset named Asset and the checksum is read
+// from an asset with the same name and a ".sha256" suffix. The empty and
+// "stable" channels use the latest (non pre-release) release. Any other
+// channel uses the most recent release whose tag contains the channel
+// name, for instance "v1.3.0-beta.1" for the "beta" channel
+type GitHubSource struct {
+	Owner string
+	Repo  string
+	Asset string
+
+	// BaseURL is the GitHub API URL, https://api.github.com is used when
+	// it is empty
+	BaseURL string
+
+	Client *http.Client
+}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (gs *GitHubSource) Latest(ctx context.Context, channel string) (*Release, error) {
+	baseURL := gs.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+
+	var ghr *githubRelease
+	if channel == "" || channel == "stable" {
+		buf, err := get(ctx, gs.Client, fmt.Sprintf("%s/repos/%s/%s/releases/latest", baseURL, gs.Owner, gs.Repo))
+		if err != nil {
+			return nil, err
+		}
+
+		ghr = &githubRelease{}
+		if err := json.Unmarshal(buf, ghr); err != nil {
+			return nil, err
+		}
+	} else {
+		buf, err := get(ctx, gs.Client, fmt.Sprintf("%s/repos/%s/%s/releases", baseURL, gs.Owner, gs.Repo))
+		if err != nil {
+			return nil, err
+		}
+
+		releases := []*githubRelease{}
+		if err := json.Unmarshal(buf, &releases); err != nil {
+			return nil, err
+		}
+
+		for _, r := range releases {
+			if strings.Contains(r.TagName, channel) {
+				ghr = r
+				break
+			}
+		}
+
+		if ghr == nil {
+			return nil, fmt.Errorf("%w for channel %q", ErrNoRelease, channel)
+		}
+	}
+
+	release := &Release{Version: ghr.TagName}
+	sumURL := ""
+	for _, asset := range ghr.Assets {
+		switch asset.Name {
+		case gs.Asset:
+			release.URL = asset.URL
+		case gs.Asset + ".sha256":
+			sumURL = asset.URL
+		}
+	}
+
+	if release.URL == "" || sumURL == "" {
+		return nil, fmt.Errorf("%w: release %s is missing asset %s or its checksum", ErrNoRelease, ghr.TagName, gs.Asset)
+	}
+
+	buf, err := get(ctx, gs.Client, sumURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// checksum files are usually in the sha256sum format "<sum>  <file>"
+	if fields := strings.Fields(string(buf)); len(fields) > 0 {
+		release.SHA256 = fields[0]
+	}
+	return release, nil
+}
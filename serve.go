@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Frame types used by Serve and Call
+const (
+	frameRequest = 'r' // NUL separated command line arguments
+	frameStdout  = 'o' // a chunk written to Stdout
+	frameStderr  = 'e' // a chunk written to Output
+	frameExit    = 'x' // the decimal exit code of the command
+)
+
+// maxFrameSize limits the size of a single frame
+const maxFrameSize = 1 << 24
+
+// writeFrame writes a frame consisting of a type byte, a 4 byte big
+// endian payload length and the payload
+func writeFrame(w io.Writer, typ byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = typ
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	if _, err := w.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func readFrame(r io.Reader) (typ byte, payload []byte, err error) {
+	header := make([]byte, 5)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxFrameSize {
+		return 0, nil, ErrFrameSize
+	}
+
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(r, payload); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return header[0], payload, err
+}
+
+type frameWriter struct {
+	w   io.Writer
+	typ byte
+}
+
+func (fw *frameWriter) Write(p []byte) (int, error) {
+	if err := writeFrame(fw.w, fw.typ, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Serve runs the command hierarchy rooted at root for each request read
+// from conn, until conn is closed. Requests and responses are sent as
+// frames, each made of a type byte, a 4 byte big endian length and a
+// payload. A request frame holds the command line arguments separated
+// by NUL bytes. While the command runs, everything it writes to Stdout
+// and Output is streamed back in stdout and stderr frames and once it
+// has finished an exit frame holding the decimal exit code is sent.
+// Call implements the client side of the protocol.
+//
+// While a request is being served, the error handling of every command
+// is ContinueOnError and their output is redirected to conn, so root
+// must not be run by anything else at the same time. The flags of every
+// command are reset to their defaults around each request, so flags
+// given in one request do not carry over to the next. Serve returns nil
+// when conn reaches EOF between requests
+func Serve(root *Command, conn io.ReadWriter) error {
+	reader := bufio.NewReader(conn)
+	for {
+		typ, payload, err := readFrame(reader)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if typ != frameRequest {
+			return fmt.Errorf("unexpected frame type %q", typ)
+		}
+
+		var args []string
+		if len(payload) > 0 {
+			args = strings.Split(string(payload), "\x00")
+		}
+
+		if err := serve(root, conn, args); err != nil {
+			return err
+		}
+	}
+}
+
+func serve(root *Command, conn io.Writer, args []string) error {
+	stdout := &frameWriter{w: conn, typ: frameStdout}
+	stderr := &frameWriter{w: conn, typ: frameStderr}
+	restore := root.redirect(stdout, stderr)
+	root.resetFlags()
+	_, err := root.Run(args)
+	root.resetFlags()
+	restore()
+
+	code := 0
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		if errors.Is(err, ErrUsage) {
+			root.renderUsageOnError(stderr)
+		}
+		code = exitCode(err)
+	}
+	return writeFrame(conn, frameExit, []byte(strconv.Itoa(code)))
+}
+
+// redirect sets the output and error handling of cmd and all of its
+// subcommands for serving a request. The returned function restores
+// the previous settings
+func (cmd *Command) redirect(stdout, stderr io.Writer) (restore func()) {
+	// lazy subcommands are built first so that they inherit the
+	// settings being saved rather than the redirected ones
+	cmd.load()
+	prevStdout, prevOutput, prevFlags, prevHandling := cmd.stdout, cmd.output, cmd.Flags.Output(), cmd.errorHandling
+	cmd.stdout, cmd.output, cmd.errorHandling = stdout, stderr, ContinueOnError
+	cmd.Flags.SetOutput(stderr)
+
+	restores := []func(){}
+	for _, subCmd := range cmd.SubCommands {
+		restores = append(restores, subCmd.redirect(stdout, stderr))
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+		cmd.stdout, cmd.output, cmd.errorHandling = prevStdout, prevOutput, prevHandling
+		cmd.Flags.SetOutput(prevFlags)
+	}
+}
+
+// Call sends a request to run args to a command hierarchy being served
+// with Serve on conn. Output from the command is copied to stdout and
+// stderr as it arrives and the command's exit code is returned once it
+// has finished
+func Call(conn io.ReadWriter, args []string, stdout, stderr io.Writer) (code int, err error) {
+	if err = writeFrame(conn, frameRequest, []byte(strings.Join(args, "\x00"))); err != nil {
+		return 0, err
+	}
+
+	for {
+		typ, payload, err := readFrame(conn)
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return 0, err
+		}
+
+		switch typ {
+		case frameStdout:
+			_, err = stdout.Write(payload)
+		case frameStderr:
+			_, err = stderr.Write(payload)
+		case frameExit:
+			return strconv.Atoi(string(payload))
+		default:
+			err = fmt.Errorf("unexpected frame type %q", typ)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	root := New("app")
+	root.SubCommand("echo", FuncOption(func(a, b string) {
+		fmt.Fprintf(root.SubCommands[0].Stdout(), "%s %s", a, b)
+	}))
+	root.SubCommand("fail", CallbackOption(func(string, ...string) ([]string, error) { return nil, errors.New("failed") }))
+	verbose := false
+	say := root.SubCommand("say", FuncOption(func(s string) {
+		fmt.Fprintf(root.Stdout(), "%v %s", verbose, s)
+	}))
+	say.Flags.BoolVar(&verbose, "v", false, "verbose")
+	root.LazyCommand("lazy", func() *Command { return New("lazy", FuncOption(func() {})) })
+
+	client, server := net.Pipe()
+	done := make(chan error)
+	go func() { done <- Serve(root, server); server.Close() }()
+
+	tests := []struct {
+		desc       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"success", []string{"echo", "hello", "world"}, 0, "hello world", ""},
+		{"failure", []string{"fail"}, 1, "", "failed\n"},
+		{"usage", []string{"foo"}, 2, "", "Invalid Usage Unknown command \"foo\"\nUsage: app "},
+		{"flag set", []string{"say", "-v", "x"}, 0, "true x", ""},
+		{"flag reset", []string{"say", "y"}, 0, "false y", ""},
+		{"lazy", []string{"lazy"}, 0, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code, err := Call(client, test.args, stdout, stderr)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if test.wantCode != code {
+				t.Errorf("Wanted exit code %d got %d", test.wantCode, code)
+			}
+
+			if test.wantStdout != stdout.String() {
+				t.Errorf("Wanted stdout %q got %q", test.wantStdout, stdout.String())
+			}
+
+			if !strings.HasPrefix(stderr.String(), test.wantStderr) {
+				t.Errorf("Wanted stderr to start with %q got %q", test.wantStderr, stderr.String())
+			}
+		})
+	}
+
+	client.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if root.errorHandling != ExitOnError || root.SubCommands[0].stdout != nil {
+		t.Errorf("Expected settings to be restored")
+	}
+
+	if lazy := subCommands(root.SubCommands).get("lazy"); lazy.factory != nil || lazy.stdout != root.stdout || lazy.output != root.output {
+		t.Errorf("Expected lazy command settings to be restored")
+	}
+
+	if verbose {
+		t.Errorf("Expected flags to be reset")
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		desc    string
+		input   []byte
+		wantErr error
+	}{
+		{"empty", nil, io.EOF},
+		{"short header", []byte{'o', 0}, io.ErrUnexpectedEOF},
+		{"short payload", []byte{'o', 0, 0, 0, 2, 'a'}, io.ErrUnexpectedEOF},
+		{"too large", []byte{'o', 0xff, 0, 0, 0}, ErrFrameSize},
+		{"valid", []byte{'o', 0, 0, 0, 1, 'a'}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, _, err := readFrame(bytes.NewReader(test.input))
+			if test.wantErr != err {
+				t.Errorf("Wanted %v got %v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Stats are the usage counts recorded by StatsOption, by command path,
+// such as "app remote add", and by flag, such as "app remote add -v"
+type Stats struct {
+	Commands map[string]int `json:"commands"`
+	Flags    map[string]int `json:"flags"`
+}
+
+type stats struct {
+	file string
+	skip map[string]bool
+}
+
+// StatsOption counts how often each subcommand and flag is used in
+// successful runs of the command, storing the counts in file, which is
+// normally in the application's state directory (see Paths). Only the
+// names of commands and flags are recorded, never their arguments or
+// values. The counts help decide what to deprecate or remove.
+// StatsOption should be set on the root command only. Failing to record
+// the counts prints a warning but does not fail the command
+func StatsOption(file string) Option {
+	return func(cmd *Command) {
+		cmd.stats = &stats{file: file, skip: make(map[string]bool)}
+	}
+}
+
+// LoadStats reads the usage counts recorded by StatsOption in file
+func LoadStats(file string) (Stats, error) {
+	s := Stats{Commands: map[string]int{}, Flags: map[string]int{}}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err == nil {
+		err = json.Unmarshal(data, &s)
+	}
+
+	if s.Commands == nil {
+		s.Commands = map[string]int{}
+	}
+
+	if s.Flags == nil {
+		s.Flags = map[string]int{}
+	}
+	return s, err
+}
+
+// PurgeStats deletes the usage counts recorded by StatsOption in file
+func PurgeStats(file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *stats) record(cmd *Command, args []string) error {
+	path := cmd.Name
+	flags := []string{}
+	for _, token := range cmd.Classify(args) {
+		switch token.Kind {
+		case TokenCommand:
+			if path == cmd.Name && s.skip[token.Arg] {
+				return nil
+			}
+			path += " " + token.Arg
+		case TokenFlag:
+			name := strings.TrimLeft(token.Arg, "-")
+			if i := strings.Index(name, "="); i >= 0 {
+				name = name[:i]
+			}
+			flags = append(flags, path+" -"+name)
+		}
+	}
+
+	counts, err := LoadStats(s.file)
+	if err != nil {
+		return err
+	}
+
+	counts.Commands[path]++
+	for _, f := range flags {
+		counts.Flags[f]++
+	}
+
+	data, err := json.MarshalIndent(counts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := MkdirAll(filepath.Dir(s.file), 0700); err != nil {
+		return err
+	}
+	return WriteFileAtomic(s.file, append(data, '\n'), 0600)
+}
+
+// Write writes the counts to w, most used first
+func (s Stats) Write(w io.Writer) error {
+	sections := []struct {
+		title  string
+		counts map[string]int
+	}{{"Commands", s.Commands}, {"Flags", s.Flags}}
+
+	for i, section := range sections {
+		names := make([]string, 0, len(section.counts))
+		for name := range section.counts {
+			names = append(names, name)
+		}
+
+		sort.Slice(names, func(i, j int) bool {
+			if section.counts[names[i]] == section.counts[names[j]] {
+				return names[i] < names[j]
+			}
+			return section.counts[names[i]] > section.counts[names[j]]
+		})
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s:\n", section.title); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%6d  %s\n", section.counts[name], name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addStatsCommand adds the "stats" command to root, which prints the
+// usage counts or, with -purge, deletes them. The command is not
+// itself counted
+func addStatsCommand(root *Command) {
+	root.stats.skip["stats"] = true
+	purge := false
+	cmd := root.SubCommand("stats",
+		DescOption("Show how often commands and flags are used"),
+		FuncOption(func() error {
+			if purge {
+				return PurgeStats(root.stats.file)
+			}
+
+			counts, err := LoadStats(root.stats.file)
+			if err != nil {
+				return err
+			}
+			return counts.Write(root.Stdout())
+		}),
+	)
+	cmd.Flags.BoolVar(&purge, "purge", purge, "delete the recorded counts")
+}
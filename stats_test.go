@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "state", "stats.json")
+	stdout := &strings.Builder{}
+	root := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}), StdoutOption(stdout))
+	remote := root.SubCommand("remote")
+	add := remote.SubCommand("add", CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+	add.Flags.Bool("v", false, "verbose")
+	add.Flags.String("name", "", "remote name")
+	AddBuiltins(root, Builtins{Stats: file})
+
+	for _, args := range [][]string{
+		{"remote", "add", "-v", "-name", "origin"},
+		{"remote", "add", "-name=upstream"},
+		{"remote", "add"},
+		{"stats"},
+		{"remote", "missing"},
+	} {
+		root.Run(args)
+	}
+
+	want := Stats{
+		Commands: map[string]int{"app remote add": 3},
+		Flags:    map[string]int{"app remote add -v": 1, "app remote add -name": 2},
+	}
+
+	got, err := LoadStats(file)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Wanted %v got %v", want, got)
+	}
+
+	stdout.Reset()
+	if _, err := root.Run([]string{"stats"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	wantOutput := "Commands:\n     3  app remote add\n\nFlags:\n     2  app remote add -name\n     1  app remote add -v\n"
+	if stdout.String() != wantOutput {
+		t.Errorf("Wanted %q got %q", wantOutput, stdout.String())
+	}
+
+	if _, err := root.Run([]string{"stats", "-purge"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Wanted stats to be purged got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// StdinIsPiped reports whether os.Stdin is a pipe or a redirected file
+// rather than a terminal
+func StdinIsPiped() bool {
+	return isPiped(os.Stdin)
+}
+
+// isPiped reports whether reader supplies data without the user typing
+// it. Readers that are not files, such as those given to SetStdin, are
+// always considered piped
+func isPiped(reader io.Reader) bool {
+	if file, ok := reader.(*os.File); ok {
+		return !isTerminal(file)
+	}
+	return true
+}
+
+// ReadStdin reads all of os.Stdin, see ReadAll
+func ReadStdin(ctx context.Context, limit int64) ([]byte, error) {
+	return ReadAll(ctx, os.Stdin, limit)
+}
+
+// ReadAll reads from reader until the end of the input. If limit is
+// greater than zero and the input is larger than limit bytes then
+// ErrInputTooLarge is returned. If ctx is done before all of the input
+// is read then the context's error is returned. In that case the read
+// from reader is abandoned but it is not interrupted
+func ReadAll(ctx context.Context, reader io.Reader, limit int64) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		r := reader
+		if limit > 0 {
+			r = io.LimitReader(reader, limit+1)
+		}
+
+		data, err := ioutil.ReadAll(r)
+		if err == nil && limit > 0 && int64(len(data)) > limit {
+			data, err = data[:limit], fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, limit)
+		}
+		ch <- result{data, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ReadInput implements the convention of accepting input either from a
+// file named by a positional argument or piped to the command's Stdin.
+// The file is read when filename is not empty, and Stdin is read when
+// filename is empty or "-". A named file is read even when Stdin is not
+// a terminal, since that is also the case when running from cron, CI
+// or a server. A UserError wrapping ErrNoInput is returned when neither
+// is given. Readers given to SetStdin that are not files are always
+// considered piped
+func (cmd *Command) ReadInput(ctx context.Context, filename string, limit int64) ([]byte, error) {
+	stdin := cmd.Stdin()
+	piped := isPiped(stdin)
+	switch {
+	case filename == "-":
+		return ReadAll(ctx, stdin, limit)
+	case filename != "":
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, &UserError{err}
+		}
+		defer file.Close()
+		return ReadAll(ctx, file, limit)
+	case piped:
+		return ReadAll(ctx, stdin, limit)
+	}
+	return nil, &UserError{fmt.Errorf("%w: name a file or pipe input to %s", ErrNoInput, cmd.Name)}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	tests := []struct {
+		desc    string
+		input   string
+		limit   int64
+		want    string
+		wantErr error
+	}{
+		{"no limit", "hello world", 0, "hello world", nil},
+		{"under limit", "hello", 5, "hello", nil},
+		{"over limit", "hello world", 5, "hello", ErrInputTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := ReadAll(context.Background(), strings.NewReader(test.input), test.limit)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestReadAllCanceled(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadAll(ctx, reader, 0); err != context.Canceled {
+		t.Errorf("Wanted %v got %v", context.Canceled, err)
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stdin")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "input")
+	ioutil.WriteFile(filename, []byte("from file"), 0600)
+
+	terminal, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer terminal.Close()
+
+	tests := []struct {
+		desc     string
+		stdin    io.Reader
+		filename string
+		want     string
+		wantErr  error
+	}{
+		{"piped", strings.NewReader("from stdin"), "", "from stdin", nil},
+		{"dash", strings.NewReader("from stdin"), "-", "from stdin", nil},
+		{"file", terminal, filename, "from file", nil},
+		{"both", strings.NewReader("from stdin"), filename, "from file", nil},
+		{"neither", terminal, "", "", ErrNoInput},
+		{"missing file", terminal, filepath.Join(dir, "missing"), "", os.ErrNotExist},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app", StdinOption(test.stdin))
+			got, err := cmd.ReadInput(context.Background(), test.filename, 0)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			var ue *UserError
+			if err != nil && !errors.As(err, &ue) {
+				t.Errorf("Expected a UserError got %T", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
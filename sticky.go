@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// stickyValues are the remembered flag values, by command name and
+// then by flag name
+type stickyValues map[string]map[string]string
+
+type sticky struct {
+	file  string
+	names []string
+}
+
+// StickyFlagsOption remembers the values given to the named flags of
+// the command in file, which is normally in the application's state
+// directory (see Paths). When a flag is not given, the value from the
+// last successful run that gave it is used as its default. Values are
+// stored by command name, so commands sharing a file should have
+// distinct names. Flags created with Secret are never remembered.
+// Failing to load or save the values prints a warning but does not
+// fail the command
+func StickyFlagsOption(file string, names ...string) Option {
+	return func(cmd *Command) {
+		cmd.sticky = &sticky{file: file, names: names}
+	}
+}
+
+func loadSticky(file string) (stickyValues, error) {
+	values := stickyValues{}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return values, nil
+	} else if err == nil {
+		err = json.Unmarshal(data, &values)
+	}
+	return values, err
+}
+
+func saveSticky(file string, values stickyValues) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return WriteFileAtomic(file, append(data, '\n'), 0600)
+}
+
+// apply sets the flags of cmd to their remembered values
+func (s *sticky) apply(cmd *Command) error {
+	values, err := loadSticky(s.file)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range s.names {
+		value, found := values[cmd.Name][name]
+		if f := cmd.Flags.Lookup(name); found && f != nil {
+			if err := f.Value.Set(value); err != nil {
+				return fmt.Errorf("-%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// save remembers the values of the flags that were given to cmd
+func (s *sticky) save(cmd *Command) error {
+	changed := map[string]string{}
+	for _, name := range s.names {
+		if f := cmd.Flags.Lookup(name); f != nil && cmd.Changed(name) && !isSecret(f.Value) {
+			changed[name] = f.Value.String()
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	values, err := loadSticky(s.file)
+	if err != nil {
+		return err
+	}
+
+	if values[cmd.Name] == nil {
+		values[cmd.Name] = map[string]string{}
+	}
+
+	for name, value := range changed {
+		values[cmd.Name][name] = value
+	}
+	return saveSticky(s.file, values)
+}
+
+// UnsetStickyFlags forgets the values remembered in file by
+// StickyFlagsOption for the named flags of command. All of the
+// command's flags are forgotten if no names are given, and every
+// command's flags are forgotten if command is empty
+func UnsetStickyFlags(file, command string, names ...string) error {
+	values, err := loadSticky(file)
+	if err != nil {
+		return err
+	}
+
+	if command == "" {
+		values = stickyValues{}
+	} else if len(names) == 0 {
+		delete(values, command)
+	} else {
+		for _, name := range names {
+			delete(values[command], name)
+		}
+
+		if len(values[command]) == 0 {
+			delete(values, command)
+		}
+	}
+	return saveSticky(file, values)
+}
+
+// addStickyCommands adds "config unset" to root to forget remembered
+// flag values
+func addStickyCommands(root *Command, file string) {
+	config := root.SubCommand("config", DescOption("Manage remembered flag values"))
+	config.SubCommand("unset",
+		DescOption("Forget the remembered flag values of a command, or of all commands"),
+		UsageOption("[command [flag...]]"),
+		CallbackOption(func(name string, args ...string) ([]string, error) {
+			command := ""
+			if len(args) > 0 {
+				command, args = args[0], args[1:]
+			}
+			return nil, UnsetStickyFlags(file, command, args...)
+		}),
+	)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStickyFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sticky")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "state", "flags.json")
+	tests := []struct {
+		desc       string
+		args       []string
+		wantRegion string
+		wantToken  string
+	}{
+		{"default", []string{"deploy"}, "local", ""},
+		{"given", []string{"deploy", "-region", "us", "-token", "secret"}, "us", "secret"},
+		{"remembered", []string{"deploy"}, "us", ""},
+		{"changed", []string{"deploy", "-region", "eu"}, "eu", ""},
+		{"remembered change", []string{"deploy"}, "eu", ""},
+		{"unset flag", []string{"config", "unset", "deploy", "region"}, "local", ""},
+		{"forgotten", []string{"deploy"}, "local", ""},
+		{"given again", []string{"deploy", "-region", "us"}, "us", ""},
+		{"unset all", []string{"config", "unset"}, "local", ""},
+		{"all forgotten", []string{"deploy"}, "local", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			region, token := "", ""
+			root := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(&strings.Builder{}))
+			deploy := root.SubCommand("deploy", StickyFlagsOption(file, "region", "token"), CallbackOption(func(string, ...string) ([]string, error) { return nil, nil }))
+			deploy.Flags.StringVar(&region, "region", "local", "region to deploy to")
+			deploy.Flags.Var(SecretString(&token), "token", "access token")
+			AddBuiltins(root, Builtins{StickyFlags: file})
+
+			if _, err := root.Run(test.args); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if test.args[0] == "deploy" && test.wantRegion != region {
+				t.Errorf("Wanted region %q got %q", test.wantRegion, region)
+			}
+
+			if test.wantToken != token {
+				t.Errorf("Wanted token %q got %q", test.wantToken, token)
+			}
+		})
+	}
+}
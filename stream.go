@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONStream writes values as JSON Lines, one JSON document per line,
+// so that long running commands can emit progressive, structured
+// output that other programs can consume as it is produced
+type JSONStream struct {
+	mu     sync.Mutex
+	writer io.Writer
+	enc    *json.Encoder
+}
+
+// NewJSONStream returns a JSONStream writing to w. A JSONStream is safe
+// to use from multiple goroutines
+func NewJSONStream(w io.Writer) *JSONStream {
+	return &JSONStream{writer: w, enc: json.NewEncoder(w)}
+}
+
+// Emit writes v to the stream as a single line of JSON. If the
+// underlying writer buffers its output, such as a bufio.Writer or an
+// http.ResponseWriter, it is flushed so the line is seen immediately
+func (js *JSONStream) Emit(v interface{}) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if err := js.enc.Encode(v); err != nil {
+		return err
+	}
+
+	switch f := js.writer.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJSONStream(t *testing.T) {
+	builder := &strings.Builder{}
+	w := bufio.NewWriter(builder)
+	stream := NewJSONStream(w)
+
+	if err := stream.Emit(map[string]int{"done": 1}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "{\"done\":1}\n"
+	if got := builder.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+
+	if err := stream.Emit(func() {}); err == nil {
+		t.Errorf("Expected an error for a value that can not be encoded")
+	}
+}
+
+func TestJSONStreamConcurrent(t *testing.T) {
+	builder := &strings.Builder{}
+	stream := NewJSONStream(builder)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stream.Emit(struct{ N int }{i})
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(builder.String()), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("Wanted 10 lines got %d", len(lines))
+	}
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{\"N\":") || !strings.HasSuffix(line, "}") {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+}
@@ -32,3 +32,57 @@ func (s subCommands) get(name string) *Command {
 func (s subCommands) sort() {
 	sort.Sort(s)
 }
+
+// sorted returns a sorted copy of s, leaving s unchanged
+func (s subCommands) sorted() subCommands {
+	c := append(subCommands{}, s...)
+	c.sort()
+	return c
+}
+
+// usageLayout holds the parts of a command's usage that depend only on
+// its subcommands, so that large hierarchies do not sort and measure
+// their subcommands every time usage is displayed
+type usageLayout struct {
+	source subCommands
+	names  []string
+	sorted subCommands
+	maxLen int
+}
+
+// valid reports whether the layout was computed for s, which is not the
+// case once subcommands have been added, removed or renamed
+func (ul *usageLayout) valid(s []*Command) bool {
+	if ul == nil || len(ul.source) != len(s) {
+		return false
+	}
+
+	for i, cmd := range s {
+		if ul.source[i] != cmd || ul.names[i] != cmd.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// layout returns the usage layout of cmd, computing it again when the
+// subcommands have changed since it was last computed
+func (cmd *Command) layout() *usageLayout {
+	cmd.layoutMu.Lock()
+	defer cmd.layoutMu.Unlock()
+	if !cmd.usageLayout.valid(cmd.SubCommands) {
+		s := subCommands(cmd.SubCommands)
+		names := make([]string, len(s))
+		for i, subCmd := range s {
+			names[i] = subCmd.Name
+		}
+
+		cmd.usageLayout = &usageLayout{
+			source: append(subCommands{}, s...),
+			names:  names,
+			sorted: s.sorted(),
+			maxLen: s.maxLen(),
+		}
+	}
+	return cmd.usageLayout
+}
@@ -63,3 +63,28 @@ func TestSubCommandsGet(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageLayout(t *testing.T) {
+	cmd := New("app")
+	cmd.SubCommand("foo")
+	cmd.SubCommand("ab")
+
+	layout := cmd.layout()
+	if layout.maxLen != 3 || layout.sorted[0].Name != "ab" {
+		t.Errorf("Wanted maxLen 3 and ab first got %d and %q", layout.maxLen, layout.sorted[0].Name)
+	}
+
+	if cmd.layout() != layout {
+		t.Errorf("Expected the layout to be cached")
+	}
+
+	cmd.SubCommand("abcdef")
+	if layout = cmd.layout(); layout.maxLen != 6 || len(layout.sorted) != 3 {
+		t.Errorf("Expected adding a command to invalidate the layout")
+	}
+
+	cmd.SubCommands[0].Name = "a"
+	if layout = cmd.layout(); layout.sorted[0].Name != "a" {
+		t.Errorf("Expected renaming a command to invalidate the layout")
+	}
+}
@@ -0,0 +1,236 @@
+// Package table renders rows of data as aligned text columns
+package table
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	ErrNotSlice     = errors.New("Value is not a slice of structs")
+	ErrUnknownField = errors.New("Unknown column")
+)
+
+// Table is a set of rows with a header for each column
+type Table struct {
+	Headers []string
+	Rows    [][]string
+
+	// Widths limits the width of each column. Values longer than the
+	// width are truncated and shorter values are padded. A width of
+	// zero leaves the column unlimited
+	Widths []int
+
+	// NoHeaders omits the headers when the table is written
+	NoHeaders bool
+}
+
+// rows returns the rows to write, starting with the headers unless
+// they are omitted
+func (t *Table) rows() [][]string {
+	if t.NoHeaders || len(t.Headers) == 0 {
+		return t.Rows
+	}
+	return append([][]string{t.Headers}, t.Rows...)
+}
+
+// Write writes the table to w with the columns aligned
+func (t *Table) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range t.rows() {
+		t.writeRow(tw, row)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the table to w as comma separated values, quoting
+// values as necessary. Widths are ignored
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.WriteAll(t.rows())
+	return cw.Error()
+}
+
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// WriteTSV writes the table to w as tab separated values. Tabs,
+// newlines and backslashes in values are escaped as \t, \n and \\ so
+// that every row is a single line. Widths are ignored
+func (t *Table) WriteTSV(w io.Writer) error {
+	for _, row := range t.rows() {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = tsvEscaper.Replace(cell)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\n", strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) writeRow(w io.Writer, row []string) {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		// tabs separate the columns for the tabwriter
+		cell = strings.Replace(cell, "\t", " ", -1)
+		if i < len(t.Widths) && t.Widths[i] > 0 {
+			cell = fit(cell, t.Widths[i])
+		}
+		cells[i] = cell
+	}
+	fmt.Fprintf(w, "%s\n", strings.TrimRight(strings.Join(cells, "\t"), " "))
+}
+
+// fit truncates or pads s to exactly width runes
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		if width > 1 {
+			return string(runes[:width-1]) + "…"
+		}
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+type column struct {
+	header string
+	index  []int
+	width  int
+}
+
+// FromSlice builds a table from v, which must be a slice of structs or
+// of pointers to structs. Every exported field becomes a column unless
+// columns names the headers of the columns to include, in order. The
+// header and width of a column are set with a struct tag:
+//
+//	type Host struct {
+//		Name    string `table:"Name,width=20"`
+//		Address string `table:"IP Address"`
+//		Secret  string `table:"-"`
+//	}
+//
+// Fields without a tag use the field name as the header. Values are
+// formatted with fmt.Sprint and nil pointers are displayed as empty
+// cells
+func FromSlice(v interface{}, columns ...string) (*Table, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%w: %T", ErrNotSlice, v)
+	}
+
+	elem := value.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotSlice, v)
+	}
+
+	cols, err := selectColumns(structColumns(elem, nil), columns)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{}
+	for _, col := range cols {
+		t.Headers = append(t.Headers, col.header)
+		t.Widths = append(t.Widths, col.width)
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		row := make([]string, len(cols))
+		item := reflect.Indirect(value.Index(i))
+		if item.IsValid() {
+			for j, col := range cols {
+				row[j] = format(item, col.index)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// structColumns returns a column for every exported field of t,
+// including the fields of embedded structs
+func structColumns(t reflect.Type, index []int) []column {
+	cols := []column{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		tag, tagged := field.Tag.Lookup("table")
+		if tag == "-" {
+			continue
+		}
+
+		// the exported fields of embedded structs are promoted, even
+		// when the embedded struct is unexported
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !tagged {
+			cols = append(cols, structColumns(field.Type, fieldIndex)...)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		col := column{header: field.Name, index: fieldIndex}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			col.header = parts[0]
+		}
+
+		for _, part := range parts[1:] {
+			if strings.HasPrefix(part, "width=") {
+				col.width, _ = strconv.Atoi(strings.TrimPrefix(part, "width="))
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// selectColumns returns the named columns in order, or all columns if
+// no names are given. Names are compared case insensitively
+func selectColumns(cols []column, names []string) ([]column, error) {
+	if len(names) == 0 {
+		return cols, nil
+	}
+
+	selected := []column{}
+	for _, name := range names {
+		found := false
+		for _, col := range cols {
+			if strings.EqualFold(col.header, name) {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w %q", ErrUnknownField, name)
+		}
+	}
+	return selected, nil
+}
+
+func format(item reflect.Value, index []int) string {
+	field := item.FieldByIndex(index)
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,110 @@
+package table
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type base struct {
+	ID int
+}
+
+type host struct {
+	base
+	Name    string `table:"Name,width=6"`
+	Address string `table:"IP Address"`
+	Port    *int
+	secret  string
+	Token   string `table:"-"`
+}
+
+func TestFromSlice(t *testing.T) {
+	port := 22
+	hosts := []*host{
+		{base: base{1}, Name: "alpha", Address: "10.0.0.1", Port: &port, secret: "s", Token: "t"},
+		{base: base{2}, Name: "bravo-long", Address: "10.0.0.2"},
+		nil,
+	}
+
+	tests := []struct {
+		desc        string
+		columns     []string
+		wantHeaders []string
+		wantRows    [][]string
+		wantErr     error
+	}{
+		{"all columns", nil, []string{"ID", "Name", "IP Address", "Port"}, [][]string{{"1", "alpha", "10.0.0.1", "22"}, {"2", "bravo-long", "10.0.0.2", ""}, {"", "", "", ""}}, nil},
+		{"selected columns", []string{"ip address", "id"}, []string{"IP Address", "ID"}, [][]string{{"10.0.0.1", "1"}, {"10.0.0.2", "2"}, {"", ""}}, nil},
+		{"unknown column", []string{"Token"}, nil, nil, ErrUnknownField},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			table, err := FromSlice(hosts, test.columns...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Wanted error %v got %v", test.wantErr, err)
+			} else if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(test.wantHeaders, table.Headers) {
+				t.Errorf("Wanted headers %q got %q", test.wantHeaders, table.Headers)
+			}
+
+			if !reflect.DeepEqual(test.wantRows, table.Rows) {
+				t.Errorf("Wanted rows %q got %q", test.wantRows, table.Rows)
+			}
+		})
+	}
+}
+
+func TestFromSliceNotSlice(t *testing.T) {
+	for _, v := range []interface{}{host{}, []int{1}} {
+		if _, err := FromSlice(v); !errors.Is(err, ErrNotSlice) {
+			t.Errorf("Wanted %v got %v", ErrNotSlice, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	table, _ := FromSlice([]host{{Name: "alpha", Address: "10.0.0.1"}, {Name: "bravo-long", Address: "10.0.0.2"}}, "Name", "IP Address")
+	builder := &strings.Builder{}
+	if err := table.Write(builder); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "Name    IP Address\nalpha   10.0.0.1\nbravo…  10.0.0.2\n"
+	if got := builder.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	table := &Table{Headers: []string{"Name", "Note"}, Rows: [][]string{{"alpha", "a, \"quoted\"\tvalue"}}}
+	tests := []struct {
+		desc      string
+		noHeaders bool
+		write     func(*Table, *strings.Builder) error
+		want      string
+	}{
+		{"csv", false, func(t *Table, b *strings.Builder) error { return t.WriteCSV(b) }, "Name,Note\nalpha,\"a, \"\"quoted\"\"\tvalue\"\n"},
+		{"tsv", false, func(t *Table, b *strings.Builder) error { return t.WriteTSV(b) }, "Name\tNote\nalpha\ta, \"quoted\"\\tvalue\n"},
+		{"no headers", true, func(t *Table, b *strings.Builder) error { return t.Write(b) }, "alpha  a, \"quoted\" value\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			table.NoHeaders = test.noHeaders
+			builder := &strings.Builder{}
+			if err := test.write(table, builder); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if got := builder.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import "fmt"
+
+// Theme controls how interactive prompts are displayed by Query,
+// Confirm, Select and Wizard
+type Theme struct {
+	// Prefix is written before every prompt message, for instance "? "
+	Prefix string
+
+	// Invalid is the message displayed when input is not accepted
+	Invalid string
+
+	// Cursor marks the default choice of Select
+	Cursor string
+
+	// PromptColor and ErrorColor are ANSI SGR parameters, such as "1;36",
+	// used for prompts and invalid input messages when Colors is set
+	PromptColor string
+	ErrorColor  string
+
+	// sanitize is set from the command, see SanitizeOption
+	sanitize SanitizeMode
+}
+
+// DefaultTheme is used by the prompt functions and by commands that
+// have not been given a theme with ThemeOption
+var DefaultTheme = Theme{
+	Invalid:    "Invalid input",
+	Cursor:     ">",
+	ErrorColor: "31",
+}
+
+// ThemeOption sets the Theme used by the prompts of the command.
+// Subcommands created after the theme has been set inherit it
+func ThemeOption(theme Theme) Option {
+	return func(cmd *Command) { cmd.theme = &theme }
+}
+
+// Theme returns the prompt theme of the command
+func (cmd *Command) Theme() Theme {
+	theme := DefaultTheme
+	if cmd.theme != nil {
+		theme = *cmd.theme
+	}
+	theme.sanitize = cmd.sanitize
+	return theme
+}
+
+func (th Theme) style(color, s string) string {
+	if Colors && color != "" {
+		return colorize(color, s)
+	}
+	return s
+}
+
+// prompt returns the text displayed for a prompt message
+func (th Theme) prompt(message string) string {
+	return th.style(th.PromptColor, th.Prefix+message)
+}
+
+// invalid returns the line displayed when input is not accepted, err
+// may be nil
+func (th Theme) invalid(err error) string {
+	msg := th.Invalid
+	if msg == "" {
+		msg = DefaultTheme.Invalid
+	}
+
+	if err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, err)
+	}
+	return th.style(th.ErrorColor, msg) + "\n"
+}
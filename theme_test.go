@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTheme(t *testing.T) {
+	theme := Theme{Prefix: "? ", Invalid: "Nope", Cursor: "*", PromptColor: "36", ErrorColor: "31"}
+	tests := []struct {
+		desc   string
+		colors bool
+		run    func(cmd *Command) string
+		want   string
+	}{
+		{"query", false, func(cmd *Command) string { return cmd.Query("pick: ", "a") }, "? pick: Nope\n? pick: "},
+		{"confirm", false, func(cmd *Command) string { cmd.Confirm("ok?", false); return "" }, "? ok? [y/N] Nope\n? ok? [y/N] Nope\n? ok? [y/N] "},
+		{"select", false, func(cmd *Command) string { cmd.Select("pick", []string{"a"}, 0); return "" }, "? pick\n* 1) a\n? choice: Nope: \"b\" is not one of the choices\n? choice: "},
+		{"colors", true, func(cmd *Command) string { return cmd.Query("pick: ", "a") }, "\x1b[36m? pick: \x1b[0m\x1b[31mNope\x1b[0m\n\x1b[36m? pick: \x1b[0m"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			Colors = test.colors
+			defer func() { Colors = false }()
+
+			stdout := &strings.Builder{}
+			root := New("app", ThemeOption(theme), StdoutOption(stdout), StdinOption(strings.NewReader("b\na\n")))
+			sub := root.SubCommand("sub")
+			test.run(sub)
+			if got := stdout.String(); test.want != got {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestThemeDefaults(t *testing.T) {
+	if got := New("app").Theme(); got != DefaultTheme {
+		t.Errorf("Wanted %+v got %+v", DefaultTheme, got)
+	}
+
+	want := "Invalid input: oops\n"
+	if got := (Theme{}).invalid(errors.New("oops")); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TokenKind describes how a command line argument was interpreted
+type TokenKind int
+
+const (
+	TokenFlag        TokenKind = iota // A flag such as -v or -name=value
+	TokenFlagValue                    // The value of the preceding flag
+	TokenCommand                      // The name of a subcommand
+	TokenPositional                   // A positional argument
+	TokenPassthrough                  // The -- terminator and everything after it
+)
+
+func (tk TokenKind) String() string {
+	switch tk {
+	case TokenFlag:
+		return "flag"
+	case TokenFlagValue:
+		return "flag value"
+	case TokenCommand:
+		return "command"
+	case TokenPositional:
+		return "positional"
+	case TokenPassthrough:
+		return "passthrough"
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(tk))
+}
+
+// Token is a classified command line argument
+type Token struct {
+	Arg  string
+	Kind TokenKind
+
+	// Command is the name of the command that consumes the token
+	Command string
+}
+
+// Classify reports how each of args would be interpreted when running
+// the command, without parsing any flags or running any callbacks.
+// Flags are classified by the command that would parse them, so a flag
+// that is not defined is still classified as a flag of the command it
+// was given to
+func (cmd *Command) Classify(args []string) []Token {
+	if cmd.resolvePath {
+		args = cmd.resolveArgs(args)
+	}
+
+	tokens := []Token{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			for _, arg := range args[i:] {
+				tokens = append(tokens, Token{arg, TokenPassthrough, cmd.Name})
+			}
+			return tokens
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			tokens = append(tokens, Token{arg, TokenFlag, cmd.Name})
+			name := strings.TrimLeft(arg, "-")
+			if f := cmd.Flags.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				tokens = append(tokens, Token{args[i], TokenFlagValue, cmd.Name})
+			}
+		default:
+			if subCmd, found := cmd.Lookup(arg); found {
+				tokens = append(tokens, Token{arg, TokenCommand, cmd.Name})
+				return append(tokens, subCmd.Classify(args[i+1:])...)
+			}
+
+			for _, arg := range args[i:] {
+				tokens = append(tokens, Token{arg, TokenPositional, cmd.Name})
+			}
+			return tokens
+		}
+	}
+	return tokens
+}
+
+// resolvedPath returns the command path that args resolve to, starting
+// with cmd
+func (cmd *Command) resolvedPath(args []string) []*Command {
+	path := []*Command{cmd}
+	for _, token := range cmd.Classify(args) {
+		if token.Kind == TokenCommand {
+			subCmd, _ := path[len(path)-1].Lookup(token.Arg)
+			path = append(path, subCmd)
+		}
+	}
+	return path
+}
+
+// TraceOption prints, to the command's Output, how each argument was
+// classified and which command consumed it, as the command runs. The
+// option is inherited by subcommands created after it is applied.
+// Tracing can also be enabled by setting the CLI_TRACE environment
+// variable to 1
+func TraceOption() Option {
+	return func(cmd *Command) { cmd.trace = true }
+}
+
+func (cmd *Command) tracing() bool {
+	return cmd.trace || os.Getenv("CLI_TRACE") == "1"
+}
+
+// printTrace prints the tokens in args that are consumed by cmd, which
+// ends with the name of the subcommand, if there is one
+func (cmd *Command) printTrace(args []string) {
+	for _, token := range cmd.Classify(args) {
+		fmt.Fprintf(cmd.Output(), "trace: %s: %q %v\n", token.Command, token.Arg, token.Kind)
+		if token.Kind == TokenCommand {
+			break
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandClassify(t *testing.T) {
+	tests := []struct {
+		desc string
+		args []string
+		want []Token
+	}{
+		{"empty", nil, []Token{}},
+		{"flags", []string{"-v", "-config", "foo", "-name=bar"}, []Token{{"-v", TokenFlag, "app"}, {"-config", TokenFlag, "app"}, {"foo", TokenFlagValue, "app"}, {"-name=bar", TokenFlag, "app"}}},
+		{"subcommand", []string{"-v", "sub", "-n", "3", "a", "-b"}, []Token{{"-v", TokenFlag, "app"}, {"sub", TokenCommand, "app"}, {"-n", TokenFlag, "sub"}, {"3", TokenFlagValue, "sub"}, {"a", TokenPositional, "sub"}, {"-b", TokenPositional, "sub"}}},
+		{"passthrough", []string{"sub", "--", "-n"}, []Token{{"sub", TokenCommand, "app"}, {"--", TokenPassthrough, "sub"}, {"-n", TokenPassthrough, "sub"}}},
+		{"unknown command", []string{"foo"}, []Token{{"foo", TokenPositional, "app"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New("app")
+			cmd.Flags.Bool("v", false, "")
+			cmd.Flags.String("config", "", "")
+			sub := cmd.SubCommand("sub")
+			sub.Flags.Int("n", 0, "")
+
+			if got := cmd.Classify(test.args); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("Wanted %v got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestTraceOption(t *testing.T) {
+	output := &strings.Builder{}
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), OutputOption(output), TraceOption())
+	cmd.Flags.Bool("v", false, "")
+	cmd.SubCommand("sub", FuncOption(func(string) {}))
+
+	if _, err := cmd.Run([]string{"-v", "sub", "a"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	want := "trace: app: \"-v\" flag\ntrace: app: \"sub\" command\ntrace: sub: \"a\" positional\n"
+	if got := output.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
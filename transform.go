@@ -0,0 +1,37 @@
+package cli
+
+import "path/filepath"
+
+// Transform returns an ArgumentOption that applies transforms, in order,
+// to the input of an argument before the argument is set. For example:
+//
+//	args.StringVar(&name, "<name>", cli.Transform(strings.TrimSpace, strings.ToLower))
+func Transform(transforms ...func(string) string) ArgumentOption {
+	return func(arg *argument) {
+		arg.transforms = append(arg.transforms, transforms...)
+	}
+}
+
+type transformValue struct {
+	Value
+	arg *argument
+}
+
+func (tv *transformValue) Set(s string) error { return tv.Value.Set(tv.arg.transform(s)) }
+func (tv *transformValue) Unwrap() Value      { return tv.Value }
+
+// TransformValue wraps value so that transforms are applied, in order,
+// to its input before it is set. This is useful for applying the same
+// transforms as Transform to flags
+func TransformValue(value Value, transforms ...func(string) string) Value {
+	return &transformValue{Value: value, arg: &argument{transforms: transforms}}
+}
+
+// AbsPath is a transform that converts a relative path to an absolute
+// path. The input is returned unchanged if it can not be converted
+func AbsPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	args := &Arguments{}
+	s := args.String("<s>", Transform(strings.TrimSpace, strings.ToLower))
+	list := []int{}
+	args.VarSlice((*intSlice)(&list), "<n>...", Transform(func(s string) string { return strings.TrimPrefix(s, "#") }))
+
+	if err := args.Parse([]string{"  FOO ", "#1", "2", "#3"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if *s != "foo" {
+		t.Errorf("Wanted %q got %q", "foo", *s)
+	}
+
+	if got := (*intSlice)(&list).String(); got != "1,2,3" {
+		t.Errorf("Wanted %q got %q", "1,2,3", got)
+	}
+}
+
+func TestTransformValue(t *testing.T) {
+	var s string
+	cmd := New("app")
+	cmd.Flags.Var(TransformValue((*stringValue)(&s), strings.ToUpper), "s", "")
+	if err := cmd.Flags.Parse([]string{"-s", "foo"}); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if s != "FOO" {
+		t.Errorf("Wanted %q got %q", "FOO", s)
+	}
+}
+
+func TestAbsPath(t *testing.T) {
+	wd, _ := os.Getwd()
+	if got := AbsPath("foo"); got != filepath.Join(wd, "foo") {
+		t.Errorf("Wanted %q got %q", filepath.Join(wd, "foo"), got)
+	}
+
+	if got := AbsPath("/foo"); got != "/foo" {
+		t.Errorf("Wanted %q got %q", "/foo", got)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Errors is a collection of errors that is itself an error. It is
+// returned by functions, such as Validate, that report more than
+// one problem at a time
+type Errors []error
+
+func (e Errors) Error() string {
+	strs := make([]string, len(e))
+	for i, err := range e {
+		strs[i] = err.Error()
+	}
+	return strings.Join(strs, "\n")
+}
+
+// Is reports whether any of the collected errors matches target
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate walks the command hierarchy looking for common mistakes
+// such as duplicate subcommand names, commands that can never do
+// anything (no callback and no subcommands) and subcommands that are
+// missing a description. When flags are persistent, see
+// ResolvePathOption, flags that shadow the flag of an ancestor with a
+// different type are also reported. It is intended to be called from an
+// application's tests. A nil error is returned when no problems are
+// found, otherwise the returned error is of type Errors
+func (cmd *Command) Validate() error {
+	var errs Errors
+	cmd.validate(cmd.Name, &errs, nil)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// persistentFlag is a flag that is visible to the subcommands of the
+// command that defines it
+type persistentFlag struct {
+	path string
+	kind string
+}
+
+func (cmd *Command) validate(path string, errs *Errors, persistent map[string]persistentFlag) {
+	cmd.load()
+	if cmd.Callback == nil && len(cmd.SubCommands) == 0 {
+		*errs = append(*errs, fmt.Errorf("%s: %w", path, ErrNoAction))
+	}
+
+	if cmd.resolvePath || persistent != nil {
+		persistent = checkPersistent(&cmd.Flags, path, persistent, errs)
+	}
+
+	seen := make(map[string]bool)
+	for _, subCmd := range cmd.SubCommands {
+		subPath := path + " " + subCmd.Name
+		if seen[subCmd.Name] {
+			*errs = append(*errs, fmt.Errorf("%s: %w %q", path, ErrDuplicateCommand, subCmd.Name))
+			continue
+		}
+		seen[subCmd.Name] = true
+
+		if subCmd.Description == "" {
+			*errs = append(*errs, fmt.Errorf("%s: %w", subPath, ErrNoDescription))
+		}
+		subCmd.validate(subPath, errs, persistent)
+	}
+}
+
+// checkPersistent reports the flags that shadow a persistent flag with
+// a different type and returns the persistent flags visible to the
+// subcommands of path
+func checkPersistent(flags *flag.FlagSet, path string, persistent map[string]persistentFlag, errs *Errors) map[string]persistentFlag {
+	visible := make(map[string]persistentFlag, len(persistent))
+	for name, pf := range persistent {
+		visible[name] = pf
+	}
+
+	flags.VisitAll(func(f *flag.Flag) {
+		kind := valueType(f.Value)
+		if pf, found := persistent[f.Name]; found && pf.kind != kind {
+			*errs = append(*errs, fmt.Errorf("%s: %w -%s is a %s flag but %s defines it as a %s flag", path, ErrFlagConflict, f.Name, kind, pf.path, pf.kind))
+		}
+		visible[f.Name] = persistentFlag{path: path, kind: kind}
+	})
+	return visible
+}
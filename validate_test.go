@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cb := func(string, ...string) ([]string, error) { return nil, nil }
+
+	tests := []struct {
+		desc     string
+		setup    func(*Command)
+		wantErrs []error
+	}{
+		{"valid", func(cmd *Command) { cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb)) }, nil},
+		{"no action", func(cmd *Command) {}, []error{ErrNoAction}},
+		{"subcommand no action", func(cmd *Command) { cmd.SubCommand("foo", DescOption("foo")) }, []error{ErrNoAction}},
+		{"no description", func(cmd *Command) { cmd.SubCommand("foo", CallbackOption(cb)) }, []error{ErrNoDescription}},
+		{"duplicate", func(cmd *Command) {
+			cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb))
+			cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb))
+		}, []error{ErrDuplicateCommand}},
+		{"nested", func(cmd *Command) {
+			cmd.SubCommand("foo", DescOption("foo")).SubCommand("bar")
+		}, []error{ErrNoAction, ErrNoDescription}},
+		{"shadowed flag", func(cmd *Command) {
+			cmd.Flags.Int("n", 0, "")
+			cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb)).Flags.Int("n", 0, "")
+		}, nil},
+		{"persistent flag same type", func(cmd *Command) {
+			ResolvePathOption()(cmd)
+			cmd.Flags.Int("n", 0, "")
+			cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb)).Flags.Int("n", 0, "")
+		}, nil},
+		{"persistent flag different type", func(cmd *Command) {
+			ResolvePathOption()(cmd)
+			cmd.Flags.Int("n", 0, "")
+			foo := cmd.SubCommand("foo", DescOption("foo"))
+			foo.SubCommand("bar", DescOption("bar"), CallbackOption(cb)).Flags.String("n", "", "")
+		}, []error{ErrFlagConflict}},
+		{"persistent flag wrapped", func(cmd *Command) {
+			ResolvePathOption()(cmd)
+			var token string
+			cmd.Flags.String("token", "", "")
+			cmd.SubCommand("foo", DescOption("foo"), CallbackOption(cb)).Flags.Var(EnvDefault(SecretString(&token), "TOKEN"), "token", "")
+		}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cmd := New(test.desc, test.setup)
+			err := cmd.Validate()
+			if len(test.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected Errors got %T", err)
+			}
+
+			if len(test.wantErrs) != len(errs) {
+				t.Errorf("Wanted %d errors got %d: %v", len(test.wantErrs), len(errs), errs)
+			}
+
+			for _, want := range test.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Wanted error %v in %v", want, err)
+				}
+			}
+		})
+	}
+}
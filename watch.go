@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// clearScreen moves the cursor to the top left corner and clears the
+	// screen
+	clearScreen = "\x1b[H\x1b[2J"
+
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+)
+
+// Watch calls fn every interval until ctx is done or fn returns an
+// error. Each time, the screen is cleared and the output of fn is
+// written to w with the lines that changed since the previous call
+// highlighted. The cursor is hidden while watching and is shown again
+// when Watch returns, or when the program exits (see AtExit). Watch
+// returns nil when ctx is done, otherwise it returns the error from fn
+// or from writing to w
+func Watch(ctx context.Context, w io.Writer, interval time.Duration, fn func(io.Writer) error) error {
+	if _, err := io.WriteString(w, hideCursor); err != nil {
+		return err
+	}
+
+	remove := AtExit(func() { io.WriteString(w, showCursor) })
+	defer func() {
+		remove()
+		io.WriteString(w, showCursor)
+	}()
+
+	var previous []string
+	for {
+		builder := &strings.Builder{}
+		if err := fn(builder); err != nil {
+			return err
+		}
+
+		lines := strings.Split(strings.TrimSuffix(builder.String(), "\n"), "\n")
+		screen := &strings.Builder{}
+		screen.WriteString(clearScreen)
+		for i, line := range lines {
+			if previous != nil && (i >= len(previous) || previous[i] != line) {
+				line = colorize("7", line)
+			}
+			screen.WriteString(line + "\n")
+		}
+
+		if _, err := io.WriteString(w, screen.String()); err != nil {
+			return err
+		}
+		previous = lines
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// WatchOption adds a -watch flag to the command. When -watch is given
+// an interval, the command's callback is run repeatedly with Watch,
+// redrawing its output to Stdout, until it returns an error or the
+// program is interrupted
+func WatchOption() Option {
+	return func(cmd *Command) {
+		cmd.Flags.DurationVar(&cmd.watch, "watch", 0, "re-run the command at this interval")
+	}
+}
+
+// watchCallback runs the callback with Watch, writing its output, and
+// its result in the format selected with OutputFormatOption, to the
+// screen instead of Stdout
+func (cmd *Command) watchCallback(args []string) (remaining []string, err error) {
+	stdout := cmd.Stdout()
+	err = Watch(context.Background(), stdout, cmd.watch, func(w io.Writer) error {
+		saved := cmd.stdout
+		cmd.stdout = w
+		defer func() { cmd.stdout = saved }()
+
+		var err error
+		cmd.result = nil
+		remaining, err = cmd.Callback(cmd.Name, args...)
+		if f := cmd.resultFormatter(); err == nil && f != nil && cmd.result != nil {
+			err = f.write(w, cmd.result)
+		}
+		return err
+	})
+	return remaining, err
+}
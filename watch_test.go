@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	outputs := []string{"a\nb\n", "a\nc\n", "a\nc\nd\n"}
+	want := hideCursor + clearScreen + "a\nb\n" +
+		clearScreen + "a\n" + colorize("7", "c") + "\n" +
+		clearScreen + "a\nc\n" + colorize("7", "d") + "\n" + showCursor
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := &strings.Builder{}
+	calls := 0
+	err := Watch(ctx, builder, time.Millisecond, func(w io.Writer) error {
+		io.WriteString(w, outputs[calls])
+		calls++
+		if calls == len(outputs) {
+			cancel()
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if got := builder.String(); want != got {
+		t.Errorf("Wanted %q got %q", want, got)
+	}
+}
+
+func TestWatchOption(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		desc      string
+		args      []string
+		wantCalls int
+		wantErr   error
+	}{
+		{"no watch", []string{"status"}, 1, nil},
+		{"watch", []string{"status", "-watch", "1ms"}, 3, errStop},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout := &strings.Builder{}
+			calls := 0
+			cmd := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout), OutputOption(&strings.Builder{}))
+			var status *Command
+			status = cmd.SubCommand("status", WatchOption(), CallbackOption(func(name string, args ...string) ([]string, error) {
+				calls++
+				fmt.Fprintf(status.Stdout(), "run %d\n", calls)
+				if calls == 3 {
+					return args, errStop
+				}
+				return args, nil
+			}))
+
+			_, err := cmd.Run(test.args)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantCalls != calls {
+				t.Errorf("Wanted %d calls got %d", test.wantCalls, calls)
+			}
+
+			if test.wantErr != nil && !strings.Contains(stdout.String(), clearScreen+colorize("7", "run 2")+"\n") {
+				t.Errorf("Wanted the screen to be redrawn got %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestWatchResult(t *testing.T) {
+	errStop := errors.New("stop")
+	stdout := &strings.Builder{}
+	calls := 0
+	cmd := New("app", ErrorHandlingOption(ContinueOnError), StdoutOption(stdout), OutputOption(&strings.Builder{}), OutputFormatOption())
+	var status *Command
+	status = cmd.SubCommand("status", WatchOption(), CallbackOption(func(name string, args ...string) ([]string, error) {
+		calls++
+		if calls == 3 {
+			return args, errStop
+		}
+		status.SetResult(map[string]int{"calls": calls})
+		return args, nil
+	}))
+
+	_, err := cmd.Run([]string{"-output", "jsonpath={.calls}", "status", "-watch", "1ms"})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Wanted error %v got %v", errStop, err)
+	}
+
+	for _, want := range []string{clearScreen + "1\n", clearScreen + colorize("7", "2") + "\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Wanted %q in %q", want, stdout.String())
+		}
+	}
+}
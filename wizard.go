@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Wizard interactively prompts for the value of every flag and then
+// every positional argument of the command, after which the command's
+// callback is run with the collected arguments. Entering nothing for a
+// flag keeps its current value. Input that can not be parsed is
+// reported and prompted for again. The positional arguments of an
+// ArgumentsFunc are those it returns for the flag values entered. If the
+// positional arguments of the command are not known (they were not
+// declared and the callback was not set with FuncOption) then the
+// arguments are prompted for as a single space separated line.
+// Slice arguments are also entered as a space separated line.
+// ErrNonInteractive is returned if Interactive(reader) is false or input
+// is disabled for the command (see DisableInputOption)
+func (cmd *Command) Wizard(reader io.Reader, writer io.Writer) ([]string, error) {
+	cmd.result = nil
+	if err := cmd.checkRun(); err != nil {
+		return nil, cmd.handleErr(err)
+	}
+
+	if cmd.noInput || !Interactive(reader) {
+		return nil, cmd.handleErr(ErrNonInteractive)
+	}
+
+	args, err := cmd.wizard(bufio.NewReader(reader), writer)
+	if err == nil {
+		args, err = cmd.runCallback(args)
+		if derr := cmd.leaveDir(); err == nil {
+			err = derr
+		}
+	}
+	return args, cmd.handleErr(classify(err))
+}
+
+func (cmd *Command) wizard(buf *bufio.Reader, writer io.Writer) (args []string, err error) {
+	theme := cmd.Theme()
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+
+		message := fmt.Sprintf("-%s (%s) [%s]: ", f.Name, f.Usage, f.Value.String())
+		err = prompt(buf, writer, theme, message, func(resp string) error {
+			if resp == "" {
+				return nil
+			}
+			return cmd.Flags.Set(f.Name, resp)
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.finalizeArguments()
+	if cmd.arguments == nil {
+		var resp string
+		err = prompt(buf, writer, theme, "arguments: ", func(r string) error { resp = r; return nil })
+		return strings.Fields(resp), err
+	}
+
+	for _, arg := range cmd.arguments.args {
+		err = prompt(buf, writer, theme, fmt.Sprintf("%s: ", arg.desc), func(resp string) error {
+			if resp == "" {
+				return ErrNumArguments
+			}
+
+			// slice values are not validated here since they are often
+			// appended to when set and the callback will set them again
+			if _, ok := arg.value.(SliceValue); ok {
+				args = append(args, strings.Fields(resp)...)
+			} else {
+				if err := arg.value.(Value).Set(resp); err != nil {
+					return err
+				}
+				args = append(args, resp)
+			}
+			return nil
+		})
+
+		if err != nil {
+			break
+		}
+	}
+	return args, err
+}
+
+// errInvalidInput is returned by prompt set functions when input is
+// not accepted and there is nothing more to say about why
+var errInvalidInput = errors.New("invalid input")
+
+// stopPrompt is returned by prompt set functions to display err and
+// then give up with ErrTooManyAttempts
+type stopPrompt struct {
+	err error
+}
+
+func (sp *stopPrompt) Error() string { return sp.err.Error() }
+
+// prompt writes message and reads a line of input, calling set with
+// the trimmed response until set returns nil
+func prompt(buf *bufio.Reader, writer io.Writer, theme Theme, message string, set func(string) error) error {
+	return promptContext(context.Background(), buf, buf, writer, theme, message, set)
+}
+
+// promptContext is the same as prompt, but gives up waiting for input
+// once ctx is done. buf reads from reader, see readLine
+func promptContext(ctx context.Context, reader io.Reader, buf *bufio.Reader, writer io.Writer, theme Theme, message string, set func(string) error) error {
+	for {
+		fmt.Fprint(writer, theme.prompt(message))
+		resp, err := readLine(ctx, reader, buf)
+		if err != nil && (err != io.EOF || resp == "") {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		resp, err = Sanitize(strings.TrimSpace(resp), theme.sanitize)
+		if err == nil {
+			err = set(resp)
+		}
+		stop, isStop := err.(*stopPrompt)
+		if isStop {
+			err = stop.err
+		}
+
+		if err == nil {
+			return nil
+		} else if err == errInvalidInput {
+			err = nil
+		}
+		fmt.Fprint(writer, theme.invalid(err))
+
+		if isStop {
+			return ErrTooManyAttempts
+		}
+	}
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// abandonedReads holds, by the reader being read, the reads that
+// readLine gave up waiting for when their context was done
+var abandonedReads = struct {
+	sync.Mutex
+	reads map[io.Reader]chan lineResult
+}{reads: make(map[io.Reader]chan lineResult)}
+
+// readLine reads a line from buf, which reads from reader, returning
+// early with the context's error if ctx is done first. The read that
+// was given up on is not interrupted, so the next readLine from the
+// same reader takes its line rather than reading reader at the same
+// time
+func readLine(ctx context.Context, reader io.Reader, buf *bufio.Reader) (string, error) {
+	t := reflect.TypeOf(reader)
+	tracked := t != nil && t.Comparable()
+	var ch chan lineResult
+	if tracked {
+		abandonedReads.Lock()
+		ch = abandonedReads.reads[reader]
+		delete(abandonedReads.reads, reader)
+		abandonedReads.Unlock()
+	}
+
+	if ch == nil {
+		if ctx.Done() == nil {
+			return buf.ReadString('\n')
+		}
+
+		ch = make(chan lineResult, 1)
+		go func() {
+			line, err := buf.ReadString('\n')
+			ch <- lineResult{line, err}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		if tracked {
+			abandonedReads.Lock()
+			abandonedReads.reads[reader] = ch
+			abandonedReads.Unlock()
+		}
+		return "", ctx.Err()
+	case r := <-ch:
+		return r.line, r.err
+	}
+}
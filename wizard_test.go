@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWizard(t *testing.T) {
+	tests := []struct {
+		desc       string
+		setup      func(*Command, *string)
+		input      string
+		wantOutput string
+		wantResult string
+		wantErr    error
+	}{
+		{"flags and arguments", func(cmd *Command, result *string) {
+			n := cmd.Flags.Int("n", 1, "count")
+			FuncOption(func(host string, port int) { *result = fmt.Sprintf("%d %s:%d", *n, host, port) }, "<host>", "<port>")(cmd)
+		}, "\nlocalhost\n80\n", "-n (count) [1]: <host>: <port>: ", "1 localhost:80", nil},
+		{"set flag", func(cmd *Command, result *string) {
+			n := cmd.Flags.Int("n", 1, "count")
+			FuncOption(func() { *result = fmt.Sprintf("%d", *n) })(cmd)
+		}, "2\n", "-n (count) [1]: ", "2", nil},
+		{"invalid input", func(cmd *Command, result *string) {
+			FuncOption(func(port int) { *result = fmt.Sprintf("%d", port) }, "<port>")(cmd)
+		}, "\neighty\n80", "<port>: Invalid input: Invalid Usage not enough arguments given\n<port>: Invalid input: Invalid Usage parse error\n<port>: ", "80", nil},
+		{"slice", func(cmd *Command, result *string) {
+			FuncOption(func(i *intSlice) { *result = i.String() }, "<n>...")(cmd)
+		}, "1 2 3\n", "<n>...: ", "1,2,3", nil},
+		{"raw callback", func(cmd *Command, result *string) {
+			cmd.Callback = func(name string, args ...string) ([]string, error) { *result = strings.Join(args, ","); return nil, nil }
+		}, "a b  c\n", "arguments: ", "a,b,c", nil},
+		{"arguments func", func(cmd *Command, result *string) {
+			n := cmd.Flags.Int("n", 1, "count")
+			values := []*int{}
+			cmd.SetArgumentsFunc(func() *Arguments {
+				args := &Arguments{}
+				values = nil
+				for i := 0; i < *n; i++ {
+					values = append(values, args.Int("<v>"))
+				}
+				return args
+			})
+			cmd.Callback = func(string, ...string) ([]string, error) {
+				sum := 0
+				for _, v := range values {
+					sum += *v
+				}
+				*result = fmt.Sprint(sum)
+				return nil, nil
+			}
+		}, "2\n1\n2\n", "-n (count) [1]: <v>: <v>: ", "3", nil},
+		{"eof", func(cmd *Command, result *string) {
+			FuncOption(func(string) {}, "<host>")(cmd)
+		}, "", "<host>: ", "", io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			result := ""
+			cmd := New("app", ErrorHandlingOption(ContinueOnError))
+			test.setup(cmd, &result)
+
+			output := &strings.Builder{}
+			_, err := cmd.Wizard(strings.NewReader(test.input), output)
+			if !reflect.DeepEqual(test.wantErr, err) {
+				t.Errorf("Wanted error %v got %v", test.wantErr, err)
+			}
+
+			if got := output.String(); test.wantOutput != got {
+				t.Errorf("Wanted output %q got %q", test.wantOutput, got)
+			}
+
+			if test.wantResult != result {
+				t.Errorf("Wanted result %q got %q", test.wantResult, result)
+			}
+		})
+	}
+}
+
+func TestWizardForbidden(t *testing.T) {
+	ran := false
+	cmd := New("delete", ErrorHandlingOption(ContinueOnError), RequiresOption("write"), AuthorizerOption(func(*Command, []string) bool { return false }))
+	FuncOption(func(name string) { ran = true }, "<name>")(cmd)
+
+	output := &strings.Builder{}
+	_, err := cmd.Wizard(strings.NewReader("thing\n"), output)
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("Wanted error %v got %v", ErrForbidden, err)
+	}
+
+	if ran {
+		t.Errorf("Wanted the denied command not to run")
+	}
+
+	if output.String() != "" {
+		t.Errorf("Wanted no prompts got %q", output.String())
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// TempWorkspace is a temporary directory for staging files
+type TempWorkspace struct {
+	Dir string
+}
+
+// Workspace creates a new temporary directory whose name begins with
+// prefix. The caller is responsible for calling Cleanup, usually with
+// defer, once the workspace is no longer needed. Command.Workspace
+// returns a workspace that is cleaned up automatically
+func Workspace(prefix string) (*TempWorkspace, error) {
+	dir, err := ioutil.TempDir("", prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &TempWorkspace{Dir: dir}, nil
+}
+
+// Path joins elem to the workspace directory
+func (ws *TempWorkspace) Path(elem ...string) string {
+	return filepath.Join(append([]string{ws.Dir}, elem...)...)
+}
+
+// WriteFile writes data to the named file in the workspace, creating
+// any missing directories, and returns the path of the file
+func (ws *TempWorkspace) WriteFile(name string, data []byte) (string, error) {
+	path := ws.Path(name)
+	if err := MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return path, err
+	}
+	return path, WriteFile(path, data, 0600)
+}
+
+// Cleanup removes the workspace directory and everything in it
+func (ws *TempWorkspace) Cleanup() error {
+	return os.RemoveAll(ws.Dir)
+}
+
+// Workspace creates a temporary workspace, in the same way as the
+// Workspace function, that is removed when the command finishes
+// running. It is intended to be called from callbacks that need to
+// stage files
+func (cmd *Command) Workspace(prefix string) (*TempWorkspace, error) {
+	ws, err := Workspace(prefix)
+	if err == nil {
+		cmd.workspaces = append(cmd.workspaces, ws)
+	}
+	return ws, err
+}
+
+// cleanupWorkspaces removes the workspaces created while running the
+// command, printing a warning for any that can not be removed
+func (cmd *Command) cleanupWorkspaces() {
+	for _, ws := range cmd.workspaces {
+		if err := ws.Cleanup(); err != nil {
+			cmd.Warnf("workspace: %v", err)
+		}
+	}
+	cmd.workspaces = nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	ws, err := Workspace("test")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	path, err := ws.WriteFile("a/b.txt", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if path != ws.Path("a", "b.txt") {
+		t.Errorf("Wanted %q got %q", ws.Path("a", "b.txt"), path)
+	}
+
+	if got, err := ioutil.ReadFile(path); err != nil || string(got) != "hello" {
+		t.Errorf("Wanted %q got %q (%v)", "hello", got, err)
+	}
+
+	if err := ws.Cleanup(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := os.Stat(ws.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", ws.Dir)
+	}
+}
+
+func TestCommandWorkspace(t *testing.T) {
+	var ws *TempWorkspace
+	cmd := New("app", ErrorHandlingOption(ContinueOnError))
+	cmd.Callback = func(string, ...string) ([]string, error) {
+		var err error
+		ws, err = cmd.Workspace("test")
+		if err == nil {
+			_, err = ws.WriteFile("staged", []byte("data"))
+		}
+
+		if _, serr := os.Stat(ws.Dir); serr != nil {
+			t.Errorf("Expected the workspace to exist while running got %v", serr)
+		}
+		return nil, err
+	}
+
+	if _, err := cmd.Run(nil); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if _, err := os.Stat(ws.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after running", ws.Dir)
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import "strings"
+
+// usageWidth is the width that descriptions are wrapped to in usage
+// output
+const usageWidth = 80
+
+// wrapText re-wraps text so that no line is longer than width, where
+// possible. Paragraphs are separated by blank lines and are preserved.
+// Lines that begin with whitespace are treated as pre-formatted (such
+// as code blocks) and are kept exactly as they were written
+func wrapText(text string, width int) []string {
+	lines := []string{}
+	words := []string{}
+	flush := func() {
+		line := ""
+		for _, word := range words {
+			if line != "" && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+
+			if line == "" {
+				line = word
+			} else {
+				line += " " + word
+			}
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		words = words[:0]
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			flush()
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+		} else if line[0] == ' ' || line[0] == '\t' {
+			flush()
+			lines = append(lines, line)
+		} else {
+			words = append(words, strings.Fields(line)...)
+		}
+	}
+	flush()
+	return lines
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		desc  string
+		input string
+		width int
+		want  []string
+	}{
+		{"empty", "", 10, []string{}},
+		{"short", "foo bar", 10, []string{"foo bar"}},
+		{"wrapped", "foo bar baz qux", 10, []string{"foo bar", "baz qux"}},
+		{"long word", "foobarbazqux foo", 10, []string{"foobarbazqux", "foo"}},
+		{"joined lines", "foo\nbar\nbaz", 80, []string{"foo bar baz"}},
+		{"paragraphs", "foo\n\n\nbar", 80, []string{"foo", "", "bar"}},
+		{"code block", "example:\n\n    app foo   bar\n\tapp baz\ndone", 80, []string{"example:", "", "    app foo   bar", "\tapp baz", "done"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := wrapText(test.input, test.width)
+			if !reflect.DeepEqual(test.want, got) {
+				t.Errorf("Wanted %q got %q", test.want, got)
+			}
+		})
+	}
+}